@@ -0,0 +1,219 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+// Command rkentry encrypts values of boot and config files with the same AES-GCM format as CryptoAESEntry.
+//
+// Key is read from file with --key-file or from environment variable with --key-env, RK_CRYPTO_KEY by default.
+// Key should be 16, 24 or 32 bytes, encoding is declared with --key-encoding, raw by default or base64.
+// Trailing newline of raw key is trimmed.
+//
+// Usage:
+//
+//	rkentry encrypt --key-file aes.key my-password
+//	rkentry decrypt --key-file aes.key --key-encoding base64 "ENC(...)"
+//	rkentry rotate --key-file old.key --new-key-file new.key config.yaml boot.yaml
+//
+// New key of rotate is decoded with --new-key-encoding, same as --key-encoding by default.
+//
+// Value is read from stdin if missing, rotate re-encrypts every ENC(...) in files, or stdin if no file provided.
+package main
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"github.com/rookie-ninja/rk-entry/v2/entry"
+	"github.com/spf13/pflag"
+	"io"
+	"os"
+	"strings"
+)
+
+const (
+	defaultKeyEnv = "RK_CRYPTO_KEY"
+
+	keyEncodingRaw    = "raw"
+	keyEncodingBase64 = "base64"
+)
+
+func main() {
+	if err := run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// run execute subcommand with args.
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	if len(args) < 1 {
+		return errors.New("usage: rkentry <encrypt|decrypt|rotate> [flags] [values or files]")
+	}
+
+	set := pflag.NewFlagSet("rkentry "+args[0], pflag.ContinueOnError)
+	set.SetOutput(stderr)
+	keyFile := set.String("key-file", "", "file of AES key")
+	keyEnv := set.String("key-env", defaultKeyEnv, "environment variable of AES key")
+	keyEncoding := set.String("key-encoding", keyEncodingRaw, "encoding of AES key, raw or base64")
+
+	var newKeyFile, newKeyEnv, newKeyEncoding *string
+	if args[0] == "rotate" {
+		newKeyFile = set.String("new-key-file", "", "file of new AES key")
+		newKeyEnv = set.String("new-key-env", "", "environment variable of new AES key")
+		newKeyEncoding = set.String("new-key-encoding", "", "encoding of new AES key, same as --key-encoding by default")
+	}
+
+	if err := set.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	crypto, err := newCrypto("rkentry", *keyFile, *keyEnv, *keyEncoding)
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "encrypt":
+		value, err := readValue(set.Args(), stdin)
+		if err != nil {
+			return err
+		}
+
+		res, err := rkentry.EncryptValue(crypto, value)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintln(stdout, res)
+	case "decrypt":
+		value, err := readValue(set.Args(), stdin)
+		if err != nil {
+			return err
+		}
+
+		res, err := rkentry.DecryptValue(crypto, value)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintln(stdout, res)
+	case "rotate":
+		if len(*newKeyFile) < 1 && len(*newKeyEnv) < 1 {
+			return errors.New("--new-key-file or --new-key-env is required")
+		}
+
+		if len(*newKeyEncoding) < 1 {
+			*newKeyEncoding = *keyEncoding
+		}
+
+		newCrypto, err := newCrypto("rkentry-new", *newKeyFile, *newKeyEnv, *newKeyEncoding)
+		if err != nil {
+			return err
+		}
+
+		return rotate(set.Args(), crypto, newCrypto, stdin, stdout, stderr)
+	default:
+		return fmt.Errorf("unknown command %s, expect encrypt, decrypt or rotate", args[0])
+	}
+
+	return nil
+}
+
+// rotate re-encrypt values in files in place, or stdin into stdout if no file provided.
+func rotate(files []string, oldCrypto, newCrypto rkentry.Crypto, stdin io.Reader, stdout, stderr io.Writer) error {
+	if len(files) < 1 {
+		content, err := io.ReadAll(stdin)
+		if err != nil {
+			return err
+		}
+
+		res, _, err := rkentry.RotateEncryptedValues(content, oldCrypto, newCrypto)
+		if err != nil {
+			return err
+		}
+
+		_, err = stdout.Write(res)
+		return err
+	}
+
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			return err
+		}
+
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+
+		res, count, err := rkentry.RotateEncryptedValues(content, oldCrypto, newCrypto)
+		if err != nil {
+			return fmt.Errorf("failed to rotate %s, %v", file, err)
+		}
+
+		if err := os.WriteFile(file, res, info.Mode()); err != nil {
+			return err
+		}
+
+		fmt.Fprintf(stderr, "rotated %d value(s) in %s\n", count, file)
+	}
+
+	return nil
+}
+
+// newCrypto create CryptoAESEntry with key from file or environment variable decoded with encoding.
+func newCrypto(name, keyFile, keyEnv, encoding string) (rkentry.Crypto, error) {
+	var raw string
+	if len(keyFile) > 0 {
+		bytes, err := os.ReadFile(keyFile)
+		if err != nil {
+			return nil, err
+		}
+		raw = string(bytes)
+	} else {
+		var ok bool
+		if raw, ok = os.LookupEnv(keyEnv); !ok {
+			return nil, fmt.Errorf("key not found, use --key-file or set environment variable %s", keyEnv)
+		}
+	}
+
+	var key []byte
+	switch encoding {
+	case keyEncodingRaw:
+		key = []byte(strings.TrimRight(raw, "\r\n"))
+	case keyEncodingBase64:
+		var err error
+		if key, err = base64.StdEncoding.DecodeString(strings.TrimSpace(raw)); err != nil {
+			return nil, fmt.Errorf("invalid base64 key, %v", err)
+		}
+	default:
+		return nil, fmt.Errorf("unknown key encoding %s, expect raw or base64", encoding)
+	}
+
+	if !validKeyLen(len(key)) {
+		return nil, fmt.Errorf("invalid key length %d, expect 16, 24 or 32 bytes", len(key))
+	}
+
+	return rkentry.NewCryptoAES(name, key)
+}
+
+// validKeyLen returns true if length is valid for AES-128, AES-192 or AES-256.
+func validKeyLen(length int) bool {
+	return length == 16 || length == 24 || length == 32
+}
+
+// readValue returns first arg, or stdin with trailing newline trimmed.
+func readValue(args []string, stdin io.Reader) (string, error) {
+	if len(args) > 0 {
+		return args[0], nil
+	}
+
+	bytes, err := io.ReadAll(stdin)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimRight(string(bytes), "\r\n"), nil
+}
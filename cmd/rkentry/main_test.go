@@ -0,0 +1,162 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"github.com/rookie-ninja/rk-entry/v2/entry"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const (
+	rawKey    = "0123456789abcdef"
+	newRawKey = "fedcba9876543210fedcba9876543210"
+)
+
+func writeKey(t *testing.T, key string) string {
+	p := filepath.Join(t.TempDir(), "aes.key")
+	assert.Nil(t, os.WriteFile(p, []byte(key), os.ModePerm))
+	return p
+}
+
+func runCmd(args []string, stdin string) (string, string, error) {
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	err := run(args, strings.NewReader(stdin), stdout, stderr)
+	return strings.TrimRight(stdout.String(), "\n"), stderr.String(), err
+}
+
+func TestRun_EncryptAndDecrypt(t *testing.T) {
+	keyFile := writeKey(t, rawKey+"\n")
+
+	// value from args
+	encrypted, _, err := runCmd([]string{"encrypt", "--key-file", keyFile, "ut-pass"}, "")
+	assert.Nil(t, err)
+	assert.True(t, rkentry.IsEncryptedValue(encrypted))
+
+	decrypted, _, err := runCmd([]string{"decrypt", "--key-file", keyFile, encrypted}, "")
+	assert.Nil(t, err)
+	assert.Equal(t, "ut-pass", decrypted)
+
+	// value from stdin
+	encrypted, _, err = runCmd([]string{"encrypt", "--key-file", keyFile}, "ut-pass\n")
+	assert.Nil(t, err)
+	decrypted, _, err = runCmd([]string{"decrypt", "--key-file", keyFile}, encrypted)
+	assert.Nil(t, err)
+	assert.Equal(t, "ut-pass", decrypted)
+
+	// decrypted by crypto entry with the same key
+	crypto, err := rkentry.NewCryptoAES("ut-crypto", []byte(rawKey))
+	assert.Nil(t, err)
+	res, err := rkentry.DecryptValue(crypto, encrypted)
+	assert.Nil(t, err)
+	assert.Equal(t, "ut-pass", res)
+}
+
+func TestRun_WithKeyEncoding(t *testing.T) {
+	// 24 bytes raw key is valid base64 of 18 bytes, it is not guessed
+	key := "abcdefghijklmnopqrstuvwx"
+	crypto, err := rkentry.NewCryptoAES("ut-crypto", []byte(key))
+	assert.Nil(t, err)
+
+	encrypted, _, err := runCmd([]string{"encrypt", "--key-file", writeKey(t, key)}, "ut-pass")
+	assert.Nil(t, err)
+	res, err := rkentry.DecryptValue(crypto, encrypted)
+	assert.Nil(t, err)
+	assert.Equal(t, "ut-pass", res)
+
+	// base64 from ENV
+	assert.Nil(t, os.Setenv("UT_CRYPTO_KEY", base64.StdEncoding.EncodeToString([]byte(key))))
+	defer os.Unsetenv("UT_CRYPTO_KEY")
+
+	decrypted, _, err := runCmd([]string{"decrypt", "--key-env", "UT_CRYPTO_KEY", "--key-encoding", "base64", encrypted}, "")
+	assert.Nil(t, err)
+	assert.Equal(t, "ut-pass", decrypted)
+
+	// base64 key used as raw is a different key
+	_, _, err = runCmd([]string{"decrypt", "--key-env", "UT_CRYPTO_KEY", encrypted}, "")
+	assert.NotNil(t, err)
+
+	// raw key used as base64
+	_, _, err = runCmd([]string{"decrypt", "--key-file", writeKey(t, key), "--key-encoding", "base64", encrypted}, "")
+	assert.Contains(t, err.Error(), "invalid key length 18")
+
+	// invalid base64
+	_, _, err = runCmd([]string{"encrypt", "--key-file", writeKey(t, "invalid-base64!"), "--key-encoding", "base64", "ut-pass"}, "")
+	assert.Contains(t, err.Error(), "invalid base64 key")
+
+	// unknown encoding
+	_, _, err = runCmd([]string{"encrypt", "--key-file", writeKey(t, key), "--key-encoding", "hex", "ut-pass"}, "")
+	assert.Contains(t, err.Error(), "unknown key encoding hex")
+}
+
+func TestRun_Rotate(t *testing.T) {
+	keyFile, newKeyFile := writeKey(t, rawKey), writeKey(t, base64.StdEncoding.EncodeToString([]byte(newRawKey)))
+
+	encrypted, _, err := runCmd([]string{"encrypt", "--key-file", keyFile, "ut-pass"}, "")
+	assert.Nil(t, err)
+
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	assert.Nil(t, os.WriteFile(configPath, []byte("password: "+encrypted+"\n"), os.ModePerm))
+
+	// new key decoded with its own encoding
+	_, stderr, err := runCmd([]string{"rotate", "--key-file", keyFile,
+		"--new-key-file", newKeyFile, "--new-key-encoding", "base64", configPath}, "")
+	assert.Nil(t, err)
+	assert.Contains(t, stderr, "rotated 1 value(s)")
+
+	content, err := os.ReadFile(configPath)
+	assert.Nil(t, err)
+	rotated := strings.TrimSpace(strings.TrimPrefix(string(content), "password: "))
+	assert.NotEqual(t, encrypted, rotated)
+
+	crypto, err := rkentry.NewCryptoAES("ut-crypto", []byte(newRawKey))
+	assert.Nil(t, err)
+	res, err := rkentry.DecryptValue(crypto, rotated)
+	assert.Nil(t, err)
+	assert.Equal(t, "ut-pass", res)
+
+	// stdin into stdout
+	stdout, _, err := runCmd([]string{"rotate", "--key-file", keyFile,
+		"--new-key-file", newKeyFile, "--new-key-encoding", "base64"}, "password: "+encrypted)
+	assert.Nil(t, err)
+	assert.Contains(t, stdout, "password: ENC(")
+
+	// without new key
+	_, _, err = runCmd([]string{"rotate", "--key-file", keyFile, configPath}, "")
+	assert.Contains(t, err.Error(), "--new-key-file")
+}
+
+func TestRun_WithInvalidArgs(t *testing.T) {
+	_, _, err := runCmd([]string{}, "")
+	assert.Contains(t, err.Error(), "usage")
+
+	keyFile := writeKey(t, rawKey)
+	_, _, err = runCmd([]string{"unknown", "--key-file", keyFile}, "")
+	assert.Contains(t, err.Error(), "unknown command unknown")
+
+	_, _, err = runCmd([]string{"encrypt", "--unknown"}, "")
+	assert.NotNil(t, err)
+
+	// key missing
+	_, _, err = runCmd([]string{"encrypt", "--key-env", "UT_CRYPTO_KEY_NOT_EXIST", "ut-pass"}, "")
+	assert.Contains(t, err.Error(), "UT_CRYPTO_KEY_NOT_EXIST")
+
+	_, _, err = runCmd([]string{"encrypt", "--key-file", filepath.Join(t.TempDir(), "not-exist.key"), "ut-pass"}, "")
+	assert.NotNil(t, err)
+
+	// invalid key length
+	_, _, err = runCmd([]string{"encrypt", "--key-file", writeKey(t, "short"), "ut-pass"}, "")
+	assert.Contains(t, err.Error(), "invalid key length 5")
+
+	// not encrypted
+	_, _, err = runCmd([]string{"decrypt", "--key-file", keyFile, "plain"}, "")
+	assert.NotNil(t, err)
+}
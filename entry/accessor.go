@@ -0,0 +1,110 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkentry
+
+import (
+	"fmt"
+	"sort"
+)
+
+// EntryDefault is implemented by entries which could be marked as default one of its type,
+// like LoggerEntry and EventEntry with default: true in boot config.
+type EntryDefault interface {
+	IsDefaultEntry() bool
+}
+
+// GetEntryAs returns entry with name which could be converted into T.
+//
+// T could be either a concrete entry type like *LoggerEntry or an interface like SignerJwt.
+// If more than one entry matches, the one with smallest entry type in alphabetical order will be returned.
+// GlobalAppCtx will be used if ctx is nil.
+func GetEntryAs[T Entry](ctx *AppContext, entryName string) (T, bool) {
+	var zero T
+
+	for _, entryType := range sortedEntryTypes(ctx) {
+		if v, ok := appCtxOrGlobal(ctx).entries[entryType][entryName]; ok {
+			if res, ok := v.(T); ok {
+				return res, true
+			}
+		}
+	}
+
+	return zero, false
+}
+
+// MustGetEntryAs is the same as GetEntryAs but panics if entry not found.
+func MustGetEntryAs[T Entry](ctx *AppContext, entryName string) T {
+	res, ok := GetEntryAs[T](ctx, entryName)
+	if !ok {
+		var zero T
+		ShutdownWithError(fmt.Errorf("entry %s with type %T not found", entryName, zero))
+	}
+
+	return res
+}
+
+// ListEntriesAs returns all entries which could be converted into T.
+//
+// Entries are sorted by entry type and entry name.
+// GlobalAppCtx will be used if ctx is nil.
+func ListEntriesAs[T Entry](ctx *AppContext) []T {
+	res := make([]T, 0)
+
+	for _, entryType := range sortedEntryTypes(ctx) {
+		entries := appCtxOrGlobal(ctx).entries[entryType]
+
+		names := make([]string, 0, len(entries))
+		for name := range entries {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for i := range names {
+			if v, ok := entries[names[i]].(T); ok {
+				res = append(res, v)
+			}
+		}
+	}
+
+	return res
+}
+
+// GetDefault returns entry which could be converted into T and marked as default via EntryDefault.
+//
+// False will be returned if none of entries were marked as default.
+// GlobalAppCtx will be used if ctx is nil.
+func GetDefault[T Entry](ctx *AppContext) (T, bool) {
+	var zero T
+
+	entries := ListEntriesAs[T](ctx)
+	for i := range entries {
+		if v, ok := any(entries[i]).(EntryDefault); ok && v.IsDefaultEntry() {
+			return entries[i], true
+		}
+	}
+
+	return zero, false
+}
+
+// appCtxOrGlobal returns GlobalAppCtx if ctx is nil.
+func appCtxOrGlobal(ctx *AppContext) *AppContext {
+	if ctx == nil {
+		return GlobalAppCtx
+	}
+
+	return ctx
+}
+
+// sortedEntryTypes list entry types in AppContext in alphabetical order.
+func sortedEntryTypes(ctx *AppContext) []string {
+	res := make([]string, 0)
+	for entryType := range appCtxOrGlobal(ctx).entries {
+		res = append(res, entryType)
+	}
+	sort.Strings(res)
+
+	return res
+}
@@ -0,0 +1,128 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkentry
+
+import (
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestGetEntryAs(t *testing.T) {
+	ctx := NewAppContext()
+	logger := RegisterLoggerEntry(&BootLogger{
+		Logger: []*BootLoggerE{
+			{
+				Name: "ut-logger",
+			},
+		},
+	}, WithAppCtx(ctx))[0]
+	signer := RegisterSymmetricJwtSigner("ut-signer", jwt.SigningMethodHS256.Name, []byte("ut-key"), WithAppCtx(ctx))
+
+	// with concrete type
+	res, ok := GetEntryAs[*LoggerEntry](ctx, "ut-logger")
+	assert.True(t, ok)
+	assert.Equal(t, logger, res)
+
+	// with interface
+	signerRes, ok := GetEntryAs[SignerJwt](ctx, "ut-signer")
+	assert.True(t, ok)
+	assert.Equal(t, signer, signerRes)
+
+	// with wrong type
+	_, ok = GetEntryAs[*EventEntry](ctx, "ut-logger")
+	assert.False(t, ok)
+
+	// with non-exist entry
+	res, ok = GetEntryAs[*LoggerEntry](ctx, "non-exist")
+	assert.False(t, ok)
+	assert.Nil(t, res)
+
+	// with nil AppContext
+	_, ok = GetEntryAs[*LoggerEntry](nil, "ut-logger")
+	assert.False(t, ok)
+}
+
+func TestMustGetEntryAs(t *testing.T) {
+	ctx := NewAppContext()
+	RegisterLoggerEntry(&BootLogger{
+		Logger: []*BootLoggerE{
+			{
+				Name: "ut-logger",
+			},
+		},
+	}, WithAppCtx(ctx))
+
+	assert.NotNil(t, MustGetEntryAs[*LoggerEntry](ctx, "ut-logger"))
+
+	defer assertPanic(t)
+	MustGetEntryAs[*EventEntry](ctx, "ut-logger")
+}
+
+func TestListEntriesAs(t *testing.T) {
+	ctx := NewAppContext()
+	RegisterLoggerEntry(&BootLogger{
+		Logger: []*BootLoggerE{
+			{
+				Name: "ut-logger-b",
+			},
+			{
+				Name: "ut-logger-a",
+			},
+		},
+	}, WithAppCtx(ctx))
+	RegisterEventEntry(&BootEvent{
+		Event: []*BootEventE{
+			{
+				Name: "ut-event",
+			},
+		},
+	}, WithAppCtx(ctx))
+
+	loggers := ListEntriesAs[*LoggerEntry](ctx)
+	assert.Len(t, loggers, 2)
+	assert.Equal(t, "ut-logger-a", loggers[0].GetName())
+	assert.Equal(t, "ut-logger-b", loggers[1].GetName())
+
+	assert.Len(t, ListEntriesAs[*EventEntry](ctx), 1)
+	assert.Empty(t, ListEntriesAs[*CertEntry](ctx))
+	// app info entry is included
+	assert.Len(t, ListEntriesAs[Entry](ctx), 4)
+}
+
+func TestGetDefault(t *testing.T) {
+	ctx := NewAppContext()
+
+	// without entries
+	_, ok := GetDefault[*LoggerEntry](ctx)
+	assert.False(t, ok)
+	assert.Equal(t, LoggerEntryStdout, ctx.GetLoggerEntryDefault())
+
+	// without default
+	RegisterLoggerEntry(&BootLogger{
+		Logger: []*BootLoggerE{
+			{
+				Name: "ut-logger",
+			},
+		},
+	}, WithAppCtx(ctx))
+	_, ok = GetDefault[*LoggerEntry](ctx)
+	assert.False(t, ok)
+
+	// with default
+	RegisterLoggerEntry(&BootLogger{
+		Logger: []*BootLoggerE{
+			{
+				Name:    "ut-logger-default",
+				Default: true,
+			},
+		},
+	}, WithAppCtx(ctx))
+	res, ok := GetDefault[*LoggerEntry](ctx)
+	assert.True(t, ok)
+	assert.Equal(t, "ut-logger-default", res.GetName())
+	assert.Equal(t, res, ctx.GetLoggerEntryDefault())
+}
@@ -56,9 +56,23 @@ func appInfoEntryDefault() *appInfoEntry {
 
 // registerAppInfoEntryYAML register appInfoEntry with bytes of YAML
 func registerAppInfoEntryYAML(raw []byte) map[string]Entry {
+	res, err := registerAppInfoEntryYAMLE(raw)
+	if err != nil {
+		ShutdownWithError(err)
+	}
+
+	return res
+}
+
+// registerAppInfoEntryYAMLE is the same as registerAppInfoEntryYAML but returns error instead of panic.
+func registerAppInfoEntryYAMLE(raw []byte, opts ...RegOption) (map[string]Entry, error) {
+	appCtx := NewRegOptions(opts...).AppCtx
+
 	// Unmarshal user provided config into boot config struct
 	config := &bootConfigAppInfo{}
-	UnmarshalBootYAML(raw, config)
+	if err := unmarshalBootYAMLE(appCtx, raw, config); err != nil {
+		return nil, err
+	}
 	res := map[string]Entry{}
 
 	entry := appInfoEntryDefault()
@@ -91,13 +105,16 @@ func registerAppInfoEntryYAML(raw []byte) map[string]Entry {
 		entry.Maintainers = make([]string, 0)
 	}
 
-	GlobalAppCtx.appInfoEntry = entry
+	appCtx.appInfoEntry = entry
 
-	EventEntryStdout = NewEventEntryStdout()
-	LoggerEntryStdout = NewLoggerEntryStdout()
+	// stdout entries are shared by process, refresh them only with GlobalAppCtx
+	if appCtx == GlobalAppCtx {
+		EventEntryStdout = NewEventEntryStdout()
+		LoggerEntryStdout = NewLoggerEntryStdout()
+	}
 
 	res[entry.GetName()] = entry
-	return res
+	return res, nil
 }
 
 // Bootstrap is noop function.
@@ -0,0 +1,169 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkentry
+
+import (
+	"errors"
+	"fmt"
+	"github.com/mitchellh/mapstructure"
+	"gopkg.in/yaml.v2"
+	yaml3 "gopkg.in/yaml.v3"
+	"regexp"
+	"strings"
+)
+
+// mapstructure error would be like: 'logger[0].name' expected type 'string', got ... or error decoding 'logger[0].name': ...
+var decodeErrPathRegex = regexp.MustCompile(`^(?:error decoding )?'([^']*)':?\s*(.*)$`)
+
+// BootConfigIssue is a single problem found in boot config.
+type BootConfigIssue struct {
+	// Path is YAML path of offending key, like logger[0].zap.level
+	Path string `yaml:"path" json:"path"`
+	// Message describes the problem
+	Message string `yaml:"message" json:"message"`
+	// Line of offending key in boot config, 0 if unknown
+	Line int `yaml:"line,omitempty" json:"line,omitempty"`
+	// Column of offending key in boot config, 0 if unknown
+	Column int `yaml:"column,omitempty" json:"column,omitempty"`
+}
+
+// String returns issue as <path>: <message>, position is appended after path if exists.
+func (issue *BootConfigIssue) String() string {
+	prefix := issue.Path
+	if issue.Line > 0 {
+		prefix = strings.TrimSpace(fmt.Sprintf("%s (line %d, column %d)", issue.Path, issue.Line, issue.Column))
+	}
+
+	if len(prefix) < 1 {
+		return issue.Message
+	}
+
+	return prefix + ": " + issue.Message
+}
+
+// BootConfigError aggregates every problem found in boot config into one error.
+type BootConfigError struct {
+	Issues []*BootConfigIssue `yaml:"issues" json:"issues"`
+}
+
+// Add append error with YAML path into BootConfigError.
+//
+// Issues of nested BootConfigError will be flattened with path prefixed.
+func (e *BootConfigError) Add(path string, err error) {
+	if err == nil {
+		return
+	}
+
+	var nested *BootConfigError
+	if errors.As(err, &nested) {
+		for _, issue := range nested.Issues {
+			e.Issues = append(e.Issues, &BootConfigIssue{
+				Path:    joinBootPath(path, issue.Path),
+				Message: issue.Message,
+			})
+		}
+		return
+	}
+
+	var decodeErr *mapstructure.Error
+	if errors.As(err, &decodeErr) {
+		for i := range decodeErr.Errors {
+			e.addDecodeIssue(path, decodeErr.Errors[i])
+		}
+		return
+	}
+
+	var yamlErr *yaml.TypeError
+	if errors.As(err, &yamlErr) {
+		for i := range yamlErr.Errors {
+			e.Issues = append(e.Issues, &BootConfigIssue{
+				Path:    path,
+				Message: yamlErr.Errors[i],
+			})
+		}
+		return
+	}
+
+	e.Issues = append(e.Issues, &BootConfigIssue{
+		Path:    path,
+		Message: err.Error(),
+	})
+}
+
+// ErrorOrNil returns nil if there is no issue.
+func (e *BootConfigError) ErrorOrNil() error {
+	if e == nil || len(e.Issues) < 1 {
+		return nil
+	}
+
+	return e
+}
+
+// Error returns multi-line report of issues.
+func (e *BootConfigError) Error() string {
+	lines := []string{
+		fmt.Sprintf("invalid boot config, %d problem(s) found:", len(e.Issues)),
+	}
+
+	for i := range e.Issues {
+		lines = append(lines, "  * "+e.Issues[i].String())
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// addNodeIssue add issue with position of yaml node.
+func (e *BootConfigError) addNodeIssue(path string, node *yaml3.Node, msg string) {
+	e.Issues = append(e.Issues, &BootConfigIssue{
+		Path:    path,
+		Message: msg,
+		Line:    node.Line,
+		Column:  node.Column,
+	})
+}
+
+// addDecodeIssue split mapstructure error into path and message
+func (e *BootConfigError) addDecodeIssue(path, msg string) {
+	issue := &BootConfigIssue{
+		Path:    path,
+		Message: msg,
+	}
+
+	if tokens := decodeErrPathRegex.FindStringSubmatch(msg); len(tokens) == 3 {
+		issue.Path = joinBootPath(path, tokens[1])
+		issue.Message = tokens[2]
+	}
+
+	e.Issues = append(e.Issues, issue)
+}
+
+// joinBootPath join YAML paths with dot.
+func joinBootPath(parent, child string) string {
+	if len(parent) < 1 {
+		return child
+	}
+
+	if len(child) < 1 {
+		return parent
+	}
+
+	if strings.HasPrefix(child, "[") {
+		return parent + child
+	}
+
+	return parent + "." + child
+}
+
+// bootPath returns YAML path of element in list, like logger[0]
+func bootPath[T comparable](section string, list []T, elem T) string {
+	for i := range list {
+		if list[i] == elem {
+			return fmt.Sprintf("%s[%d]", section, i)
+		}
+	}
+
+	return section
+}
@@ -0,0 +1,146 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkentry
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestUnmarshalBootYAMLE(t *testing.T) {
+	// invalid YAML
+	err := UnmarshalBootYAMLE([]byte("logger: [invalid"), &BootLogger{})
+	assert.NotNil(t, err)
+
+	// wrong types are reported with YAML path
+	bootStr := `
+---
+logger:
+  - name: [ut-logger]
+    default: not-bool
+cert:
+  - name: ut-cert
+    caPath:
+`
+	err = UnmarshalBootYAMLE([]byte(bootStr), &BootLogger{})
+	assert.NotNil(t, err)
+
+	var bootErr *BootConfigError
+	assert.True(t, errors.As(err, &bootErr))
+	assert.Len(t, bootErr.Issues, 2)
+	assert.Contains(t, err.Error(), "logger[0].name")
+	assert.Contains(t, err.Error(), "logger[0].default")
+
+	// happy case
+	boot := &BootCert{}
+	assert.Nil(t, UnmarshalBootYAMLE([]byte(bootStr), boot))
+	assert.Equal(t, "ut-cert", boot.Cert[0].Name)
+}
+
+func TestBootConfigError(t *testing.T) {
+	bootErr := &BootConfigError{}
+	assert.Nil(t, bootErr.ErrorOrNil())
+
+	bootErr.Add("ignored", nil)
+	assert.Nil(t, bootErr.ErrorOrNil())
+
+	bootErr.Add("cert[0]", errors.New("ut-error"))
+
+	// nested error would be flattened
+	nested := &BootConfigError{}
+	nested.Add("[1].path", errors.New("ut-nested-error"))
+	bootErr.Add("config", nested)
+
+	assert.NotNil(t, bootErr.ErrorOrNil())
+	assert.Equal(t, "invalid boot config, 2 problem(s) found:\n"+
+		"  * cert[0]: ut-error\n"+
+		"  * config[1].path: ut-nested-error", bootErr.Error())
+}
+
+func TestRegisterEntryE_WithError(t *testing.T) {
+	defer GlobalAppCtx.clearEntries()
+
+	// config file with invalid content
+	configPath := filepath.ToSlash(filepath.Join(t.TempDir(), "ut-config.yaml"))
+	assert.Nil(t, os.WriteFile(configPath, []byte("key: [invalid"), os.ModePerm))
+
+	configs, err := RegisterConfigEntryE(&BootConfig{
+		Config: []*BootConfigE{
+			{
+				Name: "ut-config",
+				Path: configPath,
+			},
+		},
+	})
+	assert.Empty(t, configs)
+	assert.Contains(t, err.Error(), "config[0].path")
+
+	// cert with only cert pem
+	certs, err := RegisterCertEntryE(&BootCert{
+		Cert: []*BootCertE{
+			{
+				Name:        "ut-cert",
+				CertPemPath: "ut-cert.pem",
+			},
+		},
+	})
+	assert.Empty(t, certs)
+	assert.Contains(t, err.Error(), "cert[0]")
+}
+
+func TestCertEntry_BootstrapE(t *testing.T) {
+	defer GlobalAppCtx.clearEntries()
+
+	entries, err := RegisterCertEntryE(&BootCert{
+		Cert: []*BootCertE{
+			{
+				Name:        "ut-cert",
+				CertPemPath: "/non-exist-ut-cert.pem",
+				KeyPemPath:  "/non-exist-ut-key.pem",
+			},
+		},
+	})
+	assert.Nil(t, err)
+
+	// error would be returned each time
+	assert.NotNil(t, entries[0].BootstrapE(context.TODO()))
+	assert.NotNil(t, entries[0].BootstrapE(context.TODO()))
+
+	// AppContext would call BootstrapE
+	err = GlobalAppCtx.BootstrapEntries(context.TODO(), []Entry{entries[0]})
+	assert.Contains(t, err.Error(), "CertEntry/ut-cert")
+}
+
+func TestBootstrapBuiltInEntryFromYAMLE(t *testing.T) {
+	defer GlobalAppCtx.clearEntries()
+
+	bootStr := `
+---
+logger:
+  - name: ut-logger
+    default: not-bool
+cert:
+  - name: ut-cert
+    keyPemPath: ut-key.pem
+`
+	err := BootstrapBuiltInEntryFromYAMLE([]byte(bootStr))
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "logger[0].default")
+	assert.Contains(t, err.Error(), "cert[0]")
+
+	// happy case
+	bootStr = `
+---
+logger:
+  - name: ut-logger
+`
+	assert.Nil(t, BootstrapBuiltInEntryFromYAMLE([]byte(bootStr)))
+	assert.NotNil(t, GlobalAppCtx.GetLoggerEntry("ut-logger"))
+}
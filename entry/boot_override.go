@@ -0,0 +1,232 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkentry
+
+import (
+	"bytes"
+	"fmt"
+	yaml3 "gopkg.in/yaml.v3"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+const (
+	// bootOverrideTag is tag of yaml node encoded from bootOverrideValue, like !rk-override:int:env:RK_GIN_0_PORT
+	bootOverrideTag = "!rk-override"
+
+	bootOverrideKindString = "string"
+	bootOverrideKindInt    = "int"
+	bootOverrideKindFloat  = "float"
+	bootOverrideKindBool   = "bool"
+)
+
+// bootOverrideValue is scalar value of ENV or --rkset override.
+//
+// Value is kept as it is and coerced into type of destination field while decoding,
+// so that 0012 stays as string for string field and invalid-port fails for integer field.
+type bootOverrideValue struct {
+	// value is raw string of override
+	value string
+	// kind is explicit type declared like key:string=0012, empty if not declared
+	kind string
+	// source of override, like env:RK_GIN_0_PORT or flag:--rkset
+	source string
+}
+
+// coerce convert value into type of destination, explicit kind wins over destination.
+func (v bootOverrideValue) coerce(to reflect.Type) (interface{}, error) {
+	if len(v.kind) > 0 || to == nil {
+		return v.natural()
+	}
+
+	for to.Kind() == reflect.Ptr {
+		to = to.Elem()
+	}
+
+	var res interface{}
+	var err error
+
+	switch to.Kind() {
+	case reflect.String:
+		return v.value, nil
+	case reflect.Bool:
+		res, err = strconv.ParseBool(v.value)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		res, err = strconv.ParseInt(v.value, 10, to.Bits())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		res, err = strconv.ParseUint(v.value, 10, to.Bits())
+	case reflect.Float32, reflect.Float64:
+		res, err = strconv.ParseFloat(v.value, to.Bits())
+	default:
+		return v.natural()
+	}
+
+	if err != nil {
+		return nil, v.error(to.Kind().String())
+	}
+
+	return res, nil
+}
+
+// natural convert value with explicit kind, or guess type from value as strvals does.
+func (v bootOverrideValue) natural() (interface{}, error) {
+	var res interface{}
+	var err error
+
+	switch v.kind {
+	case bootOverrideKindString:
+		return v.value, nil
+	case bootOverrideKindInt:
+		res, err = strconv.Atoi(v.value)
+	case bootOverrideKindFloat:
+		res, err = strconv.ParseFloat(v.value, 64)
+	case bootOverrideKindBool:
+		res, err = strconv.ParseBool(v.value)
+	default:
+		return typedVal([]rune(v.value), false), nil
+	}
+
+	if err != nil {
+		return nil, v.error(v.kind)
+	}
+
+	return res, nil
+}
+
+// error returns error with source of override.
+func (v bootOverrideValue) error(kind string) error {
+	return fmt.Errorf("invalid value %q of %s, expect %s", v.value, v.source, kind)
+}
+
+// MarshalYAML encode value as tagged scalar node which is validated by JSONSchema with coercion.
+func (v bootOverrideValue) MarshalYAML() (interface{}, error) {
+	// comments are dropped while encoding, so source is kept in tag
+	return &yaml3.Node{
+		Kind:  yaml3.ScalarNode,
+		Tag:   strings.Join([]string{bootOverrideTag, v.kind, v.source}, ":"),
+		Value: v.value,
+	}, nil
+}
+
+// bootOverrideFromNode returns bootOverrideValue encoded by MarshalYAML.
+func bootOverrideFromNode(node *yaml3.Node) (bootOverrideValue, bool) {
+	if node.Kind != yaml3.ScalarNode || !strings.HasPrefix(node.Tag, bootOverrideTag+":") {
+		return bootOverrideValue{}, false
+	}
+
+	tokens := strings.SplitN(strings.TrimPrefix(node.Tag, bootOverrideTag+":"), ":", 2)
+	if len(tokens) != 2 {
+		return bootOverrideValue{}, false
+	}
+
+	return bootOverrideValue{
+		value:  node.Value,
+		kind:   tokens[0],
+		source: tokens[1],
+	}, true
+}
+
+// parseBootOverridesWithSource parses a set line into map with values of bootOverrideValue.
+//
+// Type could be declared explicitly with suffix of key, like key:string=0012, one of string, int, float and bool.
+func parseBootOverridesWithSource(s, source string) (map[interface{}]interface{}, error) {
+	vals := map[interface{}]interface{}{}
+	t := newParser(bytes.NewBufferString(s), vals, true)
+	if err := t.parse(); err != nil {
+		return vals, err
+	}
+
+	return wrapBootOverride(vals, "", source).(map[interface{}]interface{}), nil
+}
+
+// wrapBootOverride replace strings with bootOverrideValue recursively, kind of key suffix is applied to children.
+func wrapBootOverride(input interface{}, kind, source string) interface{} {
+	switch v := input.(type) {
+	case map[interface{}]interface{}:
+		res := map[interface{}]interface{}{}
+		for key, value := range v {
+			childKind := kind
+			if s, ok := key.(string); ok {
+				if i := strings.LastIndex(s, ":"); i >= 0 && isBootOverrideKind(s[i+1:]) {
+					key, childKind = s[:i], s[i+1:]
+				}
+			}
+
+			res[key] = wrapBootOverride(value, childKind, source)
+		}
+		return res
+	case []interface{}:
+		res := make([]interface{}, len(v))
+		for i := range v {
+			res[i] = wrapBootOverride(v[i], kind, source)
+		}
+		return res
+	case string:
+		return bootOverrideValue{
+			value:  v,
+			kind:   kind,
+			source: source,
+		}
+	default:
+		return v
+	}
+}
+
+// isBootOverrideKind returns true if kind could be declared explicitly.
+func isBootOverrideKind(kind string) bool {
+	switch kind {
+	case bootOverrideKindString, bootOverrideKindInt, bootOverrideKindFloat, bootOverrideKindBool:
+		return true
+	default:
+		return false
+	}
+}
+
+// naturalBootValue replace bootOverrideValue with natural value recursively, input is not modified.
+func naturalBootValue(input interface{}) (interface{}, error) {
+	switch v := input.(type) {
+	case bootOverrideValue:
+		return v.natural()
+	case map[interface{}]interface{}:
+		res := make(map[interface{}]interface{}, len(v))
+		for key, value := range v {
+			natural, err := naturalBootValue(value)
+			if err != nil {
+				return nil, err
+			}
+			res[key] = natural
+		}
+		return res, nil
+	case []interface{}:
+		res := make([]interface{}, len(v))
+		for i := range v {
+			natural, err := naturalBootValue(v[i])
+			if err != nil {
+				return nil, err
+			}
+			res[i] = natural
+		}
+		return res, nil
+	default:
+		return v, nil
+	}
+}
+
+// bootOverrideDecodeHook coerce bootOverrideValue into type of destination field while decoding boot config.
+//
+// Values decoded into interface{} like content of ConfigEntry are converted recursively.
+func bootOverrideDecodeHook(_ reflect.Type, to reflect.Type, data interface{}) (interface{}, error) {
+	if v, ok := data.(bootOverrideValue); ok {
+		return v.coerce(to)
+	}
+
+	if to.Kind() == reflect.Interface {
+		return naturalBootValue(data)
+	}
+
+	return data, nil
+}
@@ -0,0 +1,109 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkentry
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestBootOverrideValue_Coerce(t *testing.T) {
+	v := bootOverrideValue{value: "0012", source: "env:RK_UT"}
+
+	// coerced into type of destination
+	res, err := v.coerce(reflect.TypeOf(""))
+	assert.Nil(t, err)
+	assert.Equal(t, "0012", res)
+
+	res, err = v.coerce(reflect.TypeOf(0))
+	assert.Nil(t, err)
+	assert.Equal(t, int64(12), res)
+
+	res, err = v.coerce(reflect.TypeOf(new(float64)))
+	assert.Nil(t, err)
+	assert.Equal(t, float64(12), res)
+
+	_, err = v.coerce(reflect.TypeOf(true))
+	assert.Contains(t, err.Error(), "env:RK_UT")
+
+	// guessed if destination is interface
+	res, err = bootOverrideValue{value: "true"}.coerce(reflect.TypeOf((*interface{})(nil)).Elem())
+	assert.Nil(t, err)
+	assert.Equal(t, true, res)
+
+	// explicit kind wins
+	res, err = bootOverrideValue{value: "12", kind: "string"}.coerce(reflect.TypeOf(0))
+	assert.Nil(t, err)
+	assert.Equal(t, "12", res)
+
+	_, err = bootOverrideValue{value: "abc", kind: "int", source: "flag:--rkset"}.coerce(nil)
+	assert.Contains(t, err.Error(), "flag:--rkset")
+}
+
+func TestParseBootOverridesWithSource(t *testing.T) {
+	res, err := parseBootOverridesWithSource("a.b:string=0012,a.c=true,list:int={1,2}", "flag:--rkset")
+	assert.Nil(t, err)
+
+	a := res["a"].(map[interface{}]interface{})
+	assert.Equal(t, bootOverrideValue{value: "0012", kind: "string", source: "flag:--rkset"}, a["b"])
+	assert.Equal(t, bootOverrideValue{value: "true", source: "flag:--rkset"}, a["c"])
+	assert.Equal(t, []interface{}{
+		bootOverrideValue{value: "1", kind: "int", source: "flag:--rkset"},
+		bootOverrideValue{value: "2", kind: "int", source: "flag:--rkset"},
+	}, res["list"])
+
+	// key with unknown kind is kept
+	res, err = parseBootOverridesWithSource("a:b=c", "flag:--rkset")
+	assert.Nil(t, err)
+	assert.Contains(t, res, "a:b")
+}
+
+func TestUnmarshalBootYAMLE_WithTypedOverrides(t *testing.T) {
+	raw := `
+config:
+  - name: ut-config
+    watchDebounceMs: 100
+    content:
+      db:
+        port: 3306
+`
+	assert.Nil(t, os.Setenv("RK_CONFIG_0_NAME", "0012"))
+	defer os.Unsetenv("RK_CONFIG_0_NAME")
+	assert.Nil(t, os.Setenv("RK_CONFIG_0_WATCH", "true"))
+	defer os.Unsetenv("RK_CONFIG_0_WATCH")
+	assert.Nil(t, os.Setenv("RK_CONFIG_0_CONTENT_DB_PORT", "3307"))
+	defer os.Unsetenv("RK_CONFIG_0_CONTENT_DB_PORT")
+
+	args := os.Args
+	defer func() { os.Args = args }()
+	os.Args = []string{args[0], "--rkset", "config[0].content.db.user:string=true"}
+
+	boot := &BootConfig{}
+	assert.Nil(t, UnmarshalBootYAMLE([]byte(raw), boot))
+	assert.Equal(t, "0012", boot.Config[0].Name)
+	assert.True(t, boot.Config[0].Watch)
+	assert.Equal(t, 100, boot.Config[0].WatchDebounceMs)
+	db := boot.Config[0].Content["db"].(map[interface{}]interface{})
+	assert.Equal(t, 3307, db["port"])
+	assert.Equal(t, "true", db["user"])
+	assert.Nil(t, ValidateBootYAML([]byte(raw)))
+
+	// invalid value fails with name of ENV
+	assert.Nil(t, os.Setenv("RK_CONFIG_0_WATCHDEBOUNCEMS", "invalid-ms"))
+	defer os.Unsetenv("RK_CONFIG_0_WATCHDEBOUNCEMS")
+
+	err := UnmarshalBootYAMLE([]byte(raw), boot)
+	assert.NotNil(t, err)
+	issue := err.(*BootConfigError).Issues[0]
+	assert.Equal(t, "config[0].watchDebounceMs", issue.Path)
+	assert.Contains(t, issue.Message, "env:RK_CONFIG_0_WATCHDEBOUNCEMS")
+
+	err = ValidateBootYAML([]byte(raw))
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "env:RK_CONFIG_0_WATCHDEBOUNCEMS")
+}
@@ -11,73 +11,142 @@ import (
 	"crypto/x509"
 	"embed"
 	"encoding/json"
-	"encoding/pem"
+	"errors"
+	"fmt"
+	"github.com/fsnotify/fsnotify"
+	"go.uber.org/zap"
+	"os"
+	"path/filepath"
+	"strings"
 	"sync"
+	"time"
 )
 
 // RegisterCertEntry create cert entry with options.
-func RegisterCertEntry(boot *BootCert) []*CertEntry {
+func RegisterCertEntry(boot *BootCert, opts ...RegOption) []*CertEntry {
+	res, err := RegisterCertEntryE(boot, opts...)
+	if err != nil {
+		ShutdownWithError(err)
+	}
+
+	return res
+}
+
+// RegisterCertEntryE is the same as RegisterCertEntry but returns error instead of panic.
+func RegisterCertEntryE(boot *BootCert, opts ...RegOption) ([]*CertEntry, error) {
+	appCtx := NewRegOptions(opts...).AppCtx
 	res := make([]*CertEntry, 0)
+	bootErr := &BootConfigError{}
 
 	// filter out based domain
-	configMap := make(map[string]*BootCertE)
-	for _, config := range boot.Cert {
-		if len(config.Name) < 1 {
+	configMap := filterByDomain(boot.Cert, func(config *BootCertE) (string, string) {
+		return config.Name, config.Domain
+	})
+
+	for _, cert := range configMap {
+		entry := &CertEntry{
+			entryName:        cert.Name,
+			entryType:        CertEntryType,
+			entryDescription: cert.Description,
+			caPath:           cert.CAPath,
+			clientCAPath:     cert.ClientCAPath,
+			keyPemPath:       cert.KeyPemPath,
+			certPemPath:      cert.CertPemPath,
+			p12Path:          cert.P12Path,
+			keyPassword:      cert.KeyPassword,
+			cryptoEntry:      cert.CryptoEntry,
+			appCtx:           appCtx,
+			certPairs:        cert.Certs,
+			embedFS:          appCtx.GetEmbedFS(CertEntryType, cert.Name),
+			watch:            cert.Watch,
+			watchDebounce:    time.Duration(cert.WatchDebounceMs) * time.Millisecond,
+			expiry:           cert.Expiry,
+		}
+
+		if entry.watchDebounce <= 0 {
+			entry.watchDebounce = defaultConfigWatchDebounce
+		}
+
+		if (len(cert.CertPemPath) > 0) != (len(cert.KeyPemPath) > 0) {
+			bootErr.Add(bootPath("cert", boot.Cert, cert),
+				errors.New("certPemPath and keyPemPath should be provided together"))
 			continue
 		}
 
-		if !IsValidDomain(config.Domain) {
+		if len(cert.P12Path) > 0 && (len(cert.CertPemPath) > 0 || cert.Generate.Enabled) {
+			bootErr.Add(bootPath("cert", boot.Cert, cert)+".p12Path",
+				errors.New("p12Path could not be used with certPemPath, keyPemPath and generate"))
 			continue
 		}
 
-		// * or matching domain
-		// 1: add it to map if missing
-		if _, ok := configMap[config.Name]; !ok {
-			configMap[config.Name] = config
+		invalidPair := false
+		for i := range cert.Certs {
+			if len(cert.Certs[i].CertPemPath) < 1 || len(cert.Certs[i].KeyPemPath) < 1 {
+				bootErr.Add(fmt.Sprintf("%s.certs[%d]", bootPath("cert", boot.Cert, cert), i),
+					errors.New("certPemPath and keyPemPath should be provided together"))
+				invalidPair = true
+			}
+		}
+		if invalidPair {
 			continue
 		}
 
-		// 2: already has an entry, then compare domain,
-		//    only one case would occur, previous one is already the correct one, continue
-		if config.Domain == "" || config.Domain == "*" {
+		policy, err := newTLSPolicy(cert.ClientAuth, cert.MinVersion, cert.CipherSuites)
+		if err != nil {
+			bootErr.Add(bootPath("cert", boot.Cert, cert), err)
 			continue
 		}
+		entry.tlsPolicy = policy
 
-		configMap[config.Name] = config
-	}
+		if cert.Generate.Enabled {
+			if len(cert.CertPemPath) > 0 {
+				bootErr.Add(bootPath("cert", boot.Cert, cert)+".generate",
+					errors.New("generate could not be used with certPemPath and keyPemPath"))
+				continue
+			}
 
-	for _, cert := range configMap {
-		entry := &CertEntry{
-			entryName:        cert.Name,
-			entryType:        CertEntryType,
-			entryDescription: cert.Description,
-			caPath:           cert.CAPath,
-			keyPemPath:       cert.KeyPemPath,
-			certPemPath:      cert.CertPemPath,
-			embedFS:          GlobalAppCtx.GetEmbedFS(CertEntryType, cert.Name),
+			if err := cert.Generate.validate(); err != nil {
+				bootErr.Add(bootPath("cert", boot.Cert, cert)+".generate", err)
+				continue
+			}
+
+			generate := cert.Generate
+			entry.generate = &generate
 		}
 
-		GlobalAppCtx.AddEntry(entry)
+		appCtx.AddEntry(entry)
 		res = append(res, entry)
 	}
 
-	return res
+	return res, bootErr.ErrorOrNil()
 }
 
 // RegisterCertEntryYAML register function
 func RegisterCertEntryYAML(raw []byte) map[string]Entry {
+	res, err := RegisterCertEntryYAMLE(raw)
+	if err != nil {
+		ShutdownWithError(err)
+	}
+
+	return res
+}
+
+// RegisterCertEntryYAMLE is the same as RegisterCertEntryYAML but returns error instead of panic.
+func RegisterCertEntryYAMLE(raw []byte, opts ...RegOption) (map[string]Entry, error) {
 	boot := &BootCert{}
-	UnmarshalBootYAML(raw, boot)
+	if err := unmarshalBootYAMLE(NewRegOptions(opts...).AppCtx, raw, boot); err != nil {
+		return nil, err
+	}
 
 	res := map[string]Entry{}
 
-	entries := RegisterCertEntry(boot)
+	entries, err := RegisterCertEntryE(boot, opts...)
 	for i := range entries {
 		entry := entries[i]
 		res[entry.GetName()] = entry
 	}
 
-	return res
+	return res, err
 }
 
 // BootCert is bootstrap config of CertEntry.
@@ -90,58 +159,538 @@ type BootCertE struct {
 	Name        string `yaml:"name" json:"name"`
 	Description string `yaml:"description" json:"description"`
 	Domain      string `yaml:"domain" json:"domain"`
-	CAPath      string `yaml:"caPath" json:"caPath"`
-	CertPemPath string `yaml:"certPemPath" json:"certPemPath"`
-	KeyPemPath  string `yaml:"keyPemPath" json:"keyPemPath"`
+	// CAPath is CA bundle file or directory, used as RootCAs of client and ClientCAs of server
+	CAPath string `yaml:"caPath" json:"caPath"`
+	// ClientCAPath is CA bundle file or directory to verify client certificates, caPath is used if empty
+	ClientCAPath string `yaml:"clientCAPath" json:"clientCAPath"`
+	CertPemPath  string `yaml:"certPemPath" json:"certPemPath"`
+	KeyPemPath   string `yaml:"keyPemPath" json:"keyPemPath"`
+	// P12Path is PKCS#12 bundle of certificate, private key and chain, used instead of certPemPath and keyPemPath
+	P12Path string `yaml:"p12Path" json:"p12Path"`
+	// KeyPassword is password of encrypted private keys and PKCS#12 bundle,
+	// use ${env:NAME}, ${file:PATH} or ENC(BASE64CIPHERTEXT) decrypted by cryptoEntry instead of plain text
+	KeyPassword string `yaml:"keyPassword" json:"keyPassword"`
+	// CryptoEntry decrypts keyPassword of the form ENC(BASE64CIPHERTEXT)
+	CryptoEntry string `yaml:"cryptoEntry" json:"cryptoEntry"`
+	// Certs are additional certificates selected by SNI, certificate of certPemPath is the default one
+	Certs []*BootCertPair `yaml:"certs" json:"certs"`
+	// ClientAuth is one of none, request, require, verifyIfGiven and requireAndVerify, none by default
+	ClientAuth string `yaml:"clientAuth" json:"clientAuth"`
+	// MinVersion is one of 1.0, 1.1, 1.2 and 1.3, 1.2 by default
+	MinVersion   string   `yaml:"minVersion" json:"minVersion"`
+	CipherSuites []string `yaml:"cipherSuites" json:"cipherSuites"`
+	// Watch reload certificate and root CA once files changed, previous ones are kept if new files are invalid
+	Watch           bool `yaml:"watch" json:"watch"`
+	WatchDebounceMs int  `yaml:"watchDebounceMs" json:"watchDebounceMs"`
+	// Generate create certificate on first bootstrap instead of reading from path
+	Generate BootCertGenerate `yaml:"generate" json:"generate"`
+	// Expiry log warnings before certificates expire and fail readiness check once expired if enabled
+	Expiry BootCertExpiry `yaml:"expiry" json:"expiry"`
 }
 
 // CertEntry contains bellow fields.
+//
+// Certificate and RootCA are the default certificate and first CA, they are swapped while reloading,
+// use ServerTLSConfig() and ClientTLSConfig() in order to pick up new ones.
 type CertEntry struct {
 	entryName        string            `json:"-" yaml:"-"`
 	entryType        string            `json:"-" yaml:"-"`
 	entryDescription string            `json:"-" yaml:"-"`
 	caPath           string            `json:"-" yaml:"-"`
+	clientCAPath     string            `json:"-" yaml:"-"`
 	keyPemPath       string            `json:"-" yaml:"-"`
 	certPemPath      string            `json:"-" yaml:"-"`
+	p12Path          string            `json:"-" yaml:"-"`
+	keyPassword      string            `json:"-" yaml:"-"`
+	cryptoEntry      string            `json:"-" yaml:"-"`
+	appCtx           *AppContext       `json:"-" yaml:"-"`
+	certPairs        []*BootCertPair   `json:"-" yaml:"-"`
+	tlsPolicy        *tlsPolicy        `json:"-" yaml:"-"`
+	bundle           *certBundle       `json:"-" yaml:"-"`
 	embedFS          *embed.FS         `json:"-" yaml:"-"`
-	RootCA           *x509.Certificate `json:"-" json:"-"`
+	RootCA           *x509.Certificate `json:"-" yaml:"-"`
 	Certificate      *tls.Certificate  `json:"-" yaml:"-"`
 	bootstrapOnce    sync.Once         `yaml:"-" json:"-"`
+	bootstrapErr     error             `yaml:"-" json:"-"`
+	watch            bool              `yaml:"-" json:"-"`
+	watchDebounce    time.Duration     `yaml:"-" json:"-"`
+	watchStop        chan struct{}     `yaml:"-" json:"-"`
+	reloadCount      int64             `yaml:"-" json:"-"`
+	lastReloadErr    error             `yaml:"-" json:"-"`
+	generate         *BootCertGenerate `yaml:"-" json:"-"`
+	generated        *generatedCert    `yaml:"-" json:"-"`
+	expiry           BootCertExpiry    `yaml:"-" json:"-"`
+	expiryWarned     map[string]int    `yaml:"-" json:"-"`
+	expiryStop       chan struct{}     `yaml:"-" json:"-"`
+	lock             sync.RWMutex      `yaml:"-" json:"-"`
 }
 
 // Bootstrap iterate retrievers and call Retrieve() for each of them.
 func (entry *CertEntry) Bootstrap(ctx context.Context) {
+	if err := entry.BootstrapE(ctx); err != nil {
+		ShutdownWithError(err)
+	}
+}
+
+// BootstrapE is the same as Bootstrap but returns error instead of panic.
+//
+// Expiry of certificates is checked periodically, files would be watched if enabled.
+func (entry *CertEntry) BootstrapE(context.Context) error {
 	entry.bootstrapOnce.Do(func() {
-		// server cert path
-		if len(entry.keyPemPath) > 0 && len(entry.certPemPath) > 0 {
-			cert, err := tls.X509KeyPair(
-				readFile(entry.certPemPath, entry.embedFS, true),
-				readFile(entry.keyPemPath, entry.embedFS, true))
-			if err != nil {
-				ShutdownWithError(err)
+		if entry.bootstrapErr = entry.generateCert(); entry.bootstrapErr != nil {
+			return
+		}
+
+		if entry.bootstrapErr = entry.load(); entry.bootstrapErr != nil {
+			return
+		}
+
+		entry.checkExpiry(time.Now())
+		entry.startExpiryCheck()
+
+		if entry.watch {
+			entry.bootstrapErr = entry.startWatch()
+		}
+	})
+
+	return entry.bootstrapErr
+}
+
+// generateCert create certificate with generate config, nothing happens if generate is disabled.
+func (entry *CertEntry) generateCert() error {
+	entry.lock.RLock()
+	config := entry.generate
+	entry.lock.RUnlock()
+
+	if config == nil {
+		return nil
+	}
+
+	generator := &certGenerator{config: config, now: time.Now()}
+	generated, err := generator.generate()
+	if err != nil {
+		return fmt.Errorf("failed to generate certificate, %v", err)
+	}
+
+	entry.lock.Lock()
+	defer entry.lock.Unlock()
+
+	entry.generated = generated
+
+	return nil
+}
+
+// load certificates and CA from files.
+func (entry *CertEntry) load() error {
+	bundle, err := entry.loadFiles()
+	if err != nil {
+		return err
+	}
+
+	entry.lock.Lock()
+	defer entry.lock.Unlock()
+
+	entry.swap(bundle)
+
+	return nil
+}
+
+// swap certificates and CA with bundle, caller should hold lock.
+func (entry *CertEntry) swap(bundle *certBundle) {
+	entry.bundle = bundle
+	entry.Certificate = bundle.certificate()
+	entry.RootCA = bundle.rootCA()
+}
+
+// loadFiles read and parse certificates and CA from files, nothing is swapped.
+//
+// Certificates are verified with private keys and parsed into Leaf.
+func (entry *CertEntry) loadFiles() (*certBundle, error) {
+	entry.lock.RLock()
+	caPath, clientCAPath, p12Path, fs := entry.caPath, entry.clientCAPath, entry.p12Path, entry.embedFS
+	pairs := append([]*BootCertPair{}, entry.certPairs...)
+	if len(entry.certPemPath) > 0 && len(entry.keyPemPath) > 0 {
+		pairs = append([]*BootCertPair{{CertPemPath: entry.certPemPath, KeyPemPath: entry.keyPemPath}}, pairs...)
+	}
+	generated := entry.generated
+	entry.lock.RUnlock()
+
+	password, err := entry.resolveKeyPassword()
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve keyPassword, %v", err)
+	}
+
+	res := &certBundle{}
+
+	// generated certificate is the default one
+	if generated != nil {
+		cert, err := parseKeyPair(generated.certPem, generated.keyPem)
+		if err != nil {
+			return nil, err
+		}
+		res.certs = append(res.certs, cert)
+
+		if len(generated.clientCertPem) > 0 {
+			if res.clientCert, err = parseKeyPair(generated.clientCertPem, generated.clientKeyPem); err != nil {
+				return nil, err
 			}
+		}
+
+		if len(generated.caPem) > 0 {
+			if res.caCerts, err = parseCertsPem(generated.caPem); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// PKCS#12 is the default one
+	if len(p12Path) > 0 {
+		cert, err := loadPKCS12(p12Path, password, fs)
+		if err != nil {
+			return nil, err
+		}
+		res.certs = append(res.certs, cert)
+	}
+
+	for i := range pairs {
+		cert, err := loadKeyPair(pairs[i].CertPemPath, pairs[i].KeyPemPath, password, fs)
+		if err != nil {
+			return nil, err
+		}
+		res.certs = append(res.certs, cert)
+	}
+
+	if len(caPath) > 0 {
+		certs, err := readCACerts(caPath, fs)
+		if err != nil {
+			return nil, err
+		}
+		res.caCerts = append(res.caCerts, certs...)
+	}
+
+	if len(clientCAPath) > 0 {
+		certs, err := readCACerts(clientCAPath, fs)
+		if err != nil {
+			return nil, err
+		}
+		res.clientCACerts = certs
+	}
+
+	return res, nil
+}
+
+// verifyValidity returns error if any certificate is expired or not valid yet.
+func verifyValidity(bundle *certBundle, now time.Time) error {
+	for _, cert := range append([]*tls.Certificate{bundle.clientCert}, bundle.certs...) {
+		if cert == nil || cert.Leaf == nil {
+			continue
+		}
+
+		if now.After(cert.Leaf.NotAfter) {
+			return fmt.Errorf("certificate %s expired at %s", cert.Leaf.Subject, cert.Leaf.NotAfter.Format(time.RFC3339))
+		}
+
+		if now.Before(cert.Leaf.NotBefore) {
+			return fmt.Errorf("certificate %s is not valid before %s", cert.Leaf.Subject, cert.Leaf.NotBefore.Format(time.RFC3339))
+		}
+	}
+
+	return nil
+}
+
+// reloadFiles read certificates and CA from files and swap them if valid.
+func (entry *CertEntry) reloadFiles() error {
+	bundle, err := entry.loadFiles()
+	if err == nil {
+		err = verifyValidity(bundle, time.Now())
+	}
+
+	if err != nil {
+		LoggerEntryStdout.Warn("Failed to reload certificate, keep previous certificate",
+			zap.String("entry", entry.GetName()), zap.Error(err))
+	}
+
+	entry.lock.Lock()
+	entry.lastReloadErr = err
+	if err == nil {
+		entry.swap(bundle)
+		entry.reloadCount++
+	}
+	entry.lock.Unlock()
+
+	if err == nil {
+		entry.checkExpiry(time.Now())
+	}
+
+	return err
+}
+
+// GetCertificate returns certificate selected by SNI, used as tls.Config.GetCertificate of server.
+//
+// The default certificate is returned if none matched, new certificate is picked up on next handshake once reloaded.
+func (entry *CertEntry) GetCertificate(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	entry.lock.RLock()
+	defer entry.lock.RUnlock()
+
+	cert := selectCertificate(entry.certs(), hello)
+	if cert == nil {
+		return nil, fmt.Errorf("certificate is missing in CertEntry, name:%s", entry.GetName())
+	}
+
+	return cert, nil
+}
+
+// GetClientCertificate returns client certificate, used as tls.Config.GetClientCertificate of client.
+//
+// Generated client leaf is returned if any, otherwise the default certificate is returned.
+// Empty certificate is returned if missing, so that no certificate is sent to server.
+func (entry *CertEntry) GetClientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	entry.lock.RLock()
+	defer entry.lock.RUnlock()
+
+	if entry.bundle != nil && entry.bundle.clientCert != nil {
+		return entry.bundle.clientCert, nil
+	}
+
+	if entry.Certificate == nil {
+		return &tls.Certificate{}, nil
+	}
+
+	return entry.Certificate, nil
+}
+
+// GetRootCA returns current root CA, nil returned if missing.
+func (entry *CertEntry) GetRootCA() *x509.Certificate {
+	entry.lock.RLock()
+	defer entry.lock.RUnlock()
+
+	return entry.RootCA
+}
+
+// GetReloadCount returns times of certificate reloaded successfully by watcher.
+func (entry *CertEntry) GetReloadCount() int64 {
+	entry.lock.RLock()
+	defer entry.lock.RUnlock()
+
+	return entry.reloadCount
+}
+
+// GetLastReloadError returns error of last reload by watcher, nil returned if last reload succeeded.
+func (entry *CertEntry) GetLastReloadError() error {
+	entry.lock.RLock()
+	defer entry.lock.RUnlock()
+
+	return entry.lastReloadErr
+}
+
+// watchPaths returns cleaned absolute paths of certificates, private keys and CA.
+func (entry *CertEntry) watchPaths() []string {
+	paths := []string{entry.certPemPath, entry.keyPemPath, entry.p12Path, entry.caPath, entry.clientCAPath}
+	for i := range entry.certPairs {
+		paths = append(paths, entry.certPairs[i].CertPemPath, entry.certPairs[i].KeyPemPath)
+	}
+
+	res := make([]string, 0)
+	for _, p := range paths {
+		if len(p) < 1 {
+			continue
+		}
+
+		if abs, err := absConfigPath(p); err == nil {
+			res = append(res, filepath.Clean(abs))
+		}
+	}
+
+	return res
+}
+
+// startWatch start watching directories of files, nothing happens if already started or files are embedded.
+//
+// Directories are watched instead of files so that atomic renames and symlink swaps of Kubernetes Secret are caught.
+func (entry *CertEntry) startWatch() error {
+	entry.lock.Lock()
+	defer entry.lock.Unlock()
+
+	paths := entry.watchPaths()
+	if entry.watchStop != nil || entry.embedFS != nil || len(paths) < 1 {
+		return nil
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
 
-			entry.Certificate = &cert
+	dirs := map[string]bool{}
+	for i := range paths {
+		// files in CA directory are watched as well
+		dir := filepath.Dir(paths[i])
+		if info, err := os.Stat(paths[i]); err == nil && info.IsDir() {
+			dir = paths[i]
 		}
 
-		if len(entry.caPath) > 0 {
-			block, _ := pem.Decode(readFile(entry.caPath, entry.embedFS, true))
-			if block == nil || block.Type != "CERTIFICATE" || len(block.Headers) != 0 {
+		if dirs[dir] {
+			continue
+		}
+		dirs[dir] = true
+
+		if err := watcher.Add(dir); err != nil {
+			watcher.Close()
+			return fmt.Errorf("failed to watch certificate, path:%s, %v", paths[i], err)
+		}
+	}
+
+	stop := make(chan struct{})
+	entry.watchStop = stop
+
+	go entry.watchLoop(watcher, stop, paths, entry.watchDebounce)
+
+	return nil
+}
+
+// stopWatch stop watching files.
+func (entry *CertEntry) stopWatch() {
+	entry.lock.Lock()
+	defer entry.lock.Unlock()
+
+	if entry.watchStop != nil {
+		close(entry.watchStop)
+		entry.watchStop = nil
+	}
+}
+
+// watchLoop reload files after events settled down for debounce duration.
+//
+// Certificate and private key are usually written one by one, debounce makes sure they are loaded together.
+func (entry *CertEntry) watchLoop(watcher *fsnotify.Watcher, stop chan struct{}, paths []string, debounce time.Duration) {
+	defer watcher.Close()
+
+	realPaths := make([]string, len(paths))
+	for i := range paths {
+		realPaths[i], _ = filepath.EvalSymlinks(paths[i])
+	}
+	var timer *time.Timer
+
+	for {
+		select {
+		case <-stop:
+			if timer != nil {
+				timer.Stop()
+			}
+			return
+		case event, ok := <-watcher.Events:
+			if !ok {
 				return
 			}
 
-			cert, err := x509.ParseCertificate(block.Bytes)
-			if err != nil {
-				ShutdownWithError(err)
+			changed := false
+			for i := range paths {
+				// symlink of file changed, like ..data of Secret
+				currentPath, _ := filepath.EvalSymlinks(paths[i])
+				name := filepath.Clean(event.Name)
+				changed = changed || currentPath != realPaths[i] || name == paths[i] || filepath.Dir(name) == paths[i]
+				realPaths[i] = currentPath
+			}
+
+			if !changed {
+				continue
 			}
 
-			entry.RootCA = cert
+			if timer == nil {
+				timer = time.AfterFunc(debounce, func() {
+					entry.reloadFiles()
+				})
+			} else {
+				timer.Reset(debounce)
+			}
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			LoggerEntryStdout.Warn("Failed to watch certificate",
+				zap.String("entry", entry.GetName()), zap.Error(err))
 		}
-	})
+	}
 }
 
-// Interrupt entry.
-func (entry *CertEntry) Interrupt(context.Context) {}
+// Reload implements Reloadable, certificates, CA and TLS policy would be loaded again.
+//
+// Current certificate is kept if new one could not be loaded, watcher restarts if paths or watch option changed.
+func (entry *CertEntry) Reload(_ context.Context, raw []byte) error {
+	newEntry, err := reloadedEntry[*CertEntry](entry.appCtx, raw, entry.GetName(), RegisterCertEntryYAMLE,
+		func(ctx *AppContext) {
+			ctx.AddEmbedFS(CertEntryType, entry.GetName(), entry.embedFS)
+		})
+	if err != nil {
+		return err
+	}
+
+	// crypto entry of keyPassword is looked up in current AppContext
+	newEntry.appCtx = entry.appCtx
+
+	if err := newEntry.generateCert(); err != nil {
+		return err
+	}
+
+	bundle, err := newEntry.loadFiles()
+	if err != nil {
+		return err
+	}
+
+	if err := verifyValidity(bundle, time.Now()); err != nil {
+		return err
+	}
+
+	entry.lock.Lock()
+	restartWatch := entry.watchStop != nil &&
+		(!newEntry.watch || strings.Join(entry.watchPaths(), ",") != strings.Join(newEntry.watchPaths(), ","))
+	entry.caPath = newEntry.caPath
+	entry.clientCAPath = newEntry.clientCAPath
+	entry.keyPemPath = newEntry.keyPemPath
+	entry.certPemPath = newEntry.certPemPath
+	entry.p12Path = newEntry.p12Path
+	entry.keyPassword = newEntry.keyPassword
+	entry.cryptoEntry = newEntry.cryptoEntry
+	entry.certPairs = newEntry.certPairs
+	entry.tlsPolicy = newEntry.tlsPolicy
+	entry.watch = newEntry.watch
+	entry.watchDebounce = newEntry.watchDebounce
+	entry.generate = newEntry.generate
+	entry.generated = newEntry.generated
+	entry.expiry = newEntry.expiry
+	entry.swap(bundle)
+	watch := entry.watch
+	entry.lock.Unlock()
+
+	entry.checkExpiry(time.Now())
+
+	if restartWatch {
+		entry.stopWatch()
+	}
+
+	if watch {
+		return entry.startWatch()
+	}
+
+	return nil
+}
+
+// DependsOn returns CryptoEntry used to decrypt keyPassword.
+func (entry *CertEntry) DependsOn() []EntryRef {
+	res := make([]EntryRef, 0)
+
+	if len(entry.cryptoEntry) > 0 {
+		res = append(res, EntryRef{Type: CryptoEntryType, Name: entry.cryptoEntry})
+	}
+
+	return res
+}
+
+// Interrupt entry, stop watching files and checking expiry.
+func (entry *CertEntry) Interrupt(context.Context) {
+	entry.stopWatch()
+	entry.stopExpiryCheck()
+}
 
 // String return string of entry.
 func (entry *CertEntry) String() string {
@@ -151,13 +700,33 @@ func (entry *CertEntry) String() string {
 
 // MarshalJSON marshal entry
 func (entry *CertEntry) MarshalJSON() ([]byte, error) {
+	entry.lock.RLock()
 	m := map[string]interface{}{
-		"name":        entry.entryName,
-		"type":        entry.entryType,
-		"description": entry.entryDescription,
-		"caPath":      entry.caPath,
-		"keyPemPath":  entry.keyPemPath,
-		"certPemPath": entry.certPemPath,
+		"name":         entry.entryName,
+		"type":         entry.entryType,
+		"description":  entry.entryDescription,
+		"caPath":       entry.caPath,
+		"clientCAPath": entry.clientCAPath,
+		"keyPemPath":   entry.keyPemPath,
+		"certPemPath":  entry.certPemPath,
+		"p12Path":      entry.p12Path,
+		"cryptoEntry":  entry.cryptoEntry,
+		"certs":        entry.certPairs,
+		"watch":        entry.watch,
+		"reloadCount":  entry.reloadCount,
+	}
+
+	if entry.generate != nil {
+		m["generate"] = entry.generate
+	}
+	entry.lock.RUnlock()
+
+	if infos := entry.ListCertInfos(); len(infos) > 0 {
+		m["certInfos"] = infos
+	}
+
+	if err := entry.GetLastReloadError(); err != nil {
+		m["lastReloadError"] = err.Error()
 	}
 
 	return json.Marshal(&m)
@@ -4,6 +4,7 @@ import (
 	"context"
 	"crypto/rand"
 	"crypto/rsa"
+	"crypto/tls"
 	"crypto/x509"
 	"crypto/x509/pkix"
 	"encoding/pem"
@@ -11,6 +12,7 @@ import (
 	"math/big"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 )
@@ -107,7 +109,95 @@ func TestCertEntry_UnmarshalJSON(t *testing.T) {
 	assert.Nil(t, entries[0].UnmarshalJSON(nil))
 }
 
+func TestCertEntry_Watch(t *testing.T) {
+	dir := t.TempDir()
+	certPemPath := filepath.ToSlash(filepath.Join(dir, "cert.pem"))
+	keyPemPath := filepath.ToSlash(filepath.Join(dir, "key.pem"))
+
+	certPem, keyPem := generateCerts(t)
+	assert.Nil(t, os.WriteFile(certPemPath, certPem, os.ModePerm))
+	assert.Nil(t, os.WriteFile(keyPemPath, keyPem, os.ModePerm))
+
+	entries := RegisterCertEntry(&BootCert{
+		Cert: []*BootCertE{
+			{
+				Name:            "ut-cert",
+				CertPemPath:     certPemPath,
+				KeyPemPath:      keyPemPath,
+				Watch:           true,
+				WatchDebounceMs: 50,
+			},
+		},
+	}, WithAppCtx(NewAppContext()))
+	entry := entries[0]
+	assert.Nil(t, entry.BootstrapE(context.TODO()))
+	defer entry.Interrupt(context.TODO())
+
+	oldCert, err := entry.GetCertificate(nil)
+	assert.Nil(t, err)
+	assert.NotNil(t, oldCert.Leaf)
+
+	// rotated key pair is picked up
+	newCertPem, newKeyPem := generateCerts(t)
+	assert.Nil(t, os.WriteFile(certPemPath, newCertPem, os.ModePerm))
+	assert.Nil(t, os.WriteFile(keyPemPath, newKeyPem, os.ModePerm))
+	assert.Eventually(t, func() bool {
+		cert, _ := entry.GetClientCertificate(nil)
+		return entry.GetReloadCount() > 0 && cert != oldCert && entry.GetLastReloadError() == nil
+	}, 5*time.Second, 10*time.Millisecond)
+	newCert, _ := entry.GetCertificate(nil)
+
+	// mismatched key pair is ignored
+	assert.Nil(t, os.WriteFile(keyPemPath, keyPem, os.ModePerm))
+	assert.Eventually(t, func() bool {
+		return entry.GetLastReloadError() != nil
+	}, 5*time.Second, 10*time.Millisecond)
+	cert, _ := entry.GetCertificate(nil)
+	assert.Equal(t, newCert, cert)
+	assert.Contains(t, entry.String(), "lastReloadError")
+
+	// expired certificate is ignored
+	expiredCertPem, expiredKeyPem := generateCertsWithValidity(t, time.Now().Add(-2*time.Hour), time.Now().Add(-time.Hour))
+	assert.Nil(t, os.WriteFile(certPemPath, expiredCertPem, os.ModePerm))
+	assert.Nil(t, os.WriteFile(keyPemPath, expiredKeyPem, os.ModePerm))
+	assert.Eventually(t, func() bool {
+		err := entry.GetLastReloadError()
+		return err != nil && strings.Contains(err.Error(), "expired")
+	}, 5*time.Second, 10*time.Millisecond)
+	cert, _ = entry.GetCertificate(nil)
+	assert.Equal(t, newCert, cert)
+}
+
+func TestCertEntry_GetCertificate(t *testing.T) {
+	entry := &CertEntry{entryName: "ut-cert"}
+
+	// missing certificate
+	cert, err := entry.GetCertificate(nil)
+	assert.Nil(t, cert)
+	assert.NotNil(t, err)
+
+	cert, err = entry.GetClientCertificate(nil)
+	assert.Nil(t, err)
+	assert.Empty(t, cert.Certificate)
+	assert.Nil(t, entry.GetRootCA())
+
+	// used by tls.Config
+	certPem, keyPem := generateCerts(t)
+	pair, err := tls.X509KeyPair(certPem, keyPem)
+	assert.Nil(t, err)
+	entry.Certificate = &pair
+
+	conf := &tls.Config{GetCertificate: entry.GetCertificate}
+	cert, err = conf.GetCertificate(nil)
+	assert.Nil(t, err)
+	assert.Equal(t, &pair, cert)
+}
+
 func generateCerts(t *testing.T) ([]byte, []byte) {
+	return generateCertsWithValidity(t, time.Time{}, time.Now().Add(2*time.Hour))
+}
+
+func generateCertsWithValidity(t *testing.T, notBefore, notAfter time.Time) ([]byte, []byte) {
 	// Create certs and return as []byte
 	ca := &x509.Certificate{
 		Subject: pkix.Name{
@@ -119,7 +209,8 @@ func generateCerts(t *testing.T) ([]byte, []byte) {
 			PostalCode:    []string{"94016"},
 		},
 		SerialNumber:          big.NewInt(42),
-		NotAfter:              time.Now().Add(2 * time.Hour),
+		NotBefore:             notBefore,
+		NotAfter:              notAfter,
 		IsCA:                  true,
 		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth, x509.ExtKeyUsageServerAuth},
 		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
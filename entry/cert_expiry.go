@@ -0,0 +1,321 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkentry
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+	"sort"
+	"time"
+)
+
+const (
+	// CertKindLeaf is certificate served by CertEntry
+	CertKindLeaf = "leaf"
+	// CertKindIntermediate is intermediate certificate in chain of leaf
+	CertKindIntermediate = "intermediate"
+	// CertKindRootCA is root CA of CertEntry
+	CertKindRootCA = "rootCA"
+	// CertKindClientCA is CA to verify client certificates
+	CertKindClientCA = "clientCA"
+
+	// defaultCertExpiryCheckInterval is interval of checking expiry of certificates
+	defaultCertExpiryCheckInterval = time.Hour
+)
+
+// DefaultCertExpiryWarningDays are days before expiry when warning is logged.
+var DefaultCertExpiryWarningDays = []int{30, 7, 1}
+
+// BootCertExpiry is config of expiry monitoring of CertEntry.
+type BootCertExpiry struct {
+	// WarningDays are days before expiry when warning is logged, [30, 7, 1] by default
+	WarningDays []int `yaml:"warningDays" json:"warningDays"`
+	// FailReady make /rk/v1/ready of CommonServiceEntry fail if any certificate expired
+	FailReady bool `yaml:"failReady" json:"failReady"`
+}
+
+// CertInfo is information of certificate loaded by CertEntry.
+type CertInfo struct {
+	EntryName        string    `json:"entryName" yaml:"entryName" example:"my-cert"`
+	Kind             string    `json:"kind" yaml:"kind" example:"leaf"`
+	Subject          string    `json:"subject" yaml:"subject" example:"CN=localhost,O=rk-entry"`
+	SANs             []string  `json:"sans" yaml:"sans"`
+	Issuer           string    `json:"issuer" yaml:"issuer" example:"CN=localhost CA,O=rk-entry"`
+	Serial           string    `json:"serial" yaml:"serial" example:"4a3f"`
+	NotBefore        time.Time `json:"notBefore" yaml:"notBefore" example:"2022-03-15T20:43:05+08:00"`
+	NotAfter         time.Time `json:"notAfter" yaml:"notAfter" example:"2023-03-15T20:43:05+08:00"`
+	ExpiresInSeconds float64   `json:"expiresInSeconds" yaml:"expiresInSeconds" example:"31536000"`
+	Expired          bool      `json:"expired" yaml:"expired" example:"false"`
+}
+
+// newCertInfo create CertInfo of x509 certificate.
+func newCertInfo(entryName, kind string, cert *x509.Certificate, now time.Time) *CertInfo {
+	sans := append([]string{}, cert.DNSNames...)
+	for _, ip := range cert.IPAddresses {
+		sans = append(sans, ip.String())
+	}
+	sans = append(sans, cert.EmailAddresses...)
+	for _, uri := range cert.URIs {
+		sans = append(sans, uri.String())
+	}
+
+	return &CertInfo{
+		EntryName:        entryName,
+		Kind:             kind,
+		Subject:          cert.Subject.String(),
+		SANs:             sans,
+		Issuer:           cert.Issuer.String(),
+		Serial:           cert.SerialNumber.Text(16),
+		NotBefore:        cert.NotBefore,
+		NotAfter:         cert.NotAfter,
+		ExpiresInSeconds: cert.NotAfter.Sub(now).Seconds(),
+		Expired:          now.After(cert.NotAfter),
+	}
+}
+
+// ListCertInfos returns information of leaves, intermediates and CA loaded by CertEntry.
+func (entry *CertEntry) ListCertInfos() []*CertInfo {
+	return entry.certInfos(time.Now())
+}
+
+// certInfos returns information of certificate chains, CA and client CA.
+func (entry *CertEntry) certInfos(now time.Time) []*CertInfo {
+	entry.lock.RLock()
+	certs, caCerts := entry.certs(), entry.caCerts()
+	var clientCACerts []*x509.Certificate
+	if entry.bundle != nil {
+		clientCACerts = entry.bundle.clientCACerts
+		if entry.bundle.clientCert != nil {
+			certs = append(append([]*tls.Certificate{}, certs...), entry.bundle.clientCert)
+		}
+	}
+	entry.lock.RUnlock()
+
+	res := make([]*CertInfo, 0)
+	entryName := entry.GetName()
+
+	for _, cert := range certs {
+		for i := range cert.Certificate {
+			kind := CertKindIntermediate
+			parsed := cert.Leaf
+			if i == 0 {
+				kind = CertKindLeaf
+			}
+
+			if i > 0 || parsed == nil {
+				var err error
+				if parsed, err = x509.ParseCertificate(cert.Certificate[i]); err != nil {
+					continue
+				}
+			}
+
+			res = append(res, newCertInfo(entryName, kind, parsed, now))
+		}
+	}
+
+	for i := range caCerts {
+		res = append(res, newCertInfo(entryName, CertKindRootCA, caCerts[i], now))
+	}
+
+	for i := range clientCACerts {
+		res = append(res, newCertInfo(entryName, CertKindClientCA, clientCACerts[i], now))
+	}
+
+	return res
+}
+
+// certInfoKey returns key of certificate, serial is unique per issuer.
+func certInfoKey(kind, serial, issuer string) string {
+	return kind + "/" + serial + "/" + issuer
+}
+
+// ListExpiredCerts returns expired certificates of CertEntry whose expiry.failReady is true.
+func (ctx *AppContext) ListExpiredCerts() []*CertInfo {
+	res := make([]*CertInfo, 0)
+
+	for _, entry := range ListEntriesAs[*CertEntry](ctx) {
+		entry.lock.RLock()
+		failReady := entry.expiry.FailReady
+		entry.lock.RUnlock()
+
+		if !failReady {
+			continue
+		}
+
+		for _, info := range entry.ListCertInfos() {
+			if info.Expired {
+				res = append(res, info)
+			}
+		}
+	}
+
+	sort.SliceStable(res, func(i, j int) bool {
+		return res[i].EntryName < res[j].EntryName
+	})
+
+	return res
+}
+
+// expiryWarningDays returns warning days sorted in descending order.
+func (entry *CertEntry) expiryWarningDays() []int {
+	entry.lock.RLock()
+	defer entry.lock.RUnlock()
+
+	days := DefaultCertExpiryWarningDays
+	if len(entry.expiry.WarningDays) > 0 {
+		days = entry.expiry.WarningDays
+	}
+
+	res := append([]int{}, days...)
+	sort.Sort(sort.Reverse(sort.IntSlice(res)))
+
+	return res
+}
+
+// checkExpiry log warning once certificate crosses a threshold of warning days, and error once it expired.
+//
+// Each threshold is logged once per certificate, thresholds are reset if certificate is replaced.
+func (entry *CertEntry) checkExpiry(now time.Time) {
+	days := entry.expiryWarningDays()
+
+	entry.lock.Lock()
+	if entry.expiryWarned == nil {
+		entry.expiryWarned = map[string]int{}
+	}
+	warned := entry.expiryWarned
+	entry.lock.Unlock()
+
+	current := map[string]bool{}
+	for _, info := range entry.certInfos(now) {
+		key := certInfoKey(info.Kind, info.Serial, info.Issuer)
+		current[key] = true
+
+		// threshold is index of warning days crossed, len(days) means expired
+		threshold := -1
+		for i := range days {
+			if info.ExpiresInSeconds <= float64(days[i])*24*60*60 {
+				threshold = i
+			}
+		}
+		if info.Expired {
+			threshold = len(days)
+		}
+
+		entry.lock.Lock()
+		last, ok := warned[key]
+		if threshold < 0 || (ok && last >= threshold) {
+			entry.lock.Unlock()
+			continue
+		}
+		warned[key] = threshold
+		entry.lock.Unlock()
+
+		fields := []zap.Field{
+			zap.String("entry", entry.GetName()),
+			zap.String("kind", info.Kind),
+			zap.String("subject", info.Subject),
+			zap.String("serial", info.Serial),
+			zap.Time("notAfter", info.NotAfter),
+		}
+
+		if info.Expired {
+			LoggerEntryStdout.Error("Certificate expired", fields...)
+		} else {
+			LoggerEntryStdout.Warn(fmt.Sprintf("Certificate expires in %d days", days[threshold]), fields...)
+		}
+	}
+
+	// forget replaced certificates
+	entry.lock.Lock()
+	for k := range warned {
+		if !current[k] {
+			delete(warned, k)
+		}
+	}
+	entry.lock.Unlock()
+}
+
+// startExpiryCheck check expiry of certificates periodically, nothing happens if already started.
+func (entry *CertEntry) startExpiryCheck() {
+	entry.lock.Lock()
+	defer entry.lock.Unlock()
+
+	if entry.expiryStop != nil {
+		return
+	}
+
+	stop := make(chan struct{})
+	entry.expiryStop = stop
+
+	go func() {
+		ticker := time.NewTicker(defaultCertExpiryCheckInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-stop:
+				return
+			case now := <-ticker.C:
+				entry.checkExpiry(now)
+			}
+		}
+	}()
+}
+
+// stopExpiryCheck stop checking expiry of certificates.
+func (entry *CertEntry) stopExpiryCheck() {
+	entry.lock.Lock()
+	defer entry.lock.Unlock()
+
+	if entry.expiryStop != nil {
+		close(entry.expiryStop)
+		entry.expiryStop = nil
+	}
+}
+
+// certExpiryCollector exports expiry of certificates of CertEntry in AppContext.
+//
+// rk_cert_expiry_seconds is seconds until certificate expires, negative if expired.
+type certExpiryCollector struct {
+	appCtx *AppContext
+	expiry *prometheus.Desc
+}
+
+func newCertExpiryCollector(appCtx *AppContext) *certExpiryCollector {
+	return &certExpiryCollector{
+		appCtx: appCtx,
+		expiry: prometheus.NewDesc("rk_cert_expiry_seconds",
+			"Seconds until certificate expires, negative if expired.",
+			[]string{"entry_name", "kind", "subject", "issuer", "serial"}, nil),
+	}
+}
+
+// Describe implements prometheus.Collector
+func (c *certExpiryCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.expiry
+}
+
+// Collect implements prometheus.Collector
+//
+// Certificate shared by chains, like intermediate of several leaves, is exported once per CertEntry.
+func (c *certExpiryCollector) Collect(ch chan<- prometheus.Metric) {
+	for _, entry := range ListEntriesAs[*CertEntry](c.appCtx) {
+		seen := map[string]bool{}
+		for _, info := range entry.ListCertInfos() {
+			key := certInfoKey(info.Kind, info.Serial, info.Issuer)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+
+			ch <- prometheus.MustNewConstMetric(c.expiry, prometheus.GaugeValue, info.ExpiresInSeconds,
+				info.EntryName, info.Kind, info.Subject, info.Issuer, info.Serial)
+		}
+	}
+}
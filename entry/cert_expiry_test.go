@@ -0,0 +1,200 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkentry
+
+import (
+	"context"
+	"crypto/x509"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCertEntry_ListCertInfos(t *testing.T) {
+	entries := RegisterCertEntry(&BootCert{
+		Cert: []*BootCertE{
+			{
+				Name: "ut-cert",
+				Generate: BootCertGenerate{
+					Enabled:      true,
+					CommonName:   "ut.local",
+					ValidityDays: 10,
+					LocalCA:      true,
+				},
+			},
+		},
+	}, WithAppCtx(NewAppContext()))
+	entry := entries[0]
+	assert.Nil(t, entry.BootstrapE(context.TODO()))
+	defer entry.Interrupt(context.TODO())
+
+	infos := entry.ListCertInfos()
+	assert.Len(t, infos, 3)
+	assert.Equal(t, CertKindLeaf, infos[0].Kind)
+	assert.Equal(t, "ut-cert", infos[0].EntryName)
+	assert.Contains(t, infos[0].Subject, "CN=ut.local")
+	assert.Contains(t, infos[0].Issuer, "CN=ut.local CA")
+	assert.Equal(t, []string{"ut.local", "127.0.0.1", "::1"}, infos[0].SANs)
+	assert.NotEmpty(t, infos[0].Serial)
+	assert.InDelta(t, 10*24*time.Hour.Seconds(), infos[0].ExpiresInSeconds, 60)
+	assert.False(t, infos[0].Expired)
+	assert.Equal(t, CertKindLeaf, infos[1].Kind)
+	assert.Contains(t, infos[1].Subject, "CN=ut.local client")
+	assert.Equal(t, CertKindRootCA, infos[2].Kind)
+	assert.Contains(t, entry.String(), "ut.local")
+}
+
+func TestCertEntry_CheckExpiry(t *testing.T) {
+	entry := RegisterCertEntry(&BootCert{
+		Cert: []*BootCertE{
+			{
+				Name:     "ut-cert",
+				Generate: BootCertGenerate{Enabled: true, ValidityDays: 10},
+				Expiry:   BootCertExpiry{WarningDays: []int{1, 30, 7}},
+			},
+		},
+	}, WithAppCtx(NewAppContext()))[0]
+	assert.Nil(t, entry.BootstrapE(context.TODO()))
+	defer entry.Interrupt(context.TODO())
+
+	assert.Equal(t, []int{30, 7, 1}, entry.expiryWarningDays())
+	info := entry.ListCertInfos()[0]
+	key := certInfoKey(CertKindLeaf, info.Serial, info.Issuer)
+
+	// crossed 30 days while bootstrapping
+	assert.Equal(t, 0, entry.expiryWarned[key])
+
+	// crossed 7 and 1 days
+	entry.checkExpiry(time.Now().Add(4 * 24 * time.Hour))
+	assert.Equal(t, 1, entry.expiryWarned[key])
+	entry.checkExpiry(time.Now().Add(9*24*time.Hour + time.Hour))
+	assert.Equal(t, 2, entry.expiryWarned[key])
+
+	// expired
+	entry.checkExpiry(time.Now().Add(11 * 24 * time.Hour))
+	assert.Equal(t, 3, entry.expiryWarned[key])
+
+	// not logged again
+	entry.checkExpiry(time.Now().Add(4 * 24 * time.Hour))
+	assert.Equal(t, 3, entry.expiryWarned[key])
+}
+
+func TestAppContext_ListExpiredCerts(t *testing.T) {
+	certPem, keyPem := generateCertsWithValidity(t, time.Now().Add(-2*time.Hour), time.Now().Add(-time.Hour))
+	certPemPath := filepath.ToSlash(filepath.Join(t.TempDir(), "cert.pem"))
+	keyPemPath := filepath.ToSlash(filepath.Join(t.TempDir(), "key.pem"))
+	assert.Nil(t, os.WriteFile(certPemPath, certPem, os.ModePerm))
+	assert.Nil(t, os.WriteFile(keyPemPath, keyPem, os.ModePerm))
+
+	appCtx := NewAppContext()
+	entry := RegisterCertEntry(&BootCert{
+		Cert: []*BootCertE{
+			{
+				Name:        "ut-cert",
+				CertPemPath: certPemPath,
+				KeyPemPath:  keyPemPath,
+			},
+		},
+	}, WithAppCtx(appCtx))[0]
+	assert.Nil(t, entry.BootstrapE(context.TODO()))
+	defer entry.Interrupt(context.TODO())
+	assert.True(t, entry.ListCertInfos()[0].Expired)
+
+	// exported by collector
+	registry := prometheus.NewRegistry()
+	assert.Nil(t, registry.Register(newCertExpiryCollector(appCtx)))
+	families, err := registry.Gather()
+	assert.Nil(t, err)
+	assert.Len(t, families, 1)
+	assert.Equal(t, "rk_cert_expiry_seconds", families[0].GetName())
+	assert.Less(t, families[0].GetMetric()[0].GetGauge().GetValue(), 0.0)
+
+	// readiness is not affected by default
+	assert.Empty(t, appCtx.ListExpiredCerts())
+	service := RegisterCommonServiceEntry(&BootCommonService{
+		Enabled: true,
+	}, WithAppCtxCommonServiceEntry(appCtx))
+	writer := httptest.NewRecorder()
+	service.Ready(writer, nil)
+	assert.Equal(t, 200, writer.Code)
+
+	// with failReady
+	entry.expiry.FailReady = true
+	assert.Len(t, appCtx.ListExpiredCerts(), 1)
+	writer = httptest.NewRecorder()
+	service.Ready(writer, nil)
+	assert.Equal(t, 503, writer.Code)
+	assert.Contains(t, writer.Body.String(), "certs")
+}
+
+func TestCertExpiryCollector_WithSharedIntermediate(t *testing.T) {
+	g := &certGenerator{config: &BootCertGenerate{ValidityDays: 10}, now: time.Now()}
+	ca, caKey, caPem, err := g.readOrCreateCA()
+	assert.Nil(t, err)
+
+	// intermediate signed by CA
+	tmpl, err := g.template("ut intermediate")
+	assert.Nil(t, err)
+	tmpl.IsCA = true
+	tmpl.BasicConstraintsValid = true
+	tmpl.KeyUsage = x509.KeyUsageCertSign
+	interKey, err := g.newKey()
+	assert.Nil(t, err)
+	interPem, _, err := g.sign(tmpl, ca, interKey, caKey)
+	assert.Nil(t, err)
+	inter, err := parseCertPem(interPem)
+	assert.Nil(t, err)
+
+	// two leaves with chain of the same intermediate
+	dir := t.TempDir()
+	pairs := make([]*BootCertPair, 0)
+	for _, name := range []string{"ut-1", "ut-2"} {
+		certPem, keyPem, err := g.newLeaf(name, []string{name}, inter, interKey, x509.ExtKeyUsageServerAuth)
+		assert.Nil(t, err)
+
+		pair := &BootCertPair{
+			CertPemPath: filepath.Join(dir, name+".pem"),
+			KeyPemPath:  filepath.Join(dir, name+"-key.pem"),
+		}
+		assert.Nil(t, os.WriteFile(pair.CertPemPath, append(certPem, interPem...), os.ModePerm))
+		assert.Nil(t, os.WriteFile(pair.KeyPemPath, keyPem, os.ModePerm))
+		pairs = append(pairs, pair)
+	}
+	caPath := filepath.Join(dir, "ca.pem")
+	assert.Nil(t, os.WriteFile(caPath, caPem, os.ModePerm))
+
+	appCtx := NewAppContext()
+	entry := RegisterCertEntry(&BootCert{
+		Cert: []*BootCertE{
+			{
+				Name:   "ut-cert",
+				Certs:  pairs,
+				CAPath: caPath,
+			},
+		},
+	}, WithAppCtx(appCtx))[0]
+	assert.Nil(t, entry.BootstrapE(context.TODO()))
+	defer entry.Interrupt(context.TODO())
+
+	// intermediate is listed in both chains
+	kinds := map[string]int{}
+	for _, info := range entry.ListCertInfos() {
+		kinds[info.Kind]++
+	}
+	assert.Equal(t, map[string]int{CertKindLeaf: 2, CertKindIntermediate: 2, CertKindRootCA: 1}, kinds)
+
+	// but gathered once without duplicated series
+	registry := prometheus.NewRegistry()
+	assert.Nil(t, registry.Register(newCertExpiryCollector(appCtx)))
+	families, err := registry.Gather()
+	assert.Nil(t, err)
+	assert.Len(t, families, 1)
+	assert.Len(t, families[0].GetMetric(), 4)
+}
@@ -0,0 +1,489 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkentry
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// CertKeyTypeRSA generates 2048 bits RSA key
+	CertKeyTypeRSA = "rsa"
+	// CertKeyTypeECDSA generates ECDSA key with P-256 curve
+	CertKeyTypeECDSA = "ecdsa"
+	// CertKeyTypeEd25519 generates Ed25519 key
+	CertKeyTypeEd25519 = "ed25519"
+
+	defaultCertGenerateCommonName   = "localhost"
+	defaultCertGenerateValidityDays = 365
+
+	// file names of generated certificates in dir
+	generatedCAFile      = "ca.pem"
+	generatedCAKeyFile   = "ca-key.pem"
+	generatedCertFile    = "cert.pem"
+	generatedCertKeyFile = "key.pem"
+
+	generatedClientCertFile    = "client.pem"
+	generatedClientCertKeyFile = "client-key.pem"
+)
+
+// BootCertGenerate is config of certificate generated by CertEntry, for local development and testing.
+//
+// Certificate is self-signed and used by both server and client, or server and client leaves are signed by local CA
+// if localCA is true. Generated files are persisted into dir and reused afterwards, they are regenerated if config
+// changed or a third of validity remains, files are kept in memory if dir is empty.
+type BootCertGenerate struct {
+	Enabled      bool     `yaml:"enabled" json:"enabled"`
+	CommonName   string   `yaml:"commonName" json:"commonName"`
+	SANs         []string `yaml:"sans" json:"sans"`
+	ValidityDays int      `yaml:"validityDays" json:"validityDays"`
+	// KeyType is one of rsa, ecdsa and ed25519, ecdsa by default
+	KeyType string `yaml:"keyType" json:"keyType"`
+	LocalCA bool   `yaml:"localCA" json:"localCA"`
+	Dir     string `yaml:"dir" json:"dir"`
+}
+
+// validate generate config.
+func (g *BootCertGenerate) validate() error {
+	switch strings.ToLower(g.KeyType) {
+	case "", CertKeyTypeRSA, CertKeyTypeECDSA, CertKeyTypeEd25519:
+	default:
+		return fmt.Errorf("invalid keyType %s, expect one of rsa, ecdsa and ed25519", g.KeyType)
+	}
+
+	if g.ValidityDays < 0 {
+		return fmt.Errorf("invalid validityDays %d", g.ValidityDays)
+	}
+
+	return nil
+}
+
+// generatedCert is PEM of certificates, private keys and CA generated by CertEntry.
+type generatedCert struct {
+	certPem       []byte
+	keyPem        []byte
+	clientCertPem []byte
+	clientKeyPem  []byte
+	caPem         []byte
+}
+
+// certGenerator generates CA and leaf certificates with BootCertGenerate.
+type certGenerator struct {
+	config *BootCertGenerate
+	now    time.Time
+}
+
+// generate create certificates, existing files in dir are reused if they still match config and are not close to expiry.
+//
+// With localCA, server and client leaves are signed by CA. Without it, one self-signed leaf is used for both,
+// since peers could only trust self-signed certificate itself, a second leaf would have to be trusted separately.
+//
+// CA is reused even if leaves are regenerated, so that clients which trust it keep working.
+func (g *certGenerator) generate() (*generatedCert, error) {
+	if len(g.config.Dir) > 0 {
+		if res := g.readDir(); res != nil {
+			return res, nil
+		}
+	}
+
+	res := &generatedCert{}
+
+	if !g.config.LocalCA {
+		var err error
+		res.certPem, res.keyPem, err = g.newLeaf(g.commonName(), g.sans(), nil, nil,
+			x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth)
+		if err != nil {
+			return nil, err
+		}
+
+		return res, g.writeFiles(map[string][]byte{
+			generatedCertFile:    res.certPem,
+			generatedCertKeyFile: res.keyPem,
+		})
+	}
+
+	ca, caKey, caPem, err := g.readOrCreateCA()
+	if err != nil {
+		return nil, err
+	}
+	res.caPem = caPem
+
+	if res.certPem, res.keyPem, err = g.newLeaf(g.commonName(), g.sans(), ca, caKey, x509.ExtKeyUsageServerAuth); err != nil {
+		return nil, err
+	}
+
+	if res.clientCertPem, res.clientKeyPem, err = g.newLeaf(g.commonName()+" client", nil, ca, caKey, x509.ExtKeyUsageClientAuth); err != nil {
+		return nil, err
+	}
+
+	return res, g.writeFiles(map[string][]byte{
+		generatedCertFile:          res.certPem,
+		generatedCertKeyFile:       res.keyPem,
+		generatedClientCertFile:    res.clientCertPem,
+		generatedClientCertKeyFile: res.clientKeyPem,
+	})
+}
+
+// newLeaf create leaf certificate with extended key usages, it is self-signed if parent is nil.
+func (g *certGenerator) newLeaf(commonName string, sans []string, parent *x509.Certificate, parentKey crypto.Signer,
+	usages ...x509.ExtKeyUsage) ([]byte, []byte, error) {
+	key, err := g.newKey()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tmpl, err := g.template(commonName)
+	if err != nil {
+		return nil, nil, err
+	}
+	tmpl.KeyUsage = x509.KeyUsageDigitalSignature
+	if _, ok := key.(*rsa.PrivateKey); ok {
+		tmpl.KeyUsage |= x509.KeyUsageKeyEncipherment
+	}
+	tmpl.ExtKeyUsage = usages
+
+	for _, san := range sans {
+		if ip := net.ParseIP(san); ip != nil {
+			tmpl.IPAddresses = append(tmpl.IPAddresses, ip)
+		} else {
+			tmpl.DNSNames = append(tmpl.DNSNames, san)
+		}
+	}
+
+	// self-signed
+	if parent == nil {
+		parent, parentKey = tmpl, key
+	}
+
+	return g.sign(tmpl, parent, key, parentKey)
+}
+
+// readDir returns generated certificates in dir, nil returned if any of them is missing, close to expiry,
+// or generated with config other than current one.
+func (g *certGenerator) readDir() *generatedCert {
+	res := &generatedCert{}
+	var cert *x509.Certificate
+	var ok bool
+	if res.certPem, res.keyPem, cert, ok = g.readPair(generatedCertFile, generatedCertKeyFile); !ok {
+		return nil
+	}
+
+	if cert.Subject.CommonName != g.commonName() || !equalSANs(cert, g.sans()) {
+		return nil
+	}
+
+	if !g.config.LocalCA {
+		// leaf signed by local CA previously should be replaced with self-signed one
+		if !bytes.Equal(cert.RawIssuer, cert.RawSubject) || cert.CheckSignature(cert.SignatureAlgorithm, cert.RawTBSCertificate, cert.Signature) != nil {
+			return nil
+		}
+
+		return res
+	}
+
+	var client *x509.Certificate
+	if res.clientCertPem, res.clientKeyPem, client, ok = g.readPair(generatedClientCertFile, generatedClientCertKeyFile); !ok {
+		return nil
+	}
+
+	caPem, caKeyPem, err := g.readFiles(generatedCAFile, generatedCAKeyFile)
+	if err != nil {
+		return nil
+	}
+	res.caPem = caPem
+
+	// leaves should be signed by CA in dir
+	ca, err := parseCertPem(caPem)
+	caKey, keyErr := parseKeyPem(caKeyPem)
+	if err != nil || keyErr != nil || !g.reusable(ca, caKey) ||
+		cert.CheckSignatureFrom(ca) != nil || client.CheckSignatureFrom(ca) != nil {
+		return nil
+	}
+
+	return res
+}
+
+// readPair returns certificate and private key in dir if they match each other and are reusable.
+func (g *certGenerator) readPair(certFile, keyFile string) ([]byte, []byte, *x509.Certificate, bool) {
+	certPem, keyPem, err := g.readFiles(certFile, keyFile)
+	if err != nil {
+		return nil, nil, nil, false
+	}
+
+	pair, err := tls.X509KeyPair(certPem, keyPem)
+	if err != nil {
+		return nil, nil, nil, false
+	}
+
+	cert, err := x509.ParseCertificate(pair.Certificate[0])
+	if err != nil {
+		return nil, nil, nil, false
+	}
+
+	key, ok := pair.PrivateKey.(crypto.Signer)
+	if !ok || !g.reusable(cert, key) {
+		return nil, nil, nil, false
+	}
+
+	return certPem, keyPem, cert, true
+}
+
+// reusable returns true if key type is the same as config and certificate is not close to expiry.
+//
+// Certificate is renewed once less than a third of validity remains.
+func (g *certGenerator) reusable(cert *x509.Certificate, key crypto.Signer) bool {
+	if certKeyType(key) != g.keyType() {
+		return false
+	}
+
+	return g.now.Before(cert.NotAfter.Add(-cert.NotAfter.Sub(cert.NotBefore) / 3))
+}
+
+// readOrCreateCA returns CA in dir, new CA is created if missing, close to expiry or with other key type.
+func (g *certGenerator) readOrCreateCA() (*x509.Certificate, crypto.Signer, []byte, error) {
+	if len(g.config.Dir) > 0 {
+		if caPem, caKeyPem, err := g.readFiles(generatedCAFile, generatedCAKeyFile); err == nil {
+			ca, err := parseCertPem(caPem)
+			key, keyErr := parseKeyPem(caKeyPem)
+			if err == nil && keyErr == nil && g.reusable(ca, key) {
+				return ca, key, caPem, nil
+			}
+		}
+	}
+
+	key, err := g.newKey()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	tmpl, err := g.template(g.commonName() + " CA")
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	tmpl.IsCA = true
+	tmpl.BasicConstraintsValid = true
+	tmpl.KeyUsage = x509.KeyUsageCertSign | x509.KeyUsageCRLSign | x509.KeyUsageDigitalSignature
+
+	caPem, caKeyPem, err := g.sign(tmpl, tmpl, key, key)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	if err := g.writeFiles(map[string][]byte{generatedCAFile: caPem, generatedCAKeyFile: caKeyPem}); err != nil {
+		return nil, nil, nil, err
+	}
+
+	ca, err := parseCertPem(caPem)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return ca, key, caPem, nil
+}
+
+// template returns certificate template with random serial number and validity.
+func (g *certGenerator) template(commonName string) (*x509.Certificate, error) {
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, err
+	}
+
+	days := g.config.ValidityDays
+	if days < 1 {
+		days = defaultCertGenerateValidityDays
+	}
+
+	return &x509.Certificate{
+		SerialNumber: serial,
+		Subject: pkix.Name{
+			CommonName:   commonName,
+			Organization: []string{"rk-entry"},
+		},
+		NotBefore: g.now.Add(-time.Minute),
+		NotAfter:  g.now.Add(time.Duration(days) * 24 * time.Hour),
+	}, nil
+}
+
+// sign certificate with parent and returns PEM of certificate and private key.
+func (g *certGenerator) sign(tmpl, parent *x509.Certificate, key, parentKey crypto.Signer) ([]byte, []byte, error) {
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, parent, key.Public(), parentKey)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	keyDer, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDer}), nil
+}
+
+// newKey generates private key with key type.
+func (g *certGenerator) newKey() (crypto.Signer, error) {
+	switch g.keyType() {
+	case CertKeyTypeRSA:
+		return rsa.GenerateKey(rand.Reader, 2048)
+	case CertKeyTypeEd25519:
+		_, key, err := ed25519.GenerateKey(rand.Reader)
+		return key, err
+	default:
+		return ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	}
+}
+
+// keyType returns key type in lower case, ecdsa by default.
+func (g *certGenerator) keyType() string {
+	if len(g.config.KeyType) > 0 {
+		return strings.ToLower(g.config.KeyType)
+	}
+
+	return CertKeyTypeECDSA
+}
+
+// certKeyType returns key type of private key, empty string returned if not supported.
+func certKeyType(key crypto.Signer) string {
+	switch key.(type) {
+	case *rsa.PrivateKey:
+		return CertKeyTypeRSA
+	case *ecdsa.PrivateKey:
+		return CertKeyTypeECDSA
+	case ed25519.PrivateKey:
+		return CertKeyTypeEd25519
+	}
+
+	return ""
+}
+
+// equalSANs returns true if DNS names and IP addresses of certificate are the same as sans.
+func equalSANs(cert *x509.Certificate, sans []string) bool {
+	expect := make([]string, 0, len(sans))
+	for _, san := range sans {
+		if ip := net.ParseIP(san); ip != nil {
+			expect = append(expect, ip.String())
+		} else {
+			expect = append(expect, san)
+		}
+	}
+
+	actual := append([]string{}, cert.DNSNames...)
+	for _, ip := range cert.IPAddresses {
+		actual = append(actual, ip.String())
+	}
+
+	sort.Strings(expect)
+	sort.Strings(actual)
+
+	return reflect.DeepEqual(expect, actual)
+}
+
+// sans returns SANs of leaf certificate, localhost and loopback addresses by default.
+func (g *certGenerator) sans() []string {
+	if len(g.config.SANs) > 0 {
+		return g.config.SANs
+	}
+
+	return []string{g.commonName(), "127.0.0.1", "::1"}
+}
+
+// commonName returns common name, localhost by default.
+func (g *certGenerator) commonName() string {
+	if len(g.config.CommonName) > 0 {
+		return g.config.CommonName
+	}
+
+	return defaultCertGenerateCommonName
+}
+
+// path returns path of file in dir.
+func (g *certGenerator) path(name string) string {
+	return filepath.Join(g.config.Dir, name)
+}
+
+// readFiles read certificate and private key in dir.
+func (g *certGenerator) readFiles(certFile, keyFile string) ([]byte, []byte, error) {
+	certPem, err := os.ReadFile(g.path(certFile))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	keyPem, err := os.ReadFile(g.path(keyFile))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return certPem, keyPem, nil
+}
+
+// writeFiles write files into dir, only owner could read them, nothing happens if dir is empty.
+func (g *certGenerator) writeFiles(files map[string][]byte) error {
+	if len(g.config.Dir) < 1 {
+		return nil
+	}
+
+	if err := os.MkdirAll(g.config.Dir, 0700); err != nil {
+		return err
+	}
+
+	for name, data := range files {
+		if err := os.WriteFile(g.path(name), data, 0600); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// parseCertPem parse first certificate in PEM.
+func parseCertPem(data []byte) (*x509.Certificate, error) {
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, errors.New("certificate not found in PEM")
+	}
+
+	return x509.ParseCertificate(block.Bytes)
+}
+
+// parseKeyPem parse PKCS#8 private key in PEM.
+func parseKeyPem(data []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("private key not found in PEM")
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, errors.New("private key could not sign")
+	}
+
+	return signer, nil
+}
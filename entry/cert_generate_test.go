@@ -0,0 +1,238 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkentry
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCertEntry_Generate(t *testing.T) {
+	for keyType, expect := range map[string]interface{}{
+		"":                 &ecdsa.PrivateKey{},
+		CertKeyTypeRSA:     &rsa.PrivateKey{},
+		CertKeyTypeECDSA:   &ecdsa.PrivateKey{},
+		CertKeyTypeEd25519: ed25519.PrivateKey{},
+	} {
+		entries := RegisterCertEntry(&BootCert{
+			Cert: []*BootCertE{
+				{
+					Name: "ut-cert",
+					Generate: BootCertGenerate{
+						Enabled:      true,
+						CommonName:   "ut.local",
+						SANs:         []string{"ut.local", "127.0.0.1"},
+						ValidityDays: 10,
+						KeyType:      keyType,
+					},
+				},
+			},
+		}, WithAppCtx(NewAppContext()))
+		entry := entries[0]
+		assert.Nil(t, entry.BootstrapE(context.TODO()))
+
+		// self-signed in memory
+		cert, err := entry.GetCertificate(nil)
+		assert.Nil(t, err)
+		assert.IsType(t, expect, cert.PrivateKey)
+		assert.Equal(t, "ut.local", cert.Leaf.Subject.CommonName)
+		assert.Equal(t, []string{"ut.local"}, cert.Leaf.DNSNames)
+		assert.Len(t, cert.Leaf.IPAddresses, 1)
+		assert.True(t, cert.Leaf.NotAfter.Before(time.Now().Add(11*24*time.Hour)))
+		assert.Nil(t, entry.GetRootCA())
+		assert.Contains(t, entry.String(), "generate")
+	}
+}
+
+func TestCertEntry_GenerateWithLocalCA(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "certs")
+	raw := `
+cert:
+  - name: ut-cert
+    generate:
+      enabled: true
+      localCA: true
+      dir: ` + filepath.ToSlash(dir) + `
+`
+	entries, err := RegisterCertEntryYAMLE([]byte(raw), WithAppCtx(NewAppContext()))
+	assert.Nil(t, err)
+	assert.Nil(t, ValidateBootYAML([]byte(raw)))
+	entry := entries["ut-cert"].(*CertEntry)
+	assert.Nil(t, entry.BootstrapE(context.TODO()))
+
+	for _, name := range []string{generatedCAFile, generatedCAKeyFile, generatedCertFile, generatedCertKeyFile,
+		generatedClientCertFile, generatedClientCertKeyFile} {
+		assert.FileExists(t, filepath.Join(dir, name))
+	}
+
+	cert, _ := entry.GetCertificate(nil)
+	rootCA := entry.GetRootCA()
+	assert.NotNil(t, rootCA)
+	assert.Nil(t, cert.Leaf.CheckSignatureFrom(rootCA))
+	assert.Equal(t, []string{"localhost"}, cert.Leaf.DNSNames)
+	assert.Equal(t, []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}, cert.Leaf.ExtKeyUsage)
+
+	// client leaf is signed by the same CA
+	clientCert, _ := entry.GetClientCertificate(nil)
+	assert.Nil(t, clientCert.Leaf.CheckSignatureFrom(rootCA))
+	assert.Equal(t, "localhost client", clientCert.Leaf.Subject.CommonName)
+	assert.Equal(t, []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}, clientCert.Leaf.ExtKeyUsage)
+
+	// mutual TLS with generated certificate
+	pool := x509.NewCertPool()
+	pool.AddCert(rootCA)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	server.TLS = &tls.Config{
+		GetCertificate: entry.GetCertificate,
+		ClientCAs:      pool,
+		ClientAuth:     tls.RequireAndVerifyClientCert,
+	}
+	server.StartTLS()
+	defer server.Close()
+
+	client := &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{
+				RootCAs:              pool,
+				GetClientCertificate: entry.GetClientCertificate,
+			},
+		},
+	}
+	resp, err := client.Get(strings.Replace(server.URL, "127.0.0.1", "localhost", 1))
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	resp.Body.Close()
+
+	// reused afterwards
+	entries, err = RegisterCertEntryYAMLE([]byte(raw), WithAppCtx(NewAppContext()))
+	assert.Nil(t, err)
+	reused := entries["ut-cert"].(*CertEntry)
+	assert.Nil(t, reused.BootstrapE(context.TODO()))
+	reusedCert, _ := reused.GetCertificate(nil)
+	assert.Equal(t, cert.Certificate, reusedCert.Certificate)
+	assert.True(t, rootCA.Equal(reused.GetRootCA()))
+
+	// CA is kept while leaf is regenerated
+	assert.Nil(t, os.Remove(filepath.Join(dir, generatedCertFile)))
+	entries, err = RegisterCertEntryYAMLE([]byte(raw), WithAppCtx(NewAppContext()))
+	assert.Nil(t, err)
+	regenerated := entries["ut-cert"].(*CertEntry)
+	assert.Nil(t, regenerated.BootstrapE(context.TODO()))
+	regeneratedCert, _ := regenerated.GetCertificate(nil)
+	assert.NotEqual(t, cert.Certificate, regeneratedCert.Certificate)
+	assert.True(t, rootCA.Equal(regenerated.GetRootCA()))
+}
+
+func TestCertGenerator_Regenerate(t *testing.T) {
+	dir := t.TempDir()
+	config := &BootCertGenerate{
+		Enabled:      true,
+		CommonName:   "ut.local",
+		ValidityDays: 30,
+		LocalCA:      true,
+		Dir:          dir,
+	}
+	now := time.Now()
+
+	generated, err := (&certGenerator{config: config, now: now}).generate()
+	assert.Nil(t, err)
+
+	// reused with the same config
+	res, err := (&certGenerator{config: config, now: now}).generate()
+	assert.Nil(t, err)
+	assert.Equal(t, generated, res)
+
+	// leaves are regenerated once common name or SANs changed, CA is kept
+	for _, changed := range []*BootCertGenerate{
+		{Enabled: true, CommonName: "ut.other", ValidityDays: 30, LocalCA: true, Dir: dir},
+		{Enabled: true, CommonName: "ut.local", SANs: []string{"ut.local"}, ValidityDays: 30, LocalCA: true, Dir: dir},
+	} {
+		res, err = (&certGenerator{config: changed, now: now}).generate()
+		assert.Nil(t, err)
+		assert.NotEqual(t, generated.certPem, res.certPem)
+		assert.NotEqual(t, generated.clientCertPem, res.clientCertPem)
+		assert.Equal(t, generated.caPem, res.caPem)
+		generated = res
+	}
+
+	// everything is regenerated with the other key type
+	config = &BootCertGenerate{Enabled: true, CommonName: "ut.local", SANs: []string{"ut.local"},
+		ValidityDays: 30, KeyType: CertKeyTypeRSA, LocalCA: true, Dir: dir}
+	res, err = (&certGenerator{config: config, now: now}).generate()
+	assert.Nil(t, err)
+	assert.NotEqual(t, generated.certPem, res.certPem)
+	assert.NotEqual(t, generated.caPem, res.caPem)
+	cert, err := parseKeyPair(res.certPem, res.keyPem)
+	assert.Nil(t, err)
+	assert.IsType(t, &rsa.PrivateKey{}, cert.PrivateKey)
+	generated = res
+
+	// everything is regenerated once a third of validity remains
+	res, err = (&certGenerator{config: config, now: now.Add(19 * 24 * time.Hour)}).generate()
+	assert.Nil(t, err)
+	assert.Equal(t, generated, res)
+	res, err = (&certGenerator{config: config, now: now.Add(21 * 24 * time.Hour)}).generate()
+	assert.Nil(t, err)
+	assert.NotEqual(t, generated.certPem, res.certPem)
+	assert.NotEqual(t, generated.caPem, res.caPem)
+
+	// self-signed leaf replaces the one signed by local CA
+	config.LocalCA = false
+	res, err = (&certGenerator{config: config, now: now}).generate()
+	assert.Nil(t, err)
+	assert.Empty(t, res.caPem)
+	assert.Empty(t, res.clientCertPem)
+	leaf, err := parseCertPem(res.certPem)
+	assert.Nil(t, err)
+	assert.Equal(t, leaf.Issuer.String(), leaf.Subject.String())
+	assert.Equal(t, []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth}, leaf.ExtKeyUsage)
+
+	// and reused afterwards
+	reused, err := (&certGenerator{config: config, now: now}).generate()
+	assert.Nil(t, err)
+	assert.Equal(t, res, reused)
+}
+
+func TestRegisterCertEntryE_WithInvalidGenerate(t *testing.T) {
+	// with path
+	_, err := RegisterCertEntryE(&BootCert{
+		Cert: []*BootCertE{
+			{
+				Name:        "ut-cert",
+				CertPemPath: "cert.pem",
+				KeyPemPath:  "key.pem",
+				Generate:    BootCertGenerate{Enabled: true},
+			},
+		},
+	}, WithAppCtx(NewAppContext()))
+	assert.Contains(t, err.Error(), "cert[0].generate")
+
+	// with invalid key type
+	_, err = RegisterCertEntryE(&BootCert{
+		Cert: []*BootCertE{
+			{
+				Name:     "ut-cert",
+				Generate: BootCertGenerate{Enabled: true, KeyType: "dsa"},
+			},
+		},
+	}, WithAppCtx(NewAppContext()))
+	assert.Contains(t, err.Error(), "dsa")
+}
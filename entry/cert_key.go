@@ -0,0 +1,117 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkentry
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"embed"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"github.com/youmark/pkcs8"
+	"software.sslmate.com/src/go-pkcs12"
+	"strings"
+)
+
+// resolveKeyPassword returns password of private key and PKCS#12 bundle.
+//
+// References like ${env:CERT_PASS} and ${file:/run/secrets/cert-pass} are resolved,
+// values of the form ENC(BASE64CIPHERTEXT) are decrypted with crypto entry named by cryptoEntry.
+func (entry *CertEntry) resolveKeyPassword() (string, error) {
+	entry.lock.RLock()
+	password, cryptoEntry, appCtx := entry.keyPassword, entry.cryptoEntry, entry.appCtx
+	entry.lock.RUnlock()
+
+	password, err := resolveSecretRefs(appCtx, password)
+	if err != nil {
+		return "", err
+	}
+
+	if !IsEncryptedValue(password) {
+		return password, nil
+	}
+
+	if len(cryptoEntry) < 1 {
+		return "", errors.New("cryptoEntry is required to decrypt keyPassword")
+	}
+
+	decrypt, err := decryptValueFunc(appCtx, cryptoEntry)
+	if err != nil {
+		return "", err
+	}
+
+	return decrypt(password)
+}
+
+// loadPKCS12 read PKCS#12 bundle, certificates in bundle other than leaf are appended into chain.
+func loadPKCS12(p12Path, password string, fs *embed.FS) (*tls.Certificate, error) {
+	data, err := readFileE(p12Path, fs)
+	if err != nil {
+		return nil, err
+	}
+
+	key, leaf, chain, err := pkcs12.DecodeChain(data, password)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode PKCS#12, path:%s, %v", p12Path, err)
+	}
+
+	keyDer, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	// verified with private key as PEM does
+	certPem := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: leaf.Raw})
+	for i := range chain {
+		certPem = append(certPem, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: chain[i].Raw})...)
+	}
+
+	return parseKeyPair(certPem, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDer}))
+}
+
+// decryptKeyPem decrypt encrypted PKCS#8 blocks with password, blocks which are not encrypted are kept as it is.
+//
+// Legacy encrypted PEM with Proc-Type header is insecure by design and not supported, error returned.
+func decryptKeyPem(data []byte, password string) ([]byte, error) {
+	res := make([]byte, 0, len(data))
+
+	for {
+		var block *pem.Block
+		if block, data = pem.Decode(data); block == nil {
+			break
+		}
+
+		switch {
+		case block.Type == "ENCRYPTED PRIVATE KEY":
+			if len(password) < 1 {
+				return nil, errors.New("private key is encrypted, keyPassword is required")
+			}
+
+			der, err := decryptPKCS8(block.Bytes, []byte(password))
+			if err != nil {
+				return nil, err
+			}
+			block = &pem.Block{Type: "PRIVATE KEY", Bytes: der}
+		case strings.Contains(block.Headers["Proc-Type"], "ENCRYPTED"):
+			return nil, errors.New("legacy encrypted PEM private key is not supported, convert it into encrypted PKCS#8 with openssl pkcs8 -topk8")
+		}
+
+		res = append(res, pem.EncodeToMemory(block)...)
+	}
+
+	return res, nil
+}
+
+// decryptPKCS8 decrypt DER of encrypted PKCS#8 private key, PBES1 is not supported.
+func decryptPKCS8(der, password []byte) ([]byte, error) {
+	key, _, err := pkcs8.ParsePrivateKey(der, password)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt private key, %v", err)
+	}
+
+	return x509.MarshalPKCS8PrivateKey(key)
+}
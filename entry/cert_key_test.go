@@ -0,0 +1,182 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkentry
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"github.com/stretchr/testify/assert"
+	"github.com/youmark/pkcs8"
+	"os"
+	"path/filepath"
+	"software.sslmate.com/src/go-pkcs12"
+	"testing"
+)
+
+func TestDecryptKeyPem(t *testing.T) {
+	certPem, keyPem := generateCerts(t)
+	block, _ := pem.Decode(keyPem)
+
+	// encrypted PKCS#8
+	encrypted := encryptPKCS8(t, keyPem, "ut-pass")
+	res, err := decryptKeyPem(encrypted, "ut-pass")
+	assert.Nil(t, err)
+	_, err = parseKeyPair(certPem, res)
+	assert.Nil(t, err)
+
+	_, err = decryptKeyPem(encrypted, "wrong-pass")
+	assert.NotNil(t, err)
+	_, err = decryptKeyPem(encrypted, "")
+	assert.Contains(t, err.Error(), "keyPassword")
+
+	// legacy encrypted PEM is not supported
+	legacy := &pem.Block{
+		Type: block.Type,
+		Headers: map[string]string{
+			"Proc-Type": "4,ENCRYPTED",
+			"DEK-Info":  "AES-256-CBC,00000000000000000000000000000000",
+		},
+		Bytes: block.Bytes,
+	}
+	_, err = decryptKeyPem(pem.EncodeToMemory(legacy), "ut-pass")
+	assert.Contains(t, err.Error(), "PKCS#8")
+
+	// not encrypted
+	res, err = decryptKeyPem(keyPem, "")
+	assert.Nil(t, err)
+	assert.Equal(t, keyPem, res)
+}
+
+func TestCertEntry_EncryptedKey(t *testing.T) {
+	certPem, keyPem := generateCerts(t)
+
+	dir := t.TempDir()
+	certPemPath := filepath.ToSlash(filepath.Join(dir, "cert.pem"))
+	keyPemPath := filepath.ToSlash(filepath.Join(dir, "key.pem"))
+	assert.Nil(t, os.WriteFile(certPemPath, certPem, os.ModePerm))
+	assert.Nil(t, os.WriteFile(keyPemPath, encryptPKCS8(t, keyPem, "ut-pass"), os.ModePerm))
+
+	// password from env
+	assert.Nil(t, os.Setenv("UT_CERT_PASS", "ut-pass"))
+	defer os.Unsetenv("UT_CERT_PASS")
+
+	entry := RegisterCertEntry(&BootCert{
+		Cert: []*BootCertE{
+			{
+				Name:        "ut-cert",
+				CertPemPath: certPemPath,
+				KeyPemPath:  keyPemPath,
+				KeyPassword: "${env:UT_CERT_PASS}",
+			},
+		},
+	}, WithAppCtx(NewAppContext()))[0]
+	assert.Nil(t, entry.BootstrapE(context.TODO()))
+	defer entry.Interrupt(context.TODO())
+	assert.NotNil(t, entry.Certificate)
+
+	// without password
+	entry = RegisterCertEntry(&BootCert{
+		Cert: []*BootCertE{
+			{
+				Name:        "ut-cert",
+				CertPemPath: certPemPath,
+				KeyPemPath:  keyPemPath,
+			},
+		},
+	}, WithAppCtx(NewAppContext()))[0]
+	assert.Contains(t, entry.BootstrapE(context.TODO()).Error(), "keyPassword")
+}
+
+func TestCertEntry_PKCS12(t *testing.T) {
+	certPem, keyPem := generateCerts(t)
+	cert, _ := parseKeyPair(certPem, keyPem)
+
+	appCtx := NewAppContext()
+	crypto, _ := NewCryptoAES("ut-crypto", []byte("0123456789abcdef"))
+	appCtx.AddEntry(crypto)
+	pass, _ := EncryptValue(crypto, "ut-pass")
+
+	pfx, err := pkcs12.Modern.Encode(cert.PrivateKey, cert.Leaf, []*x509.Certificate{cert.Leaf}, "ut-pass")
+	assert.Nil(t, err)
+	p12Path := filepath.ToSlash(filepath.Join(t.TempDir(), "cert.p12"))
+	assert.Nil(t, os.WriteFile(p12Path, pfx, os.ModePerm))
+
+	// password decrypted by crypto entry
+	raw := `
+cert:
+  - name: ut-cert
+    p12Path: ` + p12Path + `
+    keyPassword: ` + pass + `
+    cryptoEntry: ut-crypto
+`
+	entries, err := RegisterCertEntryYAMLE([]byte(raw), WithAppCtx(appCtx))
+	assert.Nil(t, err)
+	assert.Nil(t, ValidateBootYAML([]byte(raw)))
+	entry := entries["ut-cert"].(*CertEntry)
+	assert.Equal(t, []EntryRef{{Type: CryptoEntryType, Name: "ut-crypto"}}, entry.DependsOn())
+	assert.Nil(t, entry.BootstrapE(context.TODO()))
+	defer entry.Interrupt(context.TODO())
+
+	assert.Equal(t, cert.Leaf.Raw, entry.Certificate.Leaf.Raw)
+	assert.Len(t, entry.Certificate.Certificate, 2)
+	assert.NotContains(t, entry.String(), "ut-pass")
+	assert.Contains(t, entry.watchPaths(), filepath.Clean(p12Path))
+
+	// decrypted after reloaded
+	assert.Nil(t, entry.Reload(context.TODO(), []byte(raw)))
+	assert.Equal(t, cert.Leaf.Raw, entry.Certificate.Leaf.Raw)
+
+	// with wrong password
+	entry = RegisterCertEntry(&BootCert{
+		Cert: []*BootCertE{
+			{
+				Name:        "ut-cert",
+				P12Path:     p12Path,
+				KeyPassword: "wrong-pass",
+			},
+		},
+	}, WithAppCtx(NewAppContext()))[0]
+	assert.Contains(t, entry.BootstrapE(context.TODO()).Error(), "PKCS#12")
+
+	// encrypted password without crypto entry
+	entry = RegisterCertEntry(&BootCert{
+		Cert: []*BootCertE{
+			{
+				Name:        "ut-cert",
+				P12Path:     p12Path,
+				KeyPassword: pass,
+			},
+		},
+	}, WithAppCtx(NewAppContext()))[0]
+	assert.Contains(t, entry.BootstrapE(context.TODO()).Error(), "cryptoEntry")
+}
+
+func TestRegisterCertEntryE_WithInvalidPKCS12(t *testing.T) {
+	_, err := RegisterCertEntryE(&BootCert{
+		Cert: []*BootCertE{
+			{
+				Name:        "ut-cert",
+				P12Path:     "cert.p12",
+				CertPemPath: "cert.pem",
+				KeyPemPath:  "key.pem",
+			},
+		},
+	}, WithAppCtx(NewAppContext()))
+	assert.Contains(t, err.Error(), "cert[0].p12Path")
+}
+
+// encryptPKCS8 encrypt PKCS#1 private key in PEM into PKCS#8 with PBES2, PBKDF2 with hmacWithSHA256 and AES-256-CBC.
+func encryptPKCS8(t *testing.T, keyPem []byte, password string) []byte {
+	block, _ := pem.Decode(keyPem)
+	key, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+	assert.Nil(t, err)
+
+	der, err := pkcs8.MarshalPrivateKey(key, []byte(password), nil)
+	assert.Nil(t, err)
+
+	return pem.EncodeToMemory(&pem.Block{Type: "ENCRYPTED PRIVATE KEY", Bytes: der})
+}
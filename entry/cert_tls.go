@@ -0,0 +1,429 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkentry
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"embed"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	// ClientAuthNone does not request client certificate
+	ClientAuthNone = "none"
+	// ClientAuthRequest requests client certificate but does not require it
+	ClientAuthRequest = "request"
+	// ClientAuthRequire requires client certificate without verification
+	ClientAuthRequire = "require"
+	// ClientAuthVerifyIfGiven verifies client certificate if given
+	ClientAuthVerifyIfGiven = "verifyIfGiven"
+	// ClientAuthRequireAndVerify requires and verifies client certificate
+	ClientAuthRequireAndVerify = "requireAndVerify"
+
+	// defaultTLSMinVersion is min TLS version if missing
+	defaultTLSMinVersion = tls.VersionTLS12
+)
+
+var (
+	clientAuthTypes = map[string]tls.ClientAuthType{
+		"":                         tls.NoClientCert,
+		ClientAuthNone:             tls.NoClientCert,
+		ClientAuthRequest:          tls.RequestClientCert,
+		ClientAuthRequire:          tls.RequireAnyClientCert,
+		ClientAuthVerifyIfGiven:    tls.VerifyClientCertIfGiven,
+		ClientAuthRequireAndVerify: tls.RequireAndVerifyClientCert,
+	}
+
+	tlsVersions = map[string]uint16{
+		"1.0": tls.VersionTLS10,
+		"1.1": tls.VersionTLS11,
+		"1.2": tls.VersionTLS12,
+		"1.3": tls.VersionTLS13,
+	}
+)
+
+// BootCertPair is certificate and private key selected by SNI.
+type BootCertPair struct {
+	CertPemPath string `yaml:"certPemPath" json:"certPemPath"`
+	KeyPemPath  string `yaml:"keyPemPath" json:"keyPemPath"`
+}
+
+// tlsPolicy is TLS policy of CertEntry parsed from BootCertE.
+type tlsPolicy struct {
+	clientAuth   tls.ClientAuthType
+	minVersion   uint16
+	cipherSuites []uint16
+}
+
+// newTLSPolicy parse client auth, min TLS version and cipher suites.
+//
+// Cipher suites are names of tls.CipherSuites(), insecure ones are rejected.
+func newTLSPolicy(clientAuth, minVersion string, cipherSuites []string) (*tlsPolicy, error) {
+	res := &tlsPolicy{
+		minVersion: defaultTLSMinVersion,
+	}
+
+	v, ok := clientAuthTypes[clientAuth]
+	if !ok {
+		return nil, fmt.Errorf("invalid clientAuth %s, expect one of none, request, require, verifyIfGiven and requireAndVerify", clientAuth)
+	}
+	res.clientAuth = v
+
+	if len(minVersion) > 0 {
+		if res.minVersion, ok = tlsVersions[minVersion]; !ok {
+			return nil, fmt.Errorf("invalid minVersion %s, expect one of 1.0, 1.1, 1.2 and 1.3", minVersion)
+		}
+	}
+
+	supported := map[string]uint16{}
+	for _, suite := range tls.CipherSuites() {
+		supported[suite.Name] = suite.ID
+	}
+
+	for _, name := range cipherSuites {
+		id, ok := supported[name]
+		if !ok {
+			return nil, fmt.Errorf("invalid or insecure cipher suite %s", name)
+		}
+		res.cipherSuites = append(res.cipherSuites, id)
+	}
+
+	return res, nil
+}
+
+// certBundle is certificates and CA pools loaded by CertEntry.
+type certBundle struct {
+	// certs are certificates selected by SNI, first one is the default
+	certs []*tls.Certificate
+	// clientCert is certificate presented to servers, the default one is used if missing
+	clientCert    *tls.Certificate
+	caCerts       []*x509.Certificate
+	clientCACerts []*x509.Certificate
+}
+
+// certificate returns default certificate, nil returned if missing.
+func (b *certBundle) certificate() *tls.Certificate {
+	if b == nil || len(b.certs) < 1 {
+		return nil
+	}
+
+	return b.certs[0]
+}
+
+// rootCA returns first CA, nil returned if missing.
+func (b *certBundle) rootCA() *x509.Certificate {
+	if b == nil || len(b.caCerts) < 1 {
+		return nil
+	}
+
+	return b.caCerts[0]
+}
+
+// certs returns certificates selected by SNI, Certificate is returned if bundle is missing.
+//
+// Caller should hold lock.
+func (entry *CertEntry) certs() []*tls.Certificate {
+	if entry.bundle != nil && len(entry.bundle.certs) > 0 {
+		return entry.bundle.certs
+	}
+
+	if entry.Certificate != nil {
+		return []*tls.Certificate{entry.Certificate}
+	}
+
+	return nil
+}
+
+// caCerts returns certificates in CA bundle, RootCA is returned if bundle is missing.
+//
+// Caller should hold lock.
+func (entry *CertEntry) caCerts() []*x509.Certificate {
+	if entry.bundle != nil && len(entry.bundle.caCerts) > 0 {
+		return entry.bundle.caCerts
+	}
+
+	if entry.RootCA != nil {
+		return []*x509.Certificate{entry.RootCA}
+	}
+
+	return nil
+}
+
+// clientCACerts returns certificates in client CA bundle, CA bundle is returned if client CA is missing.
+//
+// Caller should hold lock.
+func (entry *CertEntry) clientCACerts() []*x509.Certificate {
+	if entry.bundle != nil && len(entry.bundle.clientCACerts) > 0 {
+		return entry.bundle.clientCACerts
+	}
+
+	return entry.caCerts()
+}
+
+// policy returns TLS policy, default one is returned if missing.
+func (entry *CertEntry) policy() *tlsPolicy {
+	if entry.tlsPolicy != nil {
+		return entry.tlsPolicy
+	}
+
+	return &tlsPolicy{minVersion: defaultTLSMinVersion}
+}
+
+// newCertPool returns pool with certificates, nil returned if empty.
+func newCertPool(certs []*x509.Certificate) *x509.CertPool {
+	if len(certs) < 1 {
+		return nil
+	}
+
+	pool := x509.NewCertPool()
+	for i := range certs {
+		pool.AddCert(certs[i])
+	}
+
+	return pool
+}
+
+// loadKeyPair read certificate and private key, certificate is verified with private key and parsed into Leaf.
+//
+// Encrypted private key is decrypted with password.
+func loadKeyPair(certPemPath, keyPemPath, password string, fs *embed.FS) (*tls.Certificate, error) {
+	certPem, err := readFileE(certPemPath, fs)
+	if err != nil {
+		return nil, err
+	}
+
+	keyPem, err := readFileE(keyPemPath, fs)
+	if err != nil {
+		return nil, err
+	}
+
+	if keyPem, err = decryptKeyPem(keyPem, password); err != nil {
+		return nil, fmt.Errorf("%v, path:%s", err, keyPemPath)
+	}
+
+	return parseKeyPair(certPem, keyPem)
+}
+
+// parseKeyPair parse PEM of certificate and private key, certificate is parsed into Leaf.
+func parseKeyPair(certPem, keyPem []byte) (*tls.Certificate, error) {
+	cert, err := tls.X509KeyPair(certPem, keyPem)
+	if err != nil {
+		return nil, err
+	}
+
+	if cert.Leaf, err = x509.ParseCertificate(cert.Certificate[0]); err != nil {
+		return nil, err
+	}
+
+	return &cert, nil
+}
+
+// readCACerts read CA bundle from file or every file in directory, error returned if no certificate found.
+func readCACerts(caPath string, fs *embed.FS) ([]*x509.Certificate, error) {
+	files, err := readBundleFiles(caPath, fs)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]*x509.Certificate, 0)
+	for i := range files {
+		certs, err := parseCertsPem(files[i])
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse CA, path:%s, %v", caPath, err)
+		}
+		res = append(res, certs...)
+	}
+
+	if len(res) < 1 {
+		return nil, fmt.Errorf("certificate not found in CA, path:%s", caPath)
+	}
+
+	return res, nil
+}
+
+// readBundleFiles returns content of file, or content of files in directory sorted by name.
+func readBundleFiles(p string, fs *embed.FS) ([][]byte, error) {
+	names := make([]string, 0)
+
+	if fs != nil {
+		entries, err := fs.ReadDir(p)
+		if err != nil {
+			bytes, err := fs.ReadFile(p)
+			return [][]byte{bytes}, err
+		}
+
+		for i := range entries {
+			if !entries[i].IsDir() {
+				names = append(names, path.Join(p, entries[i].Name()))
+			}
+		}
+	} else {
+		abs, err := absConfigPath(p)
+		if err != nil {
+			return nil, err
+		}
+
+		info, err := os.Stat(abs)
+		if err != nil {
+			return nil, err
+		}
+
+		if !info.IsDir() {
+			bytes, err := os.ReadFile(abs)
+			return [][]byte{bytes}, err
+		}
+
+		entries, err := os.ReadDir(abs)
+		if err != nil {
+			return nil, err
+		}
+
+		for i := range entries {
+			// files in directory of Kubernetes Secret are symlinks, and ..data is a directory
+			if !entries[i].IsDir() && !strings.HasPrefix(entries[i].Name(), ".") {
+				names = append(names, filepath.Join(abs, entries[i].Name()))
+			}
+		}
+	}
+
+	sort.Strings(names)
+
+	res := make([][]byte, 0, len(names))
+	for i := range names {
+		bytes, err := readFileE(names[i], fs)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, bytes)
+	}
+
+	return res, nil
+}
+
+// parseCertsPem parse every certificate in PEM, blocks of other types are skipped.
+func parseCertsPem(data []byte) ([]*x509.Certificate, error) {
+	res := make([]*x509.Certificate, 0)
+
+	for {
+		var block *pem.Block
+		if block, data = pem.Decode(data); block == nil {
+			break
+		}
+
+		if block.Type != "CERTIFICATE" || len(block.Headers) != 0 {
+			continue
+		}
+
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, cert)
+	}
+
+	return res, nil
+}
+
+// selectCertificate returns first certificate matches server name and supported by client,
+// the first one is returned if none matched.
+func selectCertificate(certs []*tls.Certificate, hello *tls.ClientHelloInfo) *tls.Certificate {
+	if len(certs) < 1 {
+		return nil
+	}
+
+	if hello != nil && len(certs) > 1 {
+		for _, cert := range certs {
+			if len(hello.ServerName) > 0 && cert.Leaf != nil && cert.Leaf.VerifyHostname(hello.ServerName) != nil {
+				continue
+			}
+
+			// key type and signature schemes are checked in handshake only
+			if len(hello.SupportedVersions) > 0 && hello.SupportsCertificate(cert) != nil {
+				continue
+			}
+
+			return cert
+		}
+	}
+
+	return certs[0]
+}
+
+// hasCertOrCA returns true if certificate or CA bundle is loaded.
+func (entry *CertEntry) hasCertOrCA() bool {
+	entry.lock.RLock()
+	defer entry.lock.RUnlock()
+
+	return len(entry.certs()) > 0 || len(entry.caCerts()) > 0
+}
+
+// GetCAPool returns pool of CA bundle, nil returned if missing.
+func (entry *CertEntry) GetCAPool() *x509.CertPool {
+	entry.lock.RLock()
+	defer entry.lock.RUnlock()
+
+	return newCertPool(entry.caCerts())
+}
+
+// GetClientCAPool returns pool of client CA bundle, pool of CA bundle is returned if client CA is missing.
+func (entry *CertEntry) GetClientCAPool() *x509.CertPool {
+	entry.lock.RLock()
+	defer entry.lock.RUnlock()
+
+	return newCertPool(entry.clientCACerts())
+}
+
+// ServerTLSConfig returns tls.Config for server with client auth, min TLS version and cipher suites.
+//
+// Certificate is selected by SNI on each handshake, and client CA pool is looked up on each handshake,
+// so that reloaded ones are used without restarting listener.
+func (entry *CertEntry) ServerTLSConfig() *tls.Config {
+	conf := entry.serverTLSConfig()
+	conf.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+		return entry.serverTLSConfig(), nil
+	}
+
+	return conf
+}
+
+// serverTLSConfig returns tls.Config for server with current client CA pool.
+func (entry *CertEntry) serverTLSConfig() *tls.Config {
+	entry.lock.RLock()
+	defer entry.lock.RUnlock()
+
+	policy := entry.policy()
+	return &tls.Config{
+		GetCertificate: entry.GetCertificate,
+		ClientAuth:     policy.clientAuth,
+		ClientCAs:      newCertPool(entry.clientCACerts()),
+		MinVersion:     policy.minVersion,
+		CipherSuites:   policy.cipherSuites,
+	}
+}
+
+// ClientTLSConfig returns tls.Config for client with min TLS version and cipher suites.
+//
+// Client certificate is looked up on each handshake, RootCAs is pool of CA bundle at the time of calling,
+// system pool is used if CA is missing.
+func (entry *CertEntry) ClientTLSConfig() *tls.Config {
+	entry.lock.RLock()
+	defer entry.lock.RUnlock()
+
+	policy := entry.policy()
+	return &tls.Config{
+		GetClientCertificate: entry.GetClientCertificate,
+		RootCAs:              newCertPool(entry.caCerts()),
+		MinVersion:           policy.minVersion,
+		CipherSuites:         policy.cipherSuites,
+	}
+}
@@ -0,0 +1,177 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkentry
+
+import (
+	"context"
+	"crypto/tls"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestNewTLSPolicy(t *testing.T) {
+	// default
+	policy, err := newTLSPolicy("", "", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, tls.NoClientCert, policy.clientAuth)
+	assert.Equal(t, uint16(tls.VersionTLS12), policy.minVersion)
+	assert.Empty(t, policy.cipherSuites)
+
+	policy, err = newTLSPolicy(ClientAuthRequireAndVerify, "1.3", []string{"TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256"})
+	assert.Nil(t, err)
+	assert.Equal(t, tls.RequireAndVerifyClientCert, policy.clientAuth)
+	assert.Equal(t, uint16(tls.VersionTLS13), policy.minVersion)
+	assert.Equal(t, []uint16{tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256}, policy.cipherSuites)
+
+	// invalid
+	_, err = newTLSPolicy("always", "", nil)
+	assert.Contains(t, err.Error(), "always")
+	_, err = newTLSPolicy("", "2.0", nil)
+	assert.Contains(t, err.Error(), "2.0")
+	_, err = newTLSPolicy("", "", []string{"TLS_RSA_WITH_RC4_128_SHA"})
+	assert.Contains(t, err.Error(), "TLS_RSA_WITH_RC4_128_SHA")
+}
+
+func TestCertEntry_CABundle(t *testing.T) {
+	caA, _ := generateCerts(t)
+	caB, _ := generateCerts(t)
+
+	// bundle file
+	dir := t.TempDir()
+	bundlePath := filepath.ToSlash(filepath.Join(dir, "bundle.pem"))
+	assert.Nil(t, os.WriteFile(bundlePath, append(append([]byte{}, caA...), caB...), os.ModePerm))
+
+	// bundle directory
+	caDir := filepath.ToSlash(filepath.Join(dir, "ca"))
+	assert.Nil(t, os.MkdirAll(caDir, os.ModePerm))
+	assert.Nil(t, os.WriteFile(filepath.Join(caDir, "a.pem"), caA, os.ModePerm))
+	assert.Nil(t, os.WriteFile(filepath.Join(caDir, "b.pem"), caB, os.ModePerm))
+
+	entry := RegisterCertEntry(&BootCert{
+		Cert: []*BootCertE{
+			{
+				Name:         "ut-cert",
+				CAPath:       bundlePath,
+				ClientCAPath: caDir,
+			},
+		},
+	}, WithAppCtx(NewAppContext()))[0]
+	assert.Nil(t, entry.BootstrapE(context.TODO()))
+
+	assert.Len(t, entry.bundle.caCerts, 2)
+	assert.Len(t, entry.bundle.clientCACerts, 2)
+	assert.Equal(t, entry.bundle.caCerts[0], entry.GetRootCA())
+	assert.NotNil(t, entry.GetCAPool())
+	assert.NotNil(t, entry.GetClientCAPool())
+	assert.Len(t, entry.ListCertInfos(), 4)
+
+	// without certificate
+	emptyPath := filepath.ToSlash(filepath.Join(dir, "empty.pem"))
+	assert.Nil(t, os.WriteFile(emptyPath, []byte("empty"), os.ModePerm))
+	entry = RegisterCertEntry(&BootCert{
+		Cert: []*BootCertE{
+			{
+				Name:   "ut-cert",
+				CAPath: emptyPath,
+			},
+		},
+	}, WithAppCtx(NewAppContext()))[0]
+	assert.NotNil(t, entry.BootstrapE(context.TODO()))
+}
+
+func TestCertEntry_ServerTLSConfig(t *testing.T) {
+	// certificates of a.local and b.local signed by the same local CA
+	dirA, dirB := t.TempDir(), t.TempDir()
+	_, err := (&certGenerator{config: &BootCertGenerate{CommonName: "a.local", LocalCA: true, Dir: dirA}, now: time.Now()}).generate()
+	assert.Nil(t, err)
+	for _, name := range []string{generatedCAFile, generatedCAKeyFile} {
+		bytes, _ := os.ReadFile(filepath.Join(dirA, name))
+		assert.Nil(t, os.WriteFile(filepath.Join(dirB, name), bytes, os.ModePerm))
+	}
+	_, err = (&certGenerator{config: &BootCertGenerate{CommonName: "b.local", LocalCA: true, Dir: dirB}, now: time.Now()}).generate()
+	assert.Nil(t, err)
+
+	raw := `
+cert:
+  - name: ut-cert
+    caPath: ` + filepath.ToSlash(filepath.Join(dirA, generatedCAFile)) + `
+    certPemPath: ` + filepath.ToSlash(filepath.Join(dirA, generatedCertFile)) + `
+    keyPemPath: ` + filepath.ToSlash(filepath.Join(dirA, generatedCertKeyFile)) + `
+    certs:
+      - certPemPath: ` + filepath.ToSlash(filepath.Join(dirB, generatedCertFile)) + `
+        keyPemPath: ` + filepath.ToSlash(filepath.Join(dirB, generatedCertKeyFile)) + `
+    clientAuth: requireAndVerify
+    minVersion: "1.2"
+`
+	entries, err := RegisterCertEntryYAMLE([]byte(raw), WithAppCtx(NewAppContext()))
+	assert.Nil(t, err)
+	assert.Nil(t, ValidateBootYAML([]byte(raw)))
+	entry := entries["ut-cert"].(*CertEntry)
+	assert.Nil(t, entry.BootstrapE(context.TODO()))
+
+	// selected by SNI
+	cert, err := entry.GetCertificate(&tls.ClientHelloInfo{ServerName: "b.local"})
+	assert.Nil(t, err)
+	assert.Equal(t, "b.local", cert.Leaf.Subject.CommonName)
+	cert, err = entry.GetCertificate(&tls.ClientHelloInfo{ServerName: "unknown.local"})
+	assert.Nil(t, err)
+	assert.Equal(t, "a.local", cert.Leaf.Subject.CommonName)
+
+	serverConf := entry.ServerTLSConfig()
+	assert.Equal(t, tls.RequireAndVerifyClientCert, serverConf.ClientAuth)
+	assert.Equal(t, uint16(tls.VersionTLS12), serverConf.MinVersion)
+
+	// mutual TLS
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	server.TLS = serverConf
+	server.StartTLS()
+	defer server.Close()
+
+	clientConf := entry.ClientTLSConfig()
+	clientConf.ServerName = "b.local"
+	conn, err := tls.Dial("tcp", server.Listener.Addr().String(), clientConf)
+	assert.Nil(t, err)
+	assert.Nil(t, conn.Handshake())
+	assert.Equal(t, "b.local", conn.ConnectionState().PeerCertificates[0].Subject.CommonName)
+	conn.Close()
+
+	// client without certificate is rejected
+	clientConf = entry.ClientTLSConfig()
+	clientConf.ServerName = "a.local"
+	clientConf.GetClientCertificate = nil
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: clientConf}}
+	_, err = client.Get(server.URL)
+	assert.NotNil(t, err)
+}
+
+func TestRegisterCertEntryE_WithInvalidTLSPolicy(t *testing.T) {
+	_, err := RegisterCertEntryE(&BootCert{
+		Cert: []*BootCertE{
+			{
+				Name:       "ut-cert",
+				ClientAuth: "always",
+			},
+		},
+	}, WithAppCtx(NewAppContext()))
+	assert.Contains(t, err.Error(), "cert[0]")
+
+	_, err = RegisterCertEntryE(&BootCert{
+		Cert: []*BootCertE{
+			{
+				Name:  "ut-cert",
+				Certs: []*BootCertPair{{CertPemPath: "cert.pem"}},
+			},
+		},
+	}, WithAppCtx(NewAppContext()))
+	assert.Contains(t, err.Error(), "cert[0].certs[0]")
+}
@@ -37,20 +37,29 @@ var swAssetsFile []byte
 
 // BootCommonService Bootstrap config of common service.
 type BootCommonService struct {
-	Enabled    bool   `yaml:"enabled" json:"enabled"`
-	PathPrefix string `yaml:"pathPrefix" json:"pathPrefix"`
+	Enabled         bool   `yaml:"enabled" json:"enabled"`
+	PathPrefix      string `yaml:"pathPrefix" json:"pathPrefix"`
+	EffectiveConfig bool   `yaml:"effectiveConfig" json:"effectiveConfig"`
+	// Config exposes current values and change history of config entries, values of secrets are masked
+	Config bool `yaml:"config" json:"config"`
 }
 
 // CommonServiceEntry RK common service which contains commonly used APIs
 type CommonServiceEntry struct {
-	entryName        string `json:"-" yaml:"-"`
-	entryType        string `json:"-" yaml:"-"`
-	entryDescription string `json:"-" yaml:"-"`
-	pathPrefix       string `json:"-" yaml:"-"`
-	ReadyPath        string `json:"-" yaml:"-"`
-	AlivePath        string `json:"-" yaml:"-"`
-	GcPath           string `json:"-" yaml:"-"`
-	InfoPath         string `json:"-" yaml:"-"`
+	entryName         string      `json:"-" yaml:"-"`
+	entryType         string      `json:"-" yaml:"-"`
+	entryDescription  string      `json:"-" yaml:"-"`
+	pathPrefix        string      `json:"-" yaml:"-"`
+	ReadyPath         string      `json:"-" yaml:"-"`
+	AlivePath         string      `json:"-" yaml:"-"`
+	GcPath            string      `json:"-" yaml:"-"`
+	InfoPath          string      `json:"-" yaml:"-"`
+	StatusPath        string      `json:"-" yaml:"-"`
+	ConfigPath        string      `json:"-" yaml:"-"`
+	FeatureFlagPath   string      `json:"-" yaml:"-"`
+	ConfigEntryPath   string      `json:"-" yaml:"-"`
+	ConfigHistoryPath string      `json:"-" yaml:"-"`
+	appCtx            *AppContext `json:"-" yaml:"-"`
 }
 
 // CommonServiceEntryOption option for CommonServiceEntry
@@ -63,6 +72,15 @@ func WithNameCommonServiceEntry(name string) CommonServiceEntryOption {
 	}
 }
 
+// WithAppCtxCommonServiceEntry provide AppContext which readiness and liveness check would be read from
+func WithAppCtxCommonServiceEntry(ctx *AppContext) CommonServiceEntryOption {
+	return func(entry *CommonServiceEntry) {
+		if ctx != nil {
+			entry.appCtx = ctx
+		}
+	}
+}
+
 // RegisterCommonServiceEntry Create new common service entry with options.
 func RegisterCommonServiceEntry(boot *BootCommonService, opts ...CommonServiceEntryOption) *CommonServiceEntry {
 	if boot.Enabled {
@@ -74,7 +92,10 @@ func RegisterCommonServiceEntry(boot *BootCommonService, opts ...CommonServiceEn
 			AlivePath:        "alive",
 			GcPath:           "gc",
 			InfoPath:         "info",
+			StatusPath:       "status",
+			FeatureFlagPath:  "featureFlags",
 			pathPrefix:       boot.PathPrefix,
+			appCtx:           GlobalAppCtx,
 		}
 
 		for i := range opts {
@@ -90,6 +111,19 @@ func RegisterCommonServiceEntry(boot *BootCommonService, opts ...CommonServiceEn
 		entry.AlivePath = path.Join("/", entry.pathPrefix, entry.AlivePath)
 		entry.GcPath = path.Join("/", entry.pathPrefix, entry.GcPath)
 		entry.InfoPath = path.Join("/", entry.pathPrefix, entry.InfoPath)
+		entry.StatusPath = path.Join("/", entry.pathPrefix, entry.StatusPath)
+		entry.FeatureFlagPath = path.Join("/", entry.pathPrefix, entry.FeatureFlagPath)
+
+		// effective config is exposed only if enabled explicitly
+		if boot.EffectiveConfig {
+			entry.ConfigPath = path.Join("/", entry.pathPrefix, "effectiveConfig")
+		}
+
+		// values of config entries are exposed only if enabled explicitly
+		if boot.Config {
+			entry.ConfigEntryPath = path.Join("/", entry.pathPrefix, "config")
+			entry.ConfigHistoryPath = path.Join("/", entry.pathPrefix, "config", "history")
+		}
 
 		// change swagger config file
 		oldSwAssets := readFile("assets/sw/config/swagger.json", &rkembed.AssetsFS, true)
@@ -127,6 +161,37 @@ func RegisterCommonServiceEntry(boot *BootCommonService, opts ...CommonServiceEn
 						inner[entry.InfoPath] = v
 						delete(inner, p)
 					}
+				case "/rk/v1/status":
+					if p != entry.StatusPath {
+						inner[entry.StatusPath] = v
+						delete(inner, p)
+					}
+				case "/rk/v1/featureFlags":
+					if p != entry.FeatureFlagPath {
+						inner[entry.FeatureFlagPath] = v
+						delete(inner, p)
+					}
+				case "/rk/v1/config":
+					if p != entry.ConfigEntryPath {
+						if len(entry.ConfigEntryPath) > 0 {
+							inner[entry.ConfigEntryPath] = v
+						}
+						delete(inner, p)
+					}
+				case "/rk/v1/config/history":
+					if p != entry.ConfigHistoryPath {
+						if len(entry.ConfigHistoryPath) > 0 {
+							inner[entry.ConfigHistoryPath] = v
+						}
+						delete(inner, p)
+					}
+				case "/rk/v1/effectiveConfig":
+					if p != entry.ConfigPath {
+						if len(entry.ConfigPath) > 0 {
+							inner[entry.ConfigPath] = v
+						}
+						delete(inner, p)
+					}
 				}
 			}
 		}
@@ -173,13 +238,18 @@ func (entry *CommonServiceEntry) String() string {
 // MarshalJSON Marshal entry.
 func (entry *CommonServiceEntry) MarshalJSON() ([]byte, error) {
 	m := map[string]interface{}{
-		"name":        entry.GetName(),
-		"type":        entry.GetType(),
-		"description": entry.GetDescription(),
-		"readyPath":   entry.ReadyPath,
-		"alivePath":   entry.AlivePath,
-		"gcPath":      entry.GcPath,
-		"infoPath":    entry.InfoPath,
+		"name":              entry.GetName(),
+		"type":              entry.GetType(),
+		"description":       entry.GetDescription(),
+		"readyPath":         entry.ReadyPath,
+		"alivePath":         entry.AlivePath,
+		"gcPath":            entry.GcPath,
+		"infoPath":          entry.InfoPath,
+		"statusPath":        entry.StatusPath,
+		"configPath":        entry.ConfigPath,
+		"featureFlagPath":   entry.FeatureFlagPath,
+		"configEntryPath":   entry.ConfigEntryPath,
+		"configHistoryPath": entry.ConfigHistoryPath,
 	}
 
 	return json.Marshal(m)
@@ -200,9 +270,32 @@ func (entry *CommonServiceEntry) UnmarshalJSON([]byte) error {
 // @produce application/json
 // @Success 200 {object} readyResp
 // @Failure 500 {object} rkerror.ErrorInterface
+// @Failure 503 {object} readyResp
 // @Router /rk/v1/ready [get]
 func (entry *CommonServiceEntry) Ready(writer http.ResponseWriter, request *http.Request) {
-	if GlobalAppCtx.readinessCheck != nil && !GlobalAppCtx.readinessCheck(request, writer) {
+	// all required entries bootstrapped through AppContext should be running
+	if ready, notReady := entry.appCtx.IsEntriesReady(); !ready {
+		writer.WriteHeader(http.StatusServiceUnavailable)
+		bytes, _ := json.MarshalIndent(&readyResp{
+			Ready:   false,
+			Entries: notReady,
+		}, "", "  ")
+		writer.Write(bytes)
+		return
+	}
+
+	// certificates of CertEntry with expiry.failReady should not be expired
+	if expired := entry.appCtx.ListExpiredCerts(); len(expired) > 0 {
+		writer.WriteHeader(http.StatusServiceUnavailable)
+		bytes, _ := json.MarshalIndent(&readyResp{
+			Ready: false,
+			Certs: expired,
+		}, "", "  ")
+		writer.Write(bytes)
+		return
+	}
+
+	if entry.appCtx.readinessCheck != nil && !entry.appCtx.readinessCheck(request, writer) {
 		return
 	}
 
@@ -224,7 +317,7 @@ func (entry *CommonServiceEntry) Ready(writer http.ResponseWriter, request *http
 // @Success 200 {object} aliveResp
 // @Router /rk/v1/alive [get]
 func (entry *CommonServiceEntry) Alive(writer http.ResponseWriter, request *http.Request) {
-	if GlobalAppCtx.livenessCheck != nil && !GlobalAppCtx.livenessCheck(request, writer) {
+	if entry.appCtx.livenessCheck != nil && !entry.appCtx.livenessCheck(request, writer) {
 		return
 	}
 
@@ -270,6 +363,150 @@ func (entry *CommonServiceEntry) Gc(writer http.ResponseWriter, request *http.Re
 // @Router /rk/v1/info [get]
 func (entry *CommonServiceEntry) Info(writer http.ResponseWriter, request *http.Request) {
 	writer.WriteHeader(http.StatusOK)
-	bytes, _ := json.MarshalIndent(NewProcessInfo(), "", "  ")
+	bytes, _ := json.MarshalIndent(newProcessInfo(entry.appCtx), "", "  ")
+	writer.Write(bytes)
+}
+
+// Status handler
+// @Summary Get lifecycle status of entries
+// @Id 8005
+// @version 1.0
+// @Security ApiKeyAuth
+// @Security BasicAuth
+// @Security JWT
+// @produce application/json
+// @Success 200 {object} statusResp
+// @Router /rk/v1/status [get]
+func (entry *CommonServiceEntry) Status(writer http.ResponseWriter, request *http.Request) {
+	ready, _ := entry.appCtx.IsEntriesReady()
+
+	writer.WriteHeader(http.StatusOK)
+	bytes, _ := json.MarshalIndent(&statusResp{
+		Ready:   ready,
+		Entries: entry.appCtx.ListEntryStatuses(),
+	}, "", "  ")
+	writer.Write(bytes)
+}
+
+// EffectiveConfig handler
+// @Summary Get effective boot config with source of each value
+// @Id 8006
+// @version 1.0
+// @Security ApiKeyAuth
+// @Security BasicAuth
+// @Security JWT
+// @produce application/json
+// @Param path query string false "Path prefix of values, like gin[0]"
+// @Success 200 {object} effectiveConfigResp
+// @Failure 404 {object} effectiveConfigResp
+// @Router /rk/v1/effectiveConfig [get]
+func (entry *CommonServiceEntry) EffectiveConfig(writer http.ResponseWriter, request *http.Request) {
+	// not enabled in boot config
+	if len(entry.ConfigPath) < 1 {
+		writer.WriteHeader(http.StatusNotFound)
+		bytes, _ := json.MarshalIndent(&effectiveConfigResp{
+			Values: []*EffectiveValue{},
+		}, "", "  ")
+		writer.Write(bytes)
+		return
+	}
+
+	prefix := ""
+	if request != nil {
+		prefix = request.URL.Query().Get("path")
+	}
+
+	writer.WriteHeader(http.StatusOK)
+	bytes, _ := json.MarshalIndent(&effectiveConfigResp{
+		Values: entry.appCtx.GetEffectiveConfig().List(prefix),
+	}, "", "  ")
+	writer.Write(bytes)
+}
+
+// FeatureFlags handler
+// @Summary Get flags and evaluation counts of feature flag entries
+// @Id 8007
+// @version 1.0
+// @Security ApiKeyAuth
+// @Security BasicAuth
+// @Security JWT
+// @produce application/json
+// @Success 200 {object} featureFlagsResp
+// @Router /rk/v1/featureFlags [get]
+func (entry *CommonServiceEntry) FeatureFlags(writer http.ResponseWriter, request *http.Request) {
+	entries := make([]*FeatureFlagState, 0)
+	for _, v := range ListEntriesAs[*FeatureFlagEntry](entry.appCtx) {
+		entries = append(entries, v.GetState())
+	}
+
+	writer.WriteHeader(http.StatusOK)
+	bytes, _ := json.MarshalIndent(&featureFlagsResp{
+		Entries: entries,
+	}, "", "  ")
+	writer.Write(bytes)
+}
+
+// Config handler
+// @Summary Get current values of config entries with source of each value
+// @Id 8008
+// @version 1.0
+// @Security ApiKeyAuth
+// @Security BasicAuth
+// @Security JWT
+// @produce application/json
+// @Success 200 {object} configResp
+// @Failure 404 {object} configResp
+// @Router /rk/v1/config [get]
+func (entry *CommonServiceEntry) Config(writer http.ResponseWriter, request *http.Request) {
+	resp := &configResp{
+		Entries: make([]*configEntryValues, 0),
+	}
+
+	// not enabled in boot config
+	if len(entry.ConfigEntryPath) < 1 {
+		writer.WriteHeader(http.StatusNotFound)
+		bytes, _ := json.MarshalIndent(resp, "", "  ")
+		writer.Write(bytes)
+		return
+	}
+
+	for _, v := range ListEntriesAs[*ConfigEntry](entry.appCtx) {
+		resp.Entries = append(resp.Entries, &configEntryValues{
+			EntryName: v.GetName(),
+			Values:    v.EffectiveValues(),
+		})
+	}
+
+	writer.WriteHeader(http.StatusOK)
+	bytes, _ := json.MarshalIndent(resp, "", "  ")
+	writer.Write(bytes)
+}
+
+// ConfigHistory handler
+// @Summary Get changes of config entries at runtime
+// @Id 8009
+// @version 1.0
+// @Security ApiKeyAuth
+// @Security BasicAuth
+// @Security JWT
+// @produce application/json
+// @Success 200 {object} configHistoryResp
+// @Failure 404 {object} configHistoryResp
+// @Router /rk/v1/config/history [get]
+func (entry *CommonServiceEntry) ConfigHistory(writer http.ResponseWriter, request *http.Request) {
+	// not enabled in boot config
+	if len(entry.ConfigHistoryPath) < 1 {
+		writer.WriteHeader(http.StatusNotFound)
+		bytes, _ := json.MarshalIndent(&configHistoryResp{
+			Changes: []*ConfigChange{},
+		}, "", "  ")
+		writer.Write(bytes)
+		return
+	}
+
+	writer.WriteHeader(http.StatusOK)
+	bytes, _ := json.MarshalIndent(&configHistoryResp{
+		Changes: entry.appCtx.ListConfigChanges(),
+	}, "", "  ")
 	writer.Write(bytes)
 }
@@ -7,8 +7,10 @@ package rkentry
 
 import (
 	"context"
+	"errors"
 	"github.com/stretchr/testify/assert"
 	"net/http/httptest"
+	"strings"
 	"testing"
 )
 
@@ -62,6 +64,51 @@ func TestCommonServiceEntry_Healthy(t *testing.T) {
 	assert.Contains(t, writer.Body.String(), "true")
 }
 
+func TestCommonServiceEntry_ReadyWithEntries(t *testing.T) {
+	appCtx := NewAppContext()
+	entry := RegisterCommonServiceEntry(&BootCommonService{
+		Enabled: true,
+	}, WithAppCtxCommonServiceEntry(appCtx))
+
+	mock := &EntryMock{Name: "ut-entry"}
+	appCtx.AddEntry(mock)
+
+	// registered entry is not considered
+	writer := httptest.NewRecorder()
+	entry.Ready(writer, nil)
+	assert.Equal(t, 200, writer.Code)
+
+	// with failed entry
+	assert.Nil(t, appCtx.SetEntryState(mock, EntryStateFailed, errors.New("ut-error")))
+	writer = httptest.NewRecorder()
+	entry.Ready(writer, nil)
+	assert.Equal(t, 503, writer.Code)
+	assert.Contains(t, writer.Body.String(), "ut-error")
+
+	// with optional entry
+	appCtx.SetEntryRequired(mock.GetType(), mock.GetName(), false)
+	writer = httptest.NewRecorder()
+	entry.Ready(writer, nil)
+	assert.Equal(t, 200, writer.Code)
+}
+
+func TestCommonServiceEntry_Status(t *testing.T) {
+	appCtx := NewAppContext()
+	entry := RegisterCommonServiceEntry(&BootCommonService{
+		Enabled: true,
+	}, WithAppCtxCommonServiceEntry(appCtx))
+	assert.Equal(t, "/rk/v1/status", entry.StatusPath)
+
+	appCtx.AddEntry(&EntryMock{Name: "ut-entry"})
+	assert.Nil(t, appCtx.BootstrapEntries(context.Background(), []Entry{appCtx.GetEntry("mock", "ut-entry")}))
+
+	writer := httptest.NewRecorder()
+	entry.Status(writer, nil)
+	assert.Equal(t, 200, writer.Code)
+	assert.Contains(t, writer.Body.String(), `"entryName": "ut-entry"`)
+	assert.Contains(t, writer.Body.String(), `"state": "Running"`)
+}
+
 func TestCommonServiceEntry_GC(t *testing.T) {
 	defer assertNotPanic(t)
 
@@ -96,3 +143,117 @@ func TestCommonServiceEntry_UnmarshalJSON(t *testing.T) {
 	})
 	assert.Nil(t, entry.UnmarshalJSON(nil))
 }
+
+func TestCommonServiceEntry_EffectiveConfig(t *testing.T) {
+	appCtx := NewAppContext()
+	appCtx.SetBootSource(nil, []byte(`
+app:
+  name: ut-app
+gin:
+  - name: greeter
+    port: 1949
+`))
+
+	// without enabled
+	entry := RegisterCommonServiceEntry(&BootCommonService{
+		Enabled: true,
+	}, WithAppCtxCommonServiceEntry(appCtx))
+	assert.Empty(t, entry.ConfigPath)
+
+	writer := httptest.NewRecorder()
+	entry.EffectiveConfig(writer, nil)
+	assert.Equal(t, 404, writer.Code)
+
+	// with enabled
+	entry = RegisterCommonServiceEntry(&BootCommonService{
+		Enabled:         true,
+		EffectiveConfig: true,
+	}, WithAppCtxCommonServiceEntry(appCtx))
+	assert.Equal(t, "/rk/v1/effectiveConfig", entry.ConfigPath)
+	assert.Contains(t, string(swAssetsFile), "/rk/v1/effectiveConfig")
+
+	writer = httptest.NewRecorder()
+	entry.EffectiveConfig(writer, httptest.NewRequest("GET", "/rk/v1/effectiveConfig?path=gin[0]", nil))
+	assert.Equal(t, 200, writer.Code)
+	assert.Contains(t, writer.Body.String(), `"path": "gin[0].port"`)
+	assert.Contains(t, writer.Body.String(), `"source": "file"`)
+	assert.NotContains(t, writer.Body.String(), "ut-app")
+}
+
+func TestCommonServiceEntry_FeatureFlags(t *testing.T) {
+	appCtx := NewAppContext()
+	_, err := RegisterFeatureFlagEntryYAMLE([]byte(`
+featureFlag:
+  - name: ut-flags
+    flags:
+      - name: ut
+        enabled: true
+`), WithAppCtx(appCtx))
+	assert.Nil(t, err)
+	appCtx.GetFeatureFlagEntry("ut-flags").IsEnabled("ut", nil)
+
+	entry := RegisterCommonServiceEntry(&BootCommonService{
+		Enabled:    true,
+		PathPrefix: "/ut",
+	}, WithAppCtxCommonServiceEntry(appCtx))
+	assert.Equal(t, "/ut/featureFlags", entry.FeatureFlagPath)
+	assert.Contains(t, string(swAssetsFile), "/ut/featureFlags")
+
+	writer := httptest.NewRecorder()
+	entry.FeatureFlags(writer, nil)
+	assert.Equal(t, 200, writer.Code)
+	assert.Contains(t, writer.Body.String(), `"entryName": "ut-flags"`)
+	assert.Contains(t, writer.Body.String(), `"on": 1`)
+}
+
+func TestCommonServiceEntry_Config(t *testing.T) {
+	appCtx := NewAppContext()
+	raw := `
+config:
+  - name: ut-config
+    content:
+      db:
+        port: 3306
+        password: ut-pass
+`
+	entries, err := RegisterConfigEntryYAMLE([]byte(raw), WithAppCtx(appCtx))
+	assert.Nil(t, err)
+
+	// without enabled
+	entry := RegisterCommonServiceEntry(&BootCommonService{
+		Enabled: true,
+	}, WithAppCtxCommonServiceEntry(appCtx))
+	assert.Empty(t, entry.ConfigEntryPath)
+	assert.NotContains(t, string(swAssetsFile), "/rk/v1/config/history")
+
+	writer := httptest.NewRecorder()
+	entry.Config(writer, nil)
+	assert.Equal(t, 404, writer.Code)
+
+	writer = httptest.NewRecorder()
+	entry.ConfigHistory(writer, nil)
+	assert.Equal(t, 404, writer.Code)
+
+	// with enabled
+	entry = RegisterCommonServiceEntry(&BootCommonService{
+		Enabled: true,
+		Config:  true,
+	}, WithAppCtxCommonServiceEntry(appCtx))
+	assert.Equal(t, "/rk/v1/config", entry.ConfigEntryPath)
+	assert.Equal(t, "/rk/v1/config/history", entry.ConfigHistoryPath)
+	assert.Contains(t, string(swAssetsFile), "/rk/v1/config/history")
+
+	writer = httptest.NewRecorder()
+	entry.Config(writer, nil)
+	assert.Equal(t, 200, writer.Code)
+	assert.Contains(t, writer.Body.String(), `"path": "db.port"`)
+	assert.NotContains(t, writer.Body.String(), "ut-pass")
+
+	configEntry := entries["ut-config"].(*ConfigEntry)
+	assert.Nil(t, configEntry.Reload(context.TODO(), []byte(strings.ReplaceAll(raw, "3306", "3307"))))
+
+	writer = httptest.NewRecorder()
+	entry.ConfigHistory(writer, nil)
+	assert.Equal(t, 200, writer.Code)
+	assert.Contains(t, writer.Body.String(), `"db.port": 3307`)
+}
@@ -0,0 +1,358 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkentry
+
+import (
+	"errors"
+	"fmt"
+	"github.com/mitchellh/mapstructure"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Bind decode values under prefix into target which should be pointer of struct, all values decoded if prefix is empty.
+//
+// Fields are matched with yaml tag case-insensitively, strings from ENV are converted into field types.
+//
+// Fields missing in config are filled with default tag, and validate tag is checked after decoded.
+// Rules of validate tag are separated by comma, regex should be the last rule since it may contain comma.
+//
+//	required    value should not be zero
+//	min=1       minimum of number, or minimum length of string, slice and map
+//	max=10      maximum of number, or maximum length of string, slice and map
+//	oneof=a b   value should be one of values separated by space
+//	regex=^a.*$ string should match regular expression
+//
+// Example:
+//
+//	type DB struct {
+//	    Host    string        `yaml:"host" validate:"required"`
+//	    Port    int           `yaml:"port" default:"3306" validate:"min=1,max=65535"`
+//	    Mode    string        `yaml:"mode" default:"rw" validate:"oneof=rw ro"`
+//	    Timeout time.Duration `yaml:"timeout" default:"5s"`
+//	}
+//
+// Issues of validation are returned in *BootConfigError with key path, like db.port.
+func (entry *ConfigEntry) Bind(prefix string, target interface{}) error {
+	value := reflect.ValueOf(target)
+	if value.Kind() != reflect.Ptr || value.IsNil() || value.Elem().Kind() != reflect.Struct {
+		return errors.New("target should be non-nil pointer of struct")
+	}
+
+	prefix = strings.ToLower(prefix)
+	bootErr := &BootConfigError{}
+
+	// 1: fill defaults
+	applyBindDefaults(prefix, value.Elem(), bootErr)
+	if err := bootErr.ErrorOrNil(); err != nil {
+		return err
+	}
+
+	// 2: decode values under prefix
+	decoder, err := newBindDecoder(target)
+	if err != nil {
+		return err
+	}
+
+	if err := decoder.Decode(entry.settingsOf(prefix)); err != nil {
+		bootErr.Add(prefix, err)
+		return bootErr
+	}
+
+	// 3: validate
+	validateBind(prefix, value.Elem(), bootErr)
+
+	return bootErr.ErrorOrNil()
+}
+
+// settingsOf returns nested map of values under prefix, ENV overrides of known keys are included.
+func (entry *ConfigEntry) settingsOf(prefix string) map[string]interface{} {
+	entry.lock.Lock()
+	vp := entry.vp
+	entry.lock.Unlock()
+
+	res := map[string]interface{}{}
+	if vp == nil {
+		return res
+	}
+
+	for _, k := range vp.AllKeys() {
+		if len(prefix) > 0 && !strings.HasPrefix(k, prefix+".") {
+			continue
+		}
+
+		tokens := strings.Split(strings.TrimPrefix(strings.TrimPrefix(k, prefix), "."), ".")
+		m := res
+		for i := 0; i < len(tokens)-1; i++ {
+			child, ok := m[tokens[i]].(map[string]interface{})
+			if !ok {
+				child = map[string]interface{}{}
+				m[tokens[i]] = child
+			}
+			m = child
+		}
+		m[tokens[len(tokens)-1]] = vp.Get(k)
+	}
+
+	return res
+}
+
+// newBindDecoder returns weakly typed decoder which converts strings from ENV and tags.
+func newBindDecoder(result interface{}) (*mapstructure.Decoder, error) {
+	return mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		Result:           result,
+		TagName:          "yaml",
+		WeaklyTypedInput: true,
+		DecodeHook: mapstructure.ComposeDecodeHookFunc(
+			mapstructure.StringToTimeDurationHookFunc(),
+			mapstructure.StringToSliceHookFunc(","),
+		),
+	})
+}
+
+// bindFieldPath returns key path of field with yaml tag or field name.
+func bindFieldPath(parent string, field reflect.StructField) (string, bool) {
+	tokens := strings.Split(field.Tag.Get("yaml"), ",")
+	if tokens[0] == "-" {
+		return "", false
+	}
+
+	// squashed struct shares parent path
+	if field.Anonymous && (hasTagOption(tokens, "squash") || hasTagOption(tokens, "inline")) {
+		return parent, true
+	}
+
+	name := tokens[0]
+	if len(name) < 1 {
+		name = field.Name
+	}
+
+	return joinBootPath(parent, strings.ToLower(name)), true
+}
+
+// applyBindDefaults fill fields with default tag recursively.
+func applyBindDefaults(path string, value reflect.Value, bootErr *BootConfigError) {
+	t := value.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		fieldPath, ok := bindFieldPath(path, field)
+		if !ok || len(field.PkgPath) > 0 {
+			continue
+		}
+
+		fieldValue := value.Field(i)
+		if def, ok := field.Tag.Lookup("default"); ok && fieldValue.IsZero() {
+			decoder, err := newBindDecoder(fieldValue.Addr().Interface())
+			if err == nil {
+				err = decoder.Decode(def)
+			}
+			if err != nil {
+				bootErr.Add(fieldPath, fmt.Errorf("invalid default value %s, %v", def, err))
+			}
+			continue
+		}
+
+		if fieldValue.Kind() == reflect.Struct {
+			applyBindDefaults(fieldPath, fieldValue, bootErr)
+		}
+	}
+}
+
+// validateBind check fields with validate tag recursively.
+func validateBind(path string, value reflect.Value, bootErr *BootConfigError) {
+	t := value.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		fieldPath, ok := bindFieldPath(path, field)
+		if !ok || len(field.PkgPath) > 0 {
+			continue
+		}
+
+		fieldValue := value.Field(i)
+		if rules, ok := field.Tag.Lookup("validate"); ok {
+			if err := validateBindValue(fieldValue, rules); err != nil {
+				bootErr.Add(fieldPath, err)
+			}
+		}
+
+		switch {
+		case fieldValue.Kind() == reflect.Struct:
+			validateBind(fieldPath, fieldValue, bootErr)
+		case fieldValue.Kind() == reflect.Ptr && !fieldValue.IsNil() && fieldValue.Elem().Kind() == reflect.Struct:
+			validateBind(fieldPath, fieldValue.Elem(), bootErr)
+		}
+	}
+}
+
+// validateBindValue check value with rules like required,min=1,max=10.
+func validateBindValue(value reflect.Value, rules string) error {
+	for len(rules) > 0 {
+		rule := rules
+		if strings.HasPrefix(rules, "regex=") {
+			rules = ""
+		} else if i := strings.Index(rules, ","); i >= 0 {
+			rule, rules = rules[:i], rules[i+1:]
+		} else {
+			rules = ""
+		}
+
+		name, arg := rule, ""
+		if i := strings.Index(rule, "="); i >= 0 {
+			name, arg = rule[:i], rule[i+1:]
+		}
+
+		switch name {
+		case "required":
+			if value.IsZero() {
+				return errors.New("required")
+			}
+		case "min", "max":
+			limit, err := strconv.ParseFloat(arg, 64)
+			if err != nil {
+				return fmt.Errorf("invalid rule %s", rule)
+			}
+
+			actual, ok := bindMeasure(value)
+			if !ok {
+				return fmt.Errorf("rule %s is not supported by %s", name, value.Kind())
+			}
+
+			if name == "min" && actual < limit {
+				return fmt.Errorf("should be greater than or equal to %s, got %v", arg, actual)
+			}
+			if name == "max" && actual > limit {
+				return fmt.Errorf("should be less than or equal to %s, got %v", arg, actual)
+			}
+		case "oneof":
+			actual := fmt.Sprintf("%v", value.Interface())
+			found := false
+			for _, v := range strings.Fields(arg) {
+				found = found || v == actual
+			}
+
+			if !found {
+				return fmt.Errorf("should be one of [%s], got %s", arg, actual)
+			}
+		case "regex":
+			regex, err := regexp.Compile(arg)
+			if err != nil {
+				return fmt.Errorf("invalid rule %s, %v", rule, err)
+			}
+
+			if value.Kind() != reflect.String || !regex.MatchString(value.String()) {
+				return fmt.Errorf("should match %s", arg)
+			}
+		case "":
+		default:
+			return fmt.Errorf("unknown rule %s", name)
+		}
+	}
+
+	return nil
+}
+
+// bindMeasure returns number or length of value which is compared with min and max.
+func bindMeasure(value reflect.Value) (float64, bool) {
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(value.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(value.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return value.Float(), true
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		return float64(value.Len()), true
+	default:
+		return 0, false
+	}
+}
+
+// ConfigBinding is typed config bound from ConfigEntry by BindConfigEntry.
+//
+// Bound value is re-bound each time values under prefix changed, a new pointer is swapped in
+// only if decoding and validation passed, so value returned by Get should be treated as immutable.
+type ConfigBinding[T any] struct {
+	lock      sync.RWMutex
+	value     *T
+	lastErr   error
+	callbacks []func(oldValue, newValue *T)
+}
+
+// BindConfigEntry bind values under prefix of ConfigEntry into T and re-bind on change.
+//
+// Example:
+//
+//	binding, err := rkentry.BindConfigEntry[DB](rkentry.GlobalAppCtx.GetConfigEntry("my-config"), "db")
+//	db := binding.Get()
+func BindConfigEntry[T any](entry *ConfigEntry, prefix string) (*ConfigBinding[T], error) {
+	if entry == nil {
+		return nil, errors.New("config entry is nil")
+	}
+
+	value := new(T)
+	if err := entry.Bind(prefix, value); err != nil {
+		return nil, err
+	}
+
+	binding := &ConfigBinding[T]{
+		value: value,
+	}
+
+	entry.OnChange(prefix, func(map[string]interface{}, map[string]interface{}) {
+		newValue := new(T)
+		err := entry.Bind(prefix, newValue)
+
+		binding.lock.Lock()
+		binding.lastErr = err
+		oldValue := binding.value
+		if err == nil {
+			binding.value = newValue
+		}
+		callbacks := append([]func(oldValue, newValue *T){}, binding.callbacks...)
+		binding.lock.Unlock()
+
+		if err != nil {
+			return
+		}
+
+		for _, f := range callbacks {
+			f(oldValue, newValue)
+		}
+	})
+
+	return binding, nil
+}
+
+// Get returns current bound value.
+func (b *ConfigBinding[T]) Get() *T {
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+
+	return b.value
+}
+
+// GetLastError returns error of last re-bind, nil returned if succeeded.
+func (b *ConfigBinding[T]) GetLastError() error {
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+
+	return b.lastErr
+}
+
+// OnChange register function which would be called with old and new value after re-bound.
+func (b *ConfigBinding[T]) OnChange(f func(oldValue, newValue *T)) {
+	if f == nil {
+		return
+	}
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.callbacks = append(b.callbacks, f)
+}
@@ -0,0 +1,146 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkentry
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type bindDBMock struct {
+	Host     string        `yaml:"host" validate:"required"`
+	Port     int           `yaml:"port" default:"3306" validate:"min=1,max=65535"`
+	Mode     string        `yaml:"mode" default:"rw" validate:"oneof=rw ro"`
+	User     string        `yaml:"user" validate:"regex=^[a-z]{2,}$"`
+	Timeout  time.Duration `yaml:"timeout" default:"5s"`
+	Replicas []string      `yaml:"replicas" default:"a,b" validate:"max=3"`
+	Pool     struct {
+		MaxConns int `yaml:"maxConns" default:"10" validate:"min=1"`
+	} `yaml:"pool"`
+}
+
+func newBindConfigEntry(t *testing.T, content string) (*ConfigEntry, string) {
+	configPath := filepath.ToSlash(filepath.Join(t.TempDir(), "config.yaml"))
+	assert.Nil(t, os.WriteFile(configPath, []byte(content), os.ModePerm))
+
+	entries := RegisterConfigEntry(&BootConfig{
+		Config: []*BootConfigE{
+			{
+				Name:      "ut-config",
+				Path:      configPath,
+				EnvPrefix: "ut",
+			},
+		},
+	}, WithAppCtx(NewAppContext()))
+
+	return entries[0], configPath
+}
+
+func TestConfigEntry_Bind(t *testing.T) {
+	entry, _ := newBindConfigEntry(t, `
+db:
+  host: localhost
+  port: 3306
+  user: ut
+  timeout: 1s
+  pool:
+    maxConns: 20
+`)
+
+	// with ENV override
+	assert.Nil(t, os.Setenv("UT_DB.PORT", "3307"))
+	defer os.Unsetenv("UT_DB.PORT")
+
+	db := &bindDBMock{}
+	assert.Nil(t, entry.Bind("db", db))
+	assert.Equal(t, "localhost", db.Host)
+	assert.Equal(t, 3307, db.Port)
+	assert.Equal(t, "rw", db.Mode)
+	assert.Equal(t, time.Second, db.Timeout)
+	assert.Equal(t, []string{"a", "b"}, db.Replicas)
+	assert.Equal(t, 20, db.Pool.MaxConns)
+
+	// with invalid target
+	assert.NotNil(t, entry.Bind("db", bindDBMock{}))
+	assert.NotNil(t, entry.Bind("db", (*bindDBMock)(nil)))
+}
+
+func TestConfigEntry_Bind_WithInvalidValues(t *testing.T) {
+	entry, _ := newBindConfigEntry(t, `
+db:
+  port: 70000
+  mode: invalid
+  user: UT
+  replicas: [a, b, c, d]
+  pool:
+    maxConns: 0
+`)
+
+	err := entry.Bind("db", &bindDBMock{})
+	assert.NotNil(t, err)
+
+	issues := err.(*BootConfigError).Issues
+	assert.Len(t, issues, 6)
+	assert.Equal(t, "db.host", issues[0].Path)
+	assert.Equal(t, "required", issues[0].Message)
+	assert.Equal(t, "db.port", issues[1].Path)
+	assert.Equal(t, "db.mode", issues[2].Path)
+	assert.Equal(t, "db.user", issues[3].Path)
+	assert.Equal(t, "db.replicas", issues[4].Path)
+	assert.Equal(t, "db.pool.maxconns", issues[5].Path)
+
+	// with wrong type
+	entry, _ = newBindConfigEntry(t, "db:\n  host: [invalid]\n")
+	assert.NotNil(t, entry.Bind("db", &bindDBMock{}))
+
+	// with invalid default
+	assert.NotNil(t, entry.Bind("", &struct {
+		Port int `yaml:"port" default:"invalid"`
+	}{}))
+
+	// with unknown rule
+	assert.NotNil(t, entry.Bind("", &struct {
+		Port int `yaml:"port" validate:"unknown"`
+	}{}))
+}
+
+func TestBindConfigEntry(t *testing.T) {
+	_, err := BindConfigEntry[bindDBMock](nil, "db")
+	assert.NotNil(t, err)
+
+	entry, configPath := newBindConfigEntry(t, "db:\n  host: localhost\n  user: ut\n")
+	binding, err := BindConfigEntry[bindDBMock](entry, "db")
+	assert.Nil(t, err)
+	old := binding.Get()
+	assert.Equal(t, "localhost", old.Host)
+
+	var changed *bindDBMock
+	binding.OnChange(nil)
+	binding.OnChange(func(oldValue, newValue *bindDBMock) {
+		changed = newValue
+	})
+
+	// re-bind on change
+	assert.Nil(t, os.WriteFile(configPath, []byte("db:\n  host: remote\n  user: ut\n"), os.ModePerm))
+	assert.Nil(t, entry.reloadFile())
+	assert.Nil(t, binding.GetLastError())
+	assert.Equal(t, "remote", binding.Get().Host)
+	assert.Equal(t, binding.Get(), changed)
+	assert.Equal(t, "localhost", old.Host)
+
+	// keep previous value if invalid
+	assert.Nil(t, os.WriteFile(configPath, []byte("db:\n  host: remote\n  user: ut\n  port: 0\n"), os.ModePerm))
+	assert.Nil(t, entry.reloadFile())
+	assert.NotNil(t, binding.GetLastError())
+	assert.Equal(t, 3306, binding.Get().Port)
+
+	// with invalid config
+	_, err = BindConfigEntry[bindDBMock](entry, "db")
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,128 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkentry
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	// encryptedValueRegex matches whole value like ENC(BASE64CIPHERTEXT)
+	encryptedValueRegex = regexp.MustCompile(`^ENC\(([A-Za-z0-9+/=]+)\)$`)
+	// encryptedValuesRegex matches every encrypted value in content of file
+	encryptedValuesRegex = regexp.MustCompile(`ENC\(([A-Za-z0-9+/=]+)\)`)
+)
+
+// IsEncryptedValue returns true if value is of the form ENC(BASE64CIPHERTEXT).
+func IsEncryptedValue(value string) bool {
+	return encryptedValueRegex.MatchString(strings.TrimSpace(value))
+}
+
+// EncryptValue encrypt plaintext with crypto and returns value of the form ENC(BASE64CIPHERTEXT).
+//
+// Ciphertext is the same as Crypto.Encrypt returns, which is nonce followed by sealed data for CryptoAESEntry.
+func EncryptValue(crypto Crypto, plaintext string) (string, error) {
+	if crypto == nil {
+		return "", errors.New("crypto is nil")
+	}
+
+	ciphertext, err := crypto.Encrypt([]byte(plaintext))
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("ENC(%s)", base64.StdEncoding.EncodeToString(ciphertext)), nil
+}
+
+// DecryptValue decrypt value of the form ENC(BASE64CIPHERTEXT) with crypto, bare base64 ciphertext is accepted as well.
+func DecryptValue(crypto Crypto, value string) (string, error) {
+	if crypto == nil {
+		return "", errors.New("crypto is nil")
+	}
+
+	value = strings.TrimSpace(value)
+	if tokens := encryptedValueRegex.FindStringSubmatch(value); len(tokens) == 2 {
+		value = tokens[1]
+	}
+
+	ciphertext, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return "", fmt.Errorf("invalid base64 ciphertext, %v", err)
+	}
+
+	plaintext, err := crypto.Decrypt(ciphertext)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt with crypto entry %s, %v", crypto.GetName(), err)
+	}
+
+	return string(plaintext), nil
+}
+
+// RotateEncryptedValues re-encrypt every ENC(BASE64CIPHERTEXT) in content with newCrypto,
+// content is returned with number of rotated values.
+//
+// Nothing is returned if any value could not be decrypted with oldCrypto.
+func RotateEncryptedValues(content []byte, oldCrypto, newCrypto Crypto) ([]byte, int, error) {
+	var rotateErr error
+	count := 0
+
+	res := encryptedValuesRegex.ReplaceAllFunc(content, func(match []byte) []byte {
+		if rotateErr != nil {
+			return match
+		}
+
+		plaintext, err := DecryptValue(oldCrypto, string(match))
+		if err != nil {
+			rotateErr = err
+			return match
+		}
+
+		value, err := EncryptValue(newCrypto, plaintext)
+		if err != nil {
+			rotateErr = err
+			return match
+		}
+
+		count++
+		return []byte(value)
+	})
+
+	if rotateErr != nil {
+		return nil, 0, rotateErr
+	}
+
+	return res, count, nil
+}
+
+// decryptValueFunc returns function which decrypts ENC(BASE64CIPHERTEXT) with crypto entry in AppContext.
+//
+// Values without ENC() are returned as it is, nil returned if name is empty.
+func decryptValueFunc(appCtx *AppContext, name string) (func(string) (string, error), error) {
+	if len(name) < 1 {
+		return nil, nil
+	}
+
+	if appCtx == nil {
+		appCtx = GlobalAppCtx
+	}
+
+	crypto := appCtx.GetCryptoEntry(name)
+	if crypto == nil {
+		return nil, fmt.Errorf("crypto entry %s not found", name)
+	}
+
+	return func(value string) (string, error) {
+		if !IsEncryptedValue(value) {
+			return value, nil
+		}
+
+		return DecryptValue(crypto, value)
+	}, nil
+}
@@ -0,0 +1,112 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkentry
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestEncryptValue(t *testing.T) {
+	crypto, err := NewCryptoAES("ut-crypto", []byte("0123456789abcdef"))
+	assert.Nil(t, err)
+
+	value, err := EncryptValue(crypto, "ut-pass")
+	assert.Nil(t, err)
+	assert.True(t, IsEncryptedValue(value))
+	assert.False(t, IsEncryptedValue("ut-pass"))
+
+	res, err := DecryptValue(crypto, value)
+	assert.Nil(t, err)
+	assert.Equal(t, "ut-pass", res)
+
+	// bare base64 ciphertext
+	res, err = DecryptValue(crypto, strings.TrimSuffix(strings.TrimPrefix(value, "ENC("), ")"))
+	assert.Nil(t, err)
+	assert.Equal(t, "ut-pass", res)
+
+	// with wrong key
+	other, _ := NewCryptoAES("ut-other", []byte("abcdef0123456789"))
+	_, err = DecryptValue(other, value)
+	assert.Contains(t, err.Error(), "ut-other")
+
+	// with invalid value
+	_, err = DecryptValue(crypto, "ENC(invalid")
+	assert.NotNil(t, err)
+	_, err = EncryptValue(nil, "ut-pass")
+	assert.NotNil(t, err)
+}
+
+func TestRotateEncryptedValues(t *testing.T) {
+	oldCrypto, _ := NewCryptoAES("ut-old", []byte("0123456789abcdef"))
+	newCrypto, _ := NewCryptoAES("ut-new", []byte("abcdef0123456789"))
+
+	user, _ := EncryptValue(oldCrypto, "ut-user")
+	pass, _ := EncryptValue(oldCrypto, "ut-pass")
+	content := []byte("db:\n  user: " + user + "\n  pass: \"" + pass + "\"\n  host: localhost\n")
+
+	res, count, err := RotateEncryptedValues(content, oldCrypto, newCrypto)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, count)
+	assert.NotContains(t, string(res), user)
+	assert.Contains(t, string(res), "host: localhost")
+
+	tokens := encryptedValuesRegex.FindAllString(string(res), -1)
+	assert.Len(t, tokens, 2)
+	plaintext, err := DecryptValue(newCrypto, tokens[1])
+	assert.Nil(t, err)
+	assert.Equal(t, "ut-pass", plaintext)
+
+	// with wrong key
+	res, count, err = RotateEncryptedValues(content, newCrypto, oldCrypto)
+	assert.Nil(t, res)
+	assert.Zero(t, count)
+	assert.NotNil(t, err)
+}
+
+func TestRegisterConfigEntry_WithCryptoEntry(t *testing.T) {
+	appCtx := NewAppContext()
+	crypto, _ := NewCryptoAES("ut-crypto", []byte("0123456789abcdef"))
+	appCtx.AddEntry(crypto)
+
+	pass, _ := EncryptValue(crypto, "ut-pass")
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	assert.Nil(t, os.WriteFile(configPath, []byte("db:\n  pass: "+pass+"\n  hosts: [\""+pass+"\"]\n"), os.ModePerm))
+
+	raw := `
+config:
+  - name: ut-config
+    path: ` + configPath + `
+    cryptoEntry: ut-crypto
+    content:
+      token: ` + pass + `
+`
+	entries, err := RegisterConfigEntryYAMLE([]byte(raw), WithAppCtx(appCtx))
+	assert.Nil(t, err)
+	entry := entries["ut-config"].(*ConfigEntry)
+	assert.Equal(t, "ut-pass", entry.GetString("db.pass"))
+	assert.Equal(t, []interface{}{"ut-pass"}, entry.Get("db.hosts"))
+	assert.Equal(t, "ut-pass", entry.GetString("token"))
+	assert.NotContains(t, entry.String(), "ut-pass")
+
+	// decrypted after reloaded
+	assert.Nil(t, entry.Reload(context.TODO(), []byte(raw)))
+	assert.Equal(t, "ut-pass", entry.GetString("db.pass"))
+
+	// without crypto entry, value is kept as it is
+	entries, err = RegisterConfigEntryYAMLE([]byte(strings.ReplaceAll(raw, "cryptoEntry: ut-crypto", "")), WithAppCtx(NewAppContext()))
+	assert.Nil(t, err)
+	assert.Equal(t, pass, entries["ut-config"].(*ConfigEntry).GetString("db.pass"))
+
+	// with missing crypto entry
+	entries, err = RegisterConfigEntryYAMLE([]byte(raw), WithAppCtx(NewAppContext()))
+	assert.Empty(t, entries)
+	assert.Contains(t, err.Error(), "ut-crypto")
+}
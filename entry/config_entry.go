@@ -12,38 +12,44 @@ import (
 	"github.com/spf13/viper"
 	"os"
 	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
 )
 
-// RegisterConfigEntry create ConfigEntry with BootConfigConfig.
-func RegisterConfigEntry(boot *BootConfig) []*ConfigEntry {
-	res := make([]*ConfigEntry, 0)
-
-	// filter out based domain
-	configMap := make(map[string]*BootConfigE)
-	for _, config := range boot.Config {
-		if len(config.Name) < 1 {
-			continue
-		}
+const (
+	// ConfigSourceFile key supplied by config file, source would be like file:/path/config.dev.yaml
+	ConfigSourceFile = "file"
+	// ConfigSourceContent key supplied by content in boot config
+	ConfigSourceContent = "content"
+	// ConfigSourceEnv key supplied by environment variable, source would be like env:MY_KEY
+	ConfigSourceEnv = "env"
+	// ConfigSourceRemote key supplied by remote endpoint, source would be like remote:https://config/app.yaml
+	ConfigSourceRemote = "remote"
+)
 
-		if !IsValidDomain(config.Domain) {
-			continue
-		}
+// RegisterConfigEntry create ConfigEntry with BootConfigConfig.
+func RegisterConfigEntry(boot *BootConfig, opts ...RegOption) []*ConfigEntry {
+	res, err := RegisterConfigEntryE(boot, opts...)
+	if err != nil {
+		ShutdownWithError(err)
+	}
 
-		// * or matching domain
-		// 1: add it to map if missing
-		if _, ok := configMap[config.Name]; !ok {
-			configMap[config.Name] = config
-			continue
-		}
+	return res
+}
 
-		// 2: already has an entry, then compare domain,
-		//    only one case would occur, previous one is already the correct one, continue
-		if config.Domain == "" || config.Domain == "*" {
-			continue
-		}
+// RegisterConfigEntryE is the same as RegisterConfigEntry but returns error instead of panic.
+func RegisterConfigEntryE(boot *BootConfig, opts ...RegOption) ([]*ConfigEntry, error) {
+	appCtx := NewRegOptions(opts...).AppCtx
+	res := make([]*ConfigEntry, 0)
+	bootErr := &BootConfigError{}
 
-		configMap[config.Name] = config
-	}
+	// filter out based domain
+	configMap := filterByDomain(boot.Config, func(config *BootConfigE) (string, string) {
+		return config.Name, config.Domain
+	})
 
 	for _, config := range configMap {
 		entry := &ConfigEntry{
@@ -51,61 +57,103 @@ func RegisterConfigEntry(boot *BootConfig) []*ConfigEntry {
 			entryType:        ConfigEntryType,
 			entryDescription: config.Description,
 			content:          config.Content,
-			Viper:            viper.New(),
 			Path:             config.Path,
+			Profile:          config.Profile,
+			Overlays:         make([]string, 0),
 			EnvPrefix:        config.EnvPrefix,
+			watch:            config.Watch,
+			watchDebounce:    time.Duration(config.WatchDebounceMs) * time.Millisecond,
+			cryptoEntry:      config.CryptoEntry,
+			appCtx:           appCtx,
+		}
+
+		if entry.watchDebounce <= 0 {
+			entry.watchDebounce = defaultConfigWatchDebounce
+		}
+
+		// profile overlay follows domain by default
+		if len(entry.Profile) < 1 {
+			entry.Profile = os.Getenv("DOMAIN")
 		}
 
 		// if file path was provided
 		if len(entry.Path) > 0 {
-			if !filepath.IsAbs(entry.Path) {
-				if wd, err := os.Getwd(); err != nil {
-					ShutdownWithError(err)
-				} else {
-					entry.Path = filepath.ToSlash(filepath.Join(wd, entry.Path))
-				}
+			if p, err := absConfigPath(entry.Path); err != nil {
+				bootErr.Add(bootPath("config", boot.Config, config)+".path", err)
+				continue
+			} else {
+				entry.Path = p
 			}
+		}
 
-			// skip this element if path is not valid
-			if fileExists(entry.Path) {
-				entry.Viper.SetConfigFile(entry.Path)
-				if err := entry.Viper.ReadInConfig(); err != nil {
-					ShutdownWithError(fmt.Errorf("failed to read file, path:%s", entry.Path))
-				}
+		if config.Remote != nil {
+			remote, err := newConfigRemote(config.Remote, appCtx)
+			if err != nil {
+				bootErr.Add(bootPath("config", boot.Config, config)+".remote", err)
+				continue
+			}
+			entry.remote = remote
+
+			// CertEntry is not bootstrapped yet, fetch while bootstrapping and start with cache
+			if len(remote.certEntry) > 0 {
+				remote.loadCache()
+			} else if err := remote.load(); err != nil {
+				bootErr.Add(bootPath("config", boot.Config, config)+".remote", err)
+				continue
 			}
 		}
 
-		// if content exist, then fill viper
-		for k, v := range entry.content {
-			entry.Viper.Set(k, v)
+		for i := range config.Overlays {
+			if p, err := absConfigPath(config.Overlays[i]); err != nil {
+				bootErr.Add(fmt.Sprintf("%s.overlays[%d]", bootPath("config", boot.Config, config), i), err)
+			} else {
+				entry.Overlays = append(entry.Overlays, p)
+			}
 		}
 
-		// enable automatic env
-		// issue: https://github.com/rookie-ninja/rk-boot/issues/55
-		entry.Viper.AutomaticEnv()
-		entry.Viper.SetEnvPrefix(entry.EnvPrefix)
+		vp, sources, secrets, err := entry.newViper()
+		if err != nil {
+			bootErr.Add(bootPath("config", boot.Config, config)+".path", err)
+			continue
+		}
+		entry.vp = vp
+		entry.Viper = vp
+		entry.sources = sources
+		entry.secrets = secrets
 
-		GlobalAppCtx.AddEntry(entry)
+		appCtx.AddEntry(entry)
 		res = append(res, entry)
 	}
 
-	return res
+	return res, bootErr.ErrorOrNil()
 }
 
 // RegisterConfigEntryYAML register function
 func RegisterConfigEntryYAML(raw []byte) map[string]Entry {
+	res, err := RegisterConfigEntryYAMLE(raw)
+	if err != nil {
+		ShutdownWithError(err)
+	}
+
+	return res
+}
+
+// RegisterConfigEntryYAMLE is the same as RegisterConfigEntryYAML but returns error instead of panic.
+func RegisterConfigEntryYAMLE(raw []byte, opts ...RegOption) (map[string]Entry, error) {
 	boot := &BootConfig{}
-	UnmarshalBootYAML(raw, boot)
+	if err := unmarshalBootYAMLE(NewRegOptions(opts...).AppCtx, raw, boot); err != nil {
+		return nil, err
+	}
 
 	res := map[string]Entry{}
 
-	entries := RegisterConfigEntry(boot)
+	entries, err := RegisterConfigEntryE(boot, opts...)
 	for i := range entries {
 		entry := entries[i]
 		res[entry.GetName()] = entry
 	}
 
-	return res
+	return res, err
 }
 
 // BootConfig is bootstrap config of ConfigEntry information.
@@ -114,17 +162,32 @@ type BootConfig struct {
 }
 
 // BootConfigE element of ConfigEntry
+//
+// Layers are deep merged in order of path, profile overlay, overlays, remote, content and ENV, later layer wins.
+// Profile overlay is file next to path with profile inserted before extension, like config.dev.yaml,
+// profile is DOMAIN environment variable by default. Missing files are skipped except path while watching.
 type BootConfigE struct {
 	Name        string                 `yaml:"name" json:"name"`
 	Description string                 `yaml:"description" json:"description"`
 	Domain      string                 `yaml:"domain" json:"domain"`
-	Path        string                 `yaml:"path" json:"name"`
+	Path        string                 `yaml:"path" json:"path"`
+	Profile     string                 `yaml:"profile" json:"profile"`
+	Overlays    []string               `yaml:"overlays" json:"overlays"`
 	EnvPrefix   string                 `yaml:"envPrefix" json:"envPrefix"`
 	Content     map[string]interface{} `yaml:"content" json:"content"`
+	// Watch reload file once changed, subscribers registered by ConfigEntry.OnChange will be notified
+	Watch           bool `yaml:"watch" json:"watch"`
+	WatchDebounceMs int  `yaml:"watchDebounceMs" json:"watchDebounceMs"`
+	// Remote fetch config from HTTP endpoint, subscribers will be notified once changed while polling
+	Remote *BootConfigRemote `yaml:"remote" json:"remote"`
+	// CryptoEntry decrypts values of the form ENC(BASE64CIPHERTEXT), crypto entry should be added into AppContext before registered
+	CryptoEntry string `yaml:"cryptoEntry" json:"cryptoEntry"`
 }
 
 // ConfigEntry contains bellow fields.
 type ConfigEntry struct {
+	// Deprecated: Viper is replaced as a whole once reloaded and reading it is not guarded by lock,
+	// use GetViper() or accessors like GetString() instead.
 	*viper.Viper
 
 	entryName        string                 `yaml:"-" json:"-"`
@@ -132,15 +195,349 @@ type ConfigEntry struct {
 	entryDescription string                 `yaml:"-" json:"-"`
 	Locale           string                 `yaml:"-" json:"-"`
 	Path             string                 `yaml:"-" json:"-"`
+	Profile          string                 `yaml:"-" json:"-"`
+	Overlays         []string               `yaml:"-" json:"-"`
 	EnvPrefix        string                 `yaml:"-" json:"-"`
 	content          map[string]interface{} `yaml:"-" json:"-"`
+	sources          map[string]string      `yaml:"-" json:"-"`
+	secrets          map[string]bool        `yaml:"-" json:"-"`
+	vp               *viper.Viper           `yaml:"-" json:"-"`
+	watch            bool                   `yaml:"-" json:"-"`
+	watchDebounce    time.Duration          `yaml:"-" json:"-"`
+	watchStop        chan struct{}          `yaml:"-" json:"-"`
+	remote           *configRemote          `yaml:"-" json:"-"`
+	pollStop         chan struct{}          `yaml:"-" json:"-"`
+	bootstrapped     bool                   `yaml:"-" json:"-"`
+	cryptoEntry      string                 `yaml:"-" json:"-"`
+	appCtx           *AppContext            `yaml:"-" json:"-"`
+	subscribers      []*configSubscriber    `yaml:"-" json:"-"`
+	reloadCount      int64                  `yaml:"-" json:"-"`
+	lastReloadErr    error                  `yaml:"-" json:"-"`
+	lock             sync.RWMutex           `yaml:"-" json:"-"`
+}
+
+// Bootstrap entry, config file would be watched and remote endpoint would be polled if enabled.
+func (entry *ConfigEntry) Bootstrap(ctx context.Context) {
+	if err := entry.BootstrapE(ctx); err != nil {
+		ShutdownWithError(err)
+	}
+}
+
+// BootstrapE is the same as Bootstrap but returns error instead of panic.
+func (entry *ConfigEntry) BootstrapE(context.Context) error {
+	if err := entry.loadPendingRemote(); err != nil {
+		return err
+	}
+
+	entry.lock.Lock()
+	entry.bootstrapped = true
+	watch := entry.watch
+	entry.lock.Unlock()
+
+	entry.startPoll()
+
+	if !watch {
+		return nil
+	}
+
+	return entry.startWatch()
+}
+
+// Interrupt entry, stop watching config file and polling remote endpoint.
+func (entry *ConfigEntry) Interrupt(context.Context) {
+	entry.stopWatch()
+	entry.stopPoll()
+
+	entry.lock.Lock()
+	entry.bootstrapped = false
+	entry.lock.Unlock()
+}
+
+// DependsOn returns CertEntry used by remote config source.
+func (entry *ConfigEntry) DependsOn() []EntryRef {
+	res := make([]EntryRef, 0)
+
+	entry.lock.Lock()
+	remote := entry.remote
+	entry.lock.Unlock()
+
+	if remote != nil && len(remote.certEntry) > 0 {
+		res = append(res, EntryRef{Type: CertEntryType, Name: remote.certEntry})
+	}
+
+	return res
+}
+
+// loadPendingRemote fetch remote config which was not fetched while registering and swap viper.
+func (entry *ConfigEntry) loadPendingRemote() error {
+	entry.lock.Lock()
+	remote := entry.remote
+	entry.lock.Unlock()
+
+	if remote == nil || !remote.isPending() {
+		return nil
+	}
+
+	if err := remote.load(); err != nil {
+		return err
+	}
+
+	vp, sources, secrets, err := entry.newViper()
+	if err != nil {
+		return err
+	}
+
+	entry.swapViper(vp, sources, secrets)
+
+	return nil
 }
 
-// Bootstrap entry.
-func (entry *ConfigEntry) Bootstrap(context.Context) {}
+// newViper deep merge config files and content into a new viper instance,
+// layer of each key and keys of secrets are returned as well.
+//
+// Secret references like ${env:NAME} are resolved after merged, and then values like ENC(BASE64CIPHERTEXT)
+// are decrypted with crypto entry if configured.
+func (entry *ConfigEntry) newViper() (*viper.Viper, map[string]string, map[string]bool, error) {
+	// fields are replaced by Reload concurrently
+	entry.lock.Lock()
+	layerPaths, path, content := entry.layerPaths(), entry.Path, entry.content
+	envPrefix, cryptoEntry, remote := entry.EnvPrefix, entry.cryptoEntry, entry.remote
+	entry.lock.Unlock()
+
+	// layers are merged into stage first
+	stage := viper.New()
+	vp := viper.New()
+	sources := map[string]string{}
+
+	for i, p := range layerPaths {
+		// skip layer if path is not valid
+		if !fileExists(p) {
+			continue
+		}
 
-// Interrupt entry.
-func (entry *ConfigEntry) Interrupt(context.Context) {}
+		layer := viper.New()
+		layer.SetConfigFile(p)
+		if err := layer.ReadInConfig(); err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to read file, path:%s, %v", p, err)
+		}
+
+		// keep ConfigFileUsed() of base file
+		if i == 0 && p == path {
+			vp.SetConfigFile(p)
+		}
+
+		if err := stage.MergeConfigMap(layer.AllSettings()); err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to merge file, path:%s, %v", p, err)
+		}
+
+		for _, k := range layer.AllKeys() {
+			sources[k] = ConfigSourceFile + ":" + p
+		}
+	}
+
+	// if remote config was fetched or read from cache, then merge into viper
+	if remote != nil {
+		layer, err := remote.layer()
+		if err != nil {
+			return nil, nil, nil, err
+		}
+
+		if layer != nil {
+			if err := stage.MergeConfigMap(layer.AllSettings()); err != nil {
+				return nil, nil, nil, fmt.Errorf("failed to merge remote config, url:%s, %v", remote.url, err)
+			}
+
+			for _, k := range layer.AllKeys() {
+				sources[k] = ConfigSourceRemote + ":" + remote.url
+			}
+		}
+	}
+
+	// if content exist, then merge into viper
+	if len(content) > 0 {
+		layer := viper.New()
+		for k, v := range content {
+			layer.Set(k, v)
+		}
+
+		if err := stage.MergeConfigMap(layer.AllSettings()); err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to merge content, %v", err)
+		}
+
+		for _, k := range layer.AllKeys() {
+			sources[k] = ConfigSourceContent
+		}
+	}
+
+	// keys of secrets are masked in history of config changes
+	secrets := map[string]bool{}
+	secretConfigKeys("", stage.AllSettings(), secrets)
+
+	settings, err := resolveSecretRefsInValue(entry.appCtx, "", stage.AllSettings())
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	decrypt, err := decryptValueFunc(entry.appCtx, cryptoEntry)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	if decrypt != nil {
+		if settings, err = mapStringsInValue("", settings, decrypt); err != nil {
+			return nil, nil, nil, err
+		}
+	}
+
+	if err := vp.MergeConfigMap(settings.(map[string]interface{})); err != nil {
+		return nil, nil, nil, err
+	}
+
+	// enable automatic env
+	// issue: https://github.com/rookie-ninja/rk-boot/issues/55
+	vp.AutomaticEnv()
+	vp.SetEnvPrefix(envPrefix)
+
+	return vp, sources, secrets, nil
+}
+
+// layerPaths returns config files in merge order of path, profile overlay and overlays, caller should hold lock.
+func (entry *ConfigEntry) layerPaths() []string {
+	res := make([]string, 0)
+
+	if len(entry.Path) > 0 {
+		res = append(res, entry.Path)
+
+		if len(entry.Profile) > 0 {
+			ext := filepath.Ext(entry.Path)
+			res = append(res, fmt.Sprintf("%s.%s%s", strings.TrimSuffix(entry.Path, ext), entry.Profile, ext))
+		}
+	}
+
+	return append(res, entry.Overlays...)
+}
+
+// Sources returns layer which supplied value of each key, keys are flattened and lowered as viper does.
+//
+// Layer would be one of file:<path>, remote:<url>, content or env:<name>.
+func (entry *ConfigEntry) Sources() map[string]string {
+	entry.lock.Lock()
+	vp, sources, envPrefix := entry.vp, entry.sources, entry.EnvPrefix
+	entry.lock.Unlock()
+
+	res := map[string]string{}
+	if vp == nil {
+		return res
+	}
+
+	for _, k := range vp.AllKeys() {
+		if v, ok := sources[k]; ok {
+			res[k] = v
+		}
+
+		// same as automatic env of viper
+		name := strings.ToUpper(k)
+		if len(envPrefix) > 0 {
+			name = strings.ToUpper(envPrefix + "_" + k)
+		}
+
+		if _, ok := os.LookupEnv(name); ok {
+			res[k] = ConfigSourceEnv + ":" + name
+		}
+	}
+
+	return res
+}
+
+// EffectiveValues returns current values with layer of each key, values of secrets are masked.
+//
+// Secrets are values with names like password and token, or values resolved from references like ${env:NAME} and ENC().
+func (entry *ConfigEntry) EffectiveValues() []*EffectiveValue {
+	entry.lock.Lock()
+	vp, secrets := entry.vp, entry.secrets
+	entry.lock.Unlock()
+
+	res := make([]*EffectiveValue, 0)
+	if vp == nil {
+		return res
+	}
+
+	sources := entry.Sources()
+	keys := vp.AllKeys()
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		value := &EffectiveValue{
+			Path:   k,
+			Value:  vp.Get(k),
+			Source: sources[k],
+		}
+
+		if isSecretBootPath(k) || secrets[k] {
+			value.Value = EffectiveMaskedValue
+			value.Masked = true
+		}
+
+		res = append(res, value)
+	}
+
+	return res
+}
+
+// Reload implements Reloadable, config file and content would be read again.
+//
+// Subscribers registered by OnChange will be notified, file watcher restarts if path or watch option changed,
+// remote endpoint is polled with new remote config.
+func (entry *ConfigEntry) Reload(_ context.Context, raw []byte) error {
+	newEntry, err := reloadedEntry[*ConfigEntry](entry.appCtx, raw, entry.GetName(), RegisterConfigEntryYAMLE)
+	if err != nil {
+		return err
+	}
+
+	// CertEntry of remote config source is looked up in current AppContext
+	if newEntry.remote != nil {
+		newEntry.remote.appCtx = entry.appCtx
+	}
+	if err := newEntry.loadPendingRemote(); err != nil {
+		return err
+	}
+
+	entry.lock.Lock()
+	restartWatch := entry.watchStop != nil &&
+		(!reflect.DeepEqual(entry.layerPaths(), newEntry.layerPaths()) || !newEntry.watch)
+	entry.Path = newEntry.Path
+	entry.Profile = newEntry.Profile
+	entry.Overlays = newEntry.Overlays
+	entry.EnvPrefix = newEntry.EnvPrefix
+	entry.content = newEntry.content
+	entry.watch = newEntry.watch
+	entry.watchDebounce = newEntry.watchDebounce
+	entry.cryptoEntry = newEntry.cryptoEntry
+	bootstrapped, watch := entry.bootstrapped, entry.watch
+	entry.lock.Unlock()
+
+	entry.stopPoll()
+
+	entry.lock.Lock()
+	entry.remote = newEntry.remote
+	entry.lock.Unlock()
+
+	entry.swapViper(newEntry.vp, newEntry.sources, newEntry.secrets)
+
+	if bootstrapped {
+		entry.startPoll()
+	}
+
+	if restartWatch {
+		entry.stopWatch()
+	}
+
+	if watch {
+		return entry.startWatch()
+	}
+
+	return nil
+}
 
 // GetName returns name of entry.
 func (entry *ConfigEntry) GetName() string {
@@ -160,13 +557,36 @@ func (entry *ConfigEntry) String() string {
 
 // MarshalJSON marshal entry.
 func (entry *ConfigEntry) MarshalJSON() ([]byte, error) {
+	entry.lock.Lock()
 	m := map[string]interface{}{
 		"name":        entry.GetName(),
 		"type":        entry.GetType(),
 		"description": entry.GetDescription(),
 		"locale":      entry.Locale,
 		"path":        entry.Path,
+		"profile":     entry.Profile,
+		"overlays":    entry.Overlays,
 		"envPrefix":   entry.EnvPrefix,
+		"watch":       entry.watch,
+		"reloadCount": entry.reloadCount,
+		"cryptoEntry": entry.cryptoEntry,
+	}
+	remote, lastReloadErr := entry.remote, entry.lastReloadErr
+	entry.lock.Unlock()
+
+	if remote != nil {
+		m["remote"] = map[string]interface{}{
+			"url":          remote.url,
+			"format":       remote.format,
+			"pollInterval": remote.pollInterval.String(),
+			"certEntry":    remote.certEntry,
+			"cachePath":    remote.cachePath,
+			"fromCache":    remote.isFromCache(),
+		}
+	}
+
+	if lastReloadErr != nil {
+		m["lastReloadError"] = lastReloadErr.Error()
 	}
 
 	return json.Marshal(m)
@@ -181,3 +601,17 @@ func (entry *ConfigEntry) UnmarshalJSON([]byte) error {
 func (entry *ConfigEntry) GetDescription() string {
 	return entry.entryDescription
 }
+
+// absConfigPath join relative path with working directory.
+func absConfigPath(p string) (string, error) {
+	if filepath.IsAbs(p) {
+		return p, nil
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.ToSlash(filepath.Join(wd, p)), nil
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"os"
 	"path/filepath"
+	"strconv"
 	"testing"
 )
 
@@ -292,3 +293,107 @@ func TestConfigEntry_Interrupt(t *testing.T) {
 	})
 	entry[0].Interrupt(context.Background())
 }
+
+func TestRegisterConfigEntry_WithLayers(t *testing.T) {
+	dir := t.TempDir()
+	basePath := filepath.ToSlash(filepath.Join(dir, "config.yaml"))
+	assert.Nil(t, os.WriteFile(basePath, []byte(`
+db:
+  host: localhost
+  port: 3306
+  user: base
+log: info
+`), os.ModePerm))
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "config.dev.yaml"), []byte(`
+db:
+  host: dev-host
+`), os.ModePerm))
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "config.local.yaml"), []byte(`
+db:
+  port: 3307
+`), os.ModePerm))
+
+	assert.Nil(t, os.Setenv("DOMAIN", "dev"))
+	defer os.Setenv("DOMAIN", "")
+	assert.Nil(t, os.Setenv("UT_LOG", "debug"))
+	defer os.Unsetenv("UT_LOG")
+
+	entries := RegisterConfigEntry(&BootConfig{
+		Config: []*BootConfigE{
+			{
+				Name:      "ut-config",
+				Path:      basePath,
+				Overlays:  []string{filepath.Join(dir, "config.local.yaml"), filepath.Join(dir, "not-exist.yaml")},
+				EnvPrefix: "ut",
+				Content: map[string]interface{}{
+					"db": map[interface{}]interface{}{
+						"user": "content",
+					},
+				},
+			},
+		},
+	}, WithAppCtx(NewAppContext()))
+	entry := entries[0]
+	assert.Equal(t, "dev", entry.Profile)
+	assert.Equal(t, basePath, entry.ConfigFileUsed())
+
+	// deep merged
+	assert.Equal(t, "dev-host", entry.GetString("db.host"))
+	assert.Equal(t, 3307, entry.GetInt("db.port"))
+	assert.Equal(t, "content", entry.GetString("db.user"))
+	assert.Equal(t, "debug", entry.GetString("log"))
+
+	sources := entry.Sources()
+	assert.Equal(t, "file:"+filepath.ToSlash(filepath.Join(dir, "config.dev.yaml")), filepath.ToSlash(sources["db.host"]))
+	assert.Equal(t, "file:"+filepath.ToSlash(filepath.Join(dir, "config.local.yaml")), filepath.ToSlash(sources["db.port"]))
+	assert.Equal(t, ConfigSourceContent, sources["db.user"])
+	assert.Equal(t, "env:UT_LOG", sources["log"])
+
+	// with explicit profile and invalid overlay
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "config.invalid.yaml"), []byte("db: [invalid"), os.ModePerm))
+	entries, err := RegisterConfigEntryE(&BootConfig{
+		Config: []*BootConfigE{
+			{
+				Name:    "ut-config",
+				Path:    basePath,
+				Profile: "invalid",
+			},
+		},
+	}, WithAppCtx(NewAppContext()))
+	assert.Empty(t, entries)
+	assert.NotNil(t, err)
+}
+
+func TestConfigEntry_ReadWhileReloading(t *testing.T) {
+	entry := RegisterConfigEntry(&BootConfig{
+		Config: []*BootConfigE{
+			{
+				Name:    "ut-config",
+				Content: map[string]interface{}{"key": "v0"},
+			},
+		},
+	}, WithAppCtx(NewAppContext()))[0]
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 20; i++ {
+			raw := "config:\n  - name: ut-config\n    content:\n      key: v" + strconv.Itoa(i) + "\n"
+			assert.Nil(t, entry.Reload(context.TODO(), []byte(raw)))
+		}
+	}()
+
+	// readers see either old or new viper
+	for {
+		select {
+		case <-done:
+			assert.Equal(t, "v19", entry.GetString("key"))
+			// deprecated field follows swapped viper
+			assert.Same(t, entry.GetViper(), entry.Viper)
+			return
+		default:
+			assert.Contains(t, entry.GetString("key"), "v")
+			assert.Equal(t, []string{"key"}, entry.AllKeys())
+		}
+	}
+}
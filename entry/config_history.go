@@ -0,0 +1,225 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkentry
+
+import (
+	"github.com/rookie-ninja/rk-query"
+	"go.uber.org/zap"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultConfigHistorySize is default number of config changes kept in AppContext
+	DefaultConfigHistorySize = 100
+
+	// configChangeOperation is operation of event emitted for config change
+	configChangeOperation = "configChange"
+)
+
+// WithConfigHistorySizeAppContext provide number of config changes kept in AppContext, oldest one is dropped if full.
+func WithConfigHistorySizeAppContext(size int) AppContextOption {
+	return func(ctx *AppContext) {
+		if size > 0 {
+			ctx.configHistory.size = size
+		}
+	}
+}
+
+// ConfigChange is a change of ConfigEntry at runtime.
+//
+// Keys are flattened and lowered as viper does, values of secrets are masked.
+// Removed keys are missing in NewValues and added keys are missing in OldValues.
+type ConfigChange struct {
+	Timestamp time.Time              `json:"timestamp" yaml:"timestamp" example:"2022-03-15T20:43:05+08:00"`
+	EntryName string                 `json:"entryName" yaml:"entryName" example:"my-config"`
+	Source    string                 `json:"source" yaml:"source" example:"file:/app/config.yaml"`
+	Keys      []string               `json:"keys" yaml:"keys"`
+	OldValues map[string]interface{} `json:"oldValues" yaml:"oldValues"`
+	NewValues map[string]interface{} `json:"newValues" yaml:"newValues"`
+}
+
+// configHistory is bounded history of config changes.
+type configHistory struct {
+	lock    sync.Mutex
+	size    int
+	changes []*ConfigChange
+}
+
+// newConfigHistory create configHistory with DefaultConfigHistorySize.
+func newConfigHistory() *configHistory {
+	return &configHistory{
+		size:    DefaultConfigHistorySize,
+		changes: make([]*ConfigChange, 0),
+	}
+}
+
+// add change and drop oldest ones if full.
+func (h *configHistory) add(change *ConfigChange) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	h.changes = append(h.changes, change)
+	if len(h.changes) > h.size {
+		h.changes = append([]*ConfigChange{}, h.changes[len(h.changes)-h.size:]...)
+	}
+}
+
+// list returns copy of changes, oldest first.
+func (h *configHistory) list() []*ConfigChange {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	return append([]*ConfigChange{}, h.changes...)
+}
+
+// AddConfigChange record config change in bounded history, and emit it as event with default EventEntry.
+//
+// Event is emitted only if EventEntry marked as default was registered, stdout is not used as fallback.
+func (ctx *AppContext) AddConfigChange(change *ConfigChange) {
+	if change == nil {
+		return
+	}
+
+	ctx.configHistory.add(change)
+
+	eventEntry, ok := GetDefault[*EventEntry](ctx)
+	if !ok {
+		return
+	}
+
+	event := eventEntry.CreateEvent(
+		rkquery.WithAppName(ctx.GetAppInfoEntry().AppName),
+		rkquery.WithAppVersion(ctx.GetAppInfoEntry().Version),
+		rkquery.WithEntryName(change.EntryName),
+		rkquery.WithEntryType(ConfigEntryType))
+	event.SetStartTime(change.Timestamp)
+	event.SetOperation(configChangeOperation)
+	event.AddPayloads(
+		zap.String("source", change.Source),
+		zap.Strings("keys", change.Keys),
+		zap.Any("oldValues", change.OldValues),
+		zap.Any("newValues", change.NewValues))
+	event.SetResCode("OK")
+	event.SetEndTime(time.Now())
+	event.Finish()
+}
+
+// ListConfigChanges returns config changes in history, oldest first.
+func (ctx *AppContext) ListConfigChanges() []*ConfigChange {
+	return ctx.configHistory.list()
+}
+
+// newConfigChange create ConfigChange with changed values of ConfigEntry.
+//
+// Source is joined layers of changed keys, values of keys which isSecret returns true are masked.
+func newConfigChange(entryName string, oldValues, newValues map[string]interface{},
+	oldSources, newSources map[string]string, isSecret func(key string, old bool) bool) *ConfigChange {
+	res := &ConfigChange{
+		Timestamp: time.Now(),
+		EntryName: entryName,
+		Keys:      make([]string, 0),
+		OldValues: map[string]interface{}{},
+		NewValues: map[string]interface{}{},
+	}
+
+	keys := map[string]bool{}
+	sources := map[string]bool{}
+	for k, v := range oldValues {
+		keys[k] = true
+		res.OldValues[k] = v
+		if isSecret(k, true) {
+			res.OldValues[k] = EffectiveMaskedValue
+		}
+
+		// source of removed key
+		if _, ok := newValues[k]; !ok {
+			sources[configSourceOrDefault(oldSources, k)] = true
+		}
+	}
+
+	for k, v := range newValues {
+		keys[k] = true
+		res.NewValues[k] = v
+		if isSecret(k, false) {
+			res.NewValues[k] = EffectiveMaskedValue
+		}
+		sources[configSourceOrDefault(newSources, k)] = true
+	}
+
+	for k := range keys {
+		res.Keys = append(res.Keys, k)
+	}
+	sort.Strings(res.Keys)
+
+	list := make([]string, 0, len(sources))
+	for k := range sources {
+		list = append(list, k)
+	}
+	sort.Strings(list)
+	res.Source = strings.Join(list, ",")
+
+	return res
+}
+
+// configSourceOrDefault returns layer of key, content would be returned if missing.
+func configSourceOrDefault(sources map[string]string, key string) string {
+	if v, ok := sources[key]; ok {
+		return v
+	}
+
+	return ConfigSourceContent
+}
+
+// secretConfigKeys returns flattened keys whose values contain secret references or encrypted values.
+//
+// Values are checked before resolved, so that resolved secrets could be masked in ConfigChange and EffectiveValues.
+func secretConfigKeys(prefix string, input interface{}, res map[string]bool) {
+	if m, ok := input.(map[string]interface{}); ok {
+		for k, v := range m {
+			key := k
+			if len(prefix) > 0 {
+				key = prefix + "." + k
+			}
+			secretConfigKeys(key, v, res)
+		}
+		return
+	}
+
+	if len(prefix) > 0 && hasSecretValue(input) {
+		res[prefix] = true
+	}
+}
+
+// hasSecretValue returns true if any string in value is secret reference or encrypted value.
+func hasSecretValue(input interface{}) bool {
+	switch v := input.(type) {
+	case string:
+		return secretRefRegex.MatchString(v) || IsEncryptedValue(v)
+	case []interface{}:
+		for i := range v {
+			if hasSecretValue(v[i]) {
+				return true
+			}
+		}
+	case map[string]interface{}:
+		for _, value := range v {
+			if hasSecretValue(value) {
+				return true
+			}
+		}
+	case map[interface{}]interface{}:
+		for _, value := range v {
+			if hasSecretValue(value) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
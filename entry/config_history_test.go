@@ -0,0 +1,133 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkentry
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAppContext_AddConfigChange(t *testing.T) {
+	appCtx := NewAppContext(WithConfigHistorySizeAppContext(2))
+	assert.Empty(t, appCtx.ListConfigChanges())
+
+	// with nil change
+	appCtx.AddConfigChange(nil)
+	assert.Empty(t, appCtx.ListConfigChanges())
+
+	// oldest one is dropped
+	for _, name := range []string{"ut-1", "ut-2", "ut-3"} {
+		appCtx.AddConfigChange(&ConfigChange{EntryName: name})
+	}
+
+	changes := appCtx.ListConfigChanges()
+	assert.Len(t, changes, 2)
+	assert.Equal(t, "ut-2", changes[0].EntryName)
+	assert.Equal(t, "ut-3", changes[1].EntryName)
+
+	// emitted with default EventEntry
+	eventPath := filepath.Join(t.TempDir(), "event.log")
+	eventEntry := RegisterEventEntry(&BootEvent{
+		Event: []*BootEventE{
+			{
+				Name:        "ut-event",
+				Default:     true,
+				OutputPaths: []string{eventPath},
+			},
+		},
+	}, WithAppCtx(appCtx))[0]
+	appCtx.AddConfigChange(&ConfigChange{EntryName: "ut-4", Source: "ut-source"})
+	eventEntry.Sync()
+
+	bytes, err := os.ReadFile(eventPath)
+	assert.Nil(t, err)
+	assert.Contains(t, string(bytes), configChangeOperation)
+	assert.Contains(t, string(bytes), "ut-source")
+}
+
+func TestConfigEntry_ConfigChange(t *testing.T) {
+	assert.Nil(t, os.Setenv("UT_CONFIG_SECRET", "ut-secret"))
+	defer os.Unsetenv("UT_CONFIG_SECRET")
+
+	// secret reference in file is resolved by ConfigEntry
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	assert.Nil(t, os.WriteFile(configPath, []byte("dsn: ${env:UT_CONFIG_SECRET}\n"), os.ModePerm))
+
+	raw := `
+config:
+  - name: ut-config
+    path: ` + configPath + `
+    content:
+      db:
+        port: 3306
+        password: ut-pass
+      removed: true
+`
+	appCtx := NewAppContext()
+	entries, err := RegisterConfigEntryYAMLE([]byte(raw), WithAppCtx(appCtx))
+	assert.Nil(t, err)
+	entry := entries["ut-config"].(*ConfigEntry)
+	assert.Equal(t, "ut-secret", entry.GetString("dsn"))
+
+	// values of secrets are masked
+	values := map[string]*EffectiveValue{}
+	for _, v := range entry.EffectiveValues() {
+		values[v.Path] = v
+	}
+	assert.Equal(t, 3306, values["db.port"].Value)
+	assert.Equal(t, ConfigSourceContent, values["db.port"].Source)
+	assert.True(t, values["db.password"].Masked)
+	assert.Equal(t, EffectiveMaskedValue, values["dsn"].Value)
+
+	// initial values are not recorded
+	assert.Empty(t, appCtx.ListConfigChanges())
+
+	newRaw := strings.ReplaceAll(raw, "3306", "3307")
+	newRaw = strings.ReplaceAll(newRaw, "ut-pass", "ut-new-pass")
+	newRaw = strings.ReplaceAll(newRaw, "removed: true\n", "")
+	assert.Nil(t, os.Setenv("UT_CONFIG_SECRET", "ut-new-secret"))
+	assert.Nil(t, entry.Reload(context.TODO(), []byte(newRaw)))
+
+	changes := appCtx.ListConfigChanges()
+	assert.Len(t, changes, 1)
+	change := changes[0]
+	assert.Equal(t, "ut-config", change.EntryName)
+	assert.Equal(t, ConfigSourceContent+","+ConfigSourceFile+":"+configPath, change.Source)
+	assert.Equal(t, []string{"db.password", "db.port", "dsn", "removed"}, change.Keys)
+	assert.Equal(t, map[string]interface{}{
+		"db.password": EffectiveMaskedValue,
+		"db.port":     3306,
+		"dsn":         EffectiveMaskedValue,
+		"removed":     true,
+	}, change.OldValues)
+	assert.Equal(t, map[string]interface{}{
+		"db.password": EffectiveMaskedValue,
+		"db.port":     3307,
+		"dsn":         EffectiveMaskedValue,
+	}, change.NewValues)
+
+	// nothing changed
+	assert.Nil(t, entry.Reload(context.TODO(), []byte(newRaw)))
+	assert.Len(t, appCtx.ListConfigChanges(), 1)
+}
+
+func TestSecretConfigKeys(t *testing.T) {
+	res := map[string]bool{}
+	secretConfigKeys("", map[string]interface{}{
+		"db": map[string]interface{}{
+			"user":  "ut",
+			"pass":  "ENC(dXQ=)",
+			"hosts": []interface{}{"localhost", "${file:/run/secrets/host}"},
+		},
+		"port": 3306,
+	}, res)
+
+	assert.Equal(t, map[string]bool{"db.pass": true, "db.hosts": true}, res)
+}
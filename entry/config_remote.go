@@ -0,0 +1,399 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkentry
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultConfigRemoteTimeout is default timeout of each request to remote config endpoint
+const defaultConfigRemoteTimeout = 5 * time.Second
+
+// BootConfigRemote is bootstrap config of remote config source of ConfigEntry.
+//
+// Config is fetched with GET in YAML or JSON, ETag returned by endpoint is sent back with If-None-Match
+// while polling. Fetched config is written into cachePath as last known good config, which is used
+// if endpoint is unreachable.
+type BootConfigRemote struct {
+	URL string `yaml:"url" json:"url"`
+	// Format is one of yaml and json, json if url ends with .json, yaml by default
+	Format string `yaml:"format" json:"format"`
+	// PollIntervalMs is interval of polling, polling is disabled if 0
+	PollIntervalMs int64 `yaml:"pollIntervalMs" json:"pollIntervalMs"`
+	TimeoutMs      int64 `yaml:"timeoutMs" json:"timeoutMs"`
+	// CertEntry is used for TLS or mutual TLS, config is fetched while bootstrapping if provided
+	CertEntry string `yaml:"certEntry" json:"certEntry"`
+	// BasicAuth is of the form user:pass
+	BasicAuth   string `yaml:"basicAuth" json:"basicAuth"`
+	BearerToken string `yaml:"bearerToken" json:"bearerToken"`
+	CachePath   string `yaml:"cachePath" json:"cachePath"`
+}
+
+// configRemote fetches config from HTTP endpoint and keeps last known good config.
+type configRemote struct {
+	url          string
+	format       string
+	pollInterval time.Duration
+	timeout      time.Duration
+	certEntry    string
+	basicAuth    string
+	bearerToken  string
+	cachePath    string
+	appCtx       *AppContext
+
+	lock      sync.Mutex
+	client    *http.Client
+	etag      string
+	body      []byte
+	fromCache bool
+	// pending is true before fetched from endpoint, which is deferred to bootstrap if CertEntry is used
+	pending bool
+}
+
+// newConfigRemote validate boot config and create configRemote.
+func newConfigRemote(boot *BootConfigRemote, appCtx *AppContext) (*configRemote, error) {
+	bootErr := &BootConfigError{}
+
+	u, err := url.Parse(boot.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || len(u.Host) < 1 {
+		bootErr.Add("url", fmt.Errorf("invalid url %s, expect http or https url", boot.URL))
+	}
+
+	remote := &configRemote{
+		url:          boot.URL,
+		format:       strings.ToLower(boot.Format),
+		pollInterval: time.Duration(boot.PollIntervalMs) * time.Millisecond,
+		timeout:      time.Duration(boot.TimeoutMs) * time.Millisecond,
+		certEntry:    boot.CertEntry,
+		basicAuth:    strings.TrimSpace(boot.BasicAuth),
+		bearerToken:  strings.TrimSpace(boot.BearerToken),
+		appCtx:       appCtx,
+		pending:      true,
+	}
+
+	if len(remote.format) < 1 {
+		remote.format = "yaml"
+		if u != nil && path.Ext(u.Path) == ".json" {
+			remote.format = "json"
+		}
+	}
+
+	if remote.format != "yaml" && remote.format != "json" {
+		bootErr.Add("format", fmt.Errorf("invalid format %s, expect yaml or json", boot.Format))
+	}
+
+	if remote.timeout <= 0 {
+		remote.timeout = defaultConfigRemoteTimeout
+	}
+
+	if len(remote.basicAuth) > 0 && !strings.Contains(remote.basicAuth, ":") {
+		bootErr.Add("basicAuth", errors.New("invalid basic auth, expect user:pass"))
+	}
+
+	if len(remote.basicAuth) > 0 && len(remote.bearerToken) > 0 {
+		bootErr.Add("bearerToken", errors.New("basicAuth and bearerToken could not be used together"))
+	}
+
+	if len(boot.CachePath) > 0 {
+		if remote.cachePath, err = absConfigPath(boot.CachePath); err != nil {
+			bootErr.Add("cachePath", err)
+		}
+	}
+
+	if err := bootErr.ErrorOrNil(); err != nil {
+		return nil, err
+	}
+
+	return remote, nil
+}
+
+// load fetch config from endpoint, cached config is used if failed.
+func (remote *configRemote) load() error {
+	_, err := remote.fetch()
+	if err == nil {
+		return nil
+	}
+
+	if cacheErr := remote.loadCache(); cacheErr != nil {
+		return fmt.Errorf("failed to fetch remote config, url:%s, %v, and no cache available, %v", remote.url, err, cacheErr)
+	}
+
+	LoggerEntryStdout.Warn("Failed to fetch remote config, failover to cache",
+		zap.String("url", remote.url),
+		zap.String("cachePath", remote.cachePath),
+		zap.Error(err))
+
+	return nil
+}
+
+// loadCache read last known good config from cachePath.
+func (remote *configRemote) loadCache() error {
+	if len(remote.cachePath) < 1 {
+		return errors.New("cachePath not configured")
+	}
+
+	body, err := os.ReadFile(remote.cachePath)
+	if err != nil {
+		return err
+	}
+
+	if _, err := remote.parse(body); err != nil {
+		return fmt.Errorf("invalid cache, path:%s, %v", remote.cachePath, err)
+	}
+
+	remote.lock.Lock()
+	defer remote.lock.Unlock()
+
+	remote.body = body
+	remote.fromCache = true
+
+	return nil
+}
+
+// fetch config from endpoint with If-None-Match, returns true if config changed.
+//
+// Current config is kept if failed.
+func (remote *configRemote) fetch() (bool, error) {
+	client, err := remote.httpClient()
+	if err != nil {
+		return false, err
+	}
+
+	req, err := http.NewRequest(http.MethodGet, remote.url, nil)
+	if err != nil {
+		return false, err
+	}
+
+	remote.lock.Lock()
+	etag := remote.etag
+	remote.lock.Unlock()
+
+	if len(etag) > 0 {
+		req.Header.Set("If-None-Match", etag)
+	}
+
+	if len(remote.basicAuth) > 0 {
+		tokens := strings.SplitN(remote.basicAuth, ":", 2)
+		req.SetBasicAuth(tokens[0], tokens[1])
+	}
+
+	if len(remote.bearerToken) > 0 {
+		req.Header.Set("Authorization", "Bearer "+remote.bearerToken)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified {
+		remote.lock.Lock()
+		defer remote.lock.Unlock()
+
+		remote.pending = false
+		return false, nil
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return false, err
+	}
+
+	// never take invalid config as last known good
+	if _, err := remote.parse(body); err != nil {
+		return false, err
+	}
+
+	remote.lock.Lock()
+	changed := !bytes.Equal(remote.body, body)
+	remote.etag = resp.Header.Get("ETag")
+	remote.body = body
+	remote.fromCache = false
+	remote.pending = false
+	remote.lock.Unlock()
+
+	if changed {
+		if err := remote.writeCache(body); err != nil {
+			LoggerEntryStdout.Warn("Failed to write remote config cache",
+				zap.String("cachePath", remote.cachePath), zap.Error(err))
+		}
+	}
+
+	return changed, nil
+}
+
+// writeCache write config into cachePath atomically.
+func (remote *configRemote) writeCache(body []byte) error {
+	if len(remote.cachePath) < 1 {
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(remote.cachePath), os.ModePerm); err != nil {
+		return err
+	}
+
+	tmp := remote.cachePath + ".tmp"
+	if err := os.WriteFile(tmp, body, 0600); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, remote.cachePath)
+}
+
+// httpClient returns client with certificate of CertEntry.
+func (remote *configRemote) httpClient() (*http.Client, error) {
+	remote.lock.Lock()
+	defer remote.lock.Unlock()
+
+	if remote.client != nil {
+		return remote.client, nil
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	if len(remote.certEntry) > 0 {
+		certEntry := remote.appCtx.GetCertEntry(remote.certEntry)
+		if certEntry == nil {
+			return nil, fmt.Errorf("cert entry %s not found", remote.certEntry)
+		}
+
+		// certificate is picked up on each handshake, so that reloaded one is used
+		transport.TLSClientConfig = certEntry.ClientTLSConfig()
+	}
+
+	remote.client = &http.Client{
+		Transport: transport,
+		Timeout:   remote.timeout,
+	}
+
+	return remote.client, nil
+}
+
+// parse config with format.
+func (remote *configRemote) parse(body []byte) (*viper.Viper, error) {
+	layer := viper.New()
+	layer.SetConfigType(remote.format)
+	if err := layer.ReadConfig(bytes.NewReader(body)); err != nil {
+		return nil, fmt.Errorf("failed to parse remote config as %s, %v", remote.format, err)
+	}
+
+	return layer, nil
+}
+
+// layer returns viper of current config, nil returned if nothing loaded yet.
+func (remote *configRemote) layer() (*viper.Viper, error) {
+	remote.lock.Lock()
+	body := remote.body
+	remote.lock.Unlock()
+
+	if body == nil {
+		return nil, nil
+	}
+
+	return remote.parse(body)
+}
+
+// isFromCache returns true if current config is read from cache.
+func (remote *configRemote) isFromCache() bool {
+	remote.lock.Lock()
+	defer remote.lock.Unlock()
+
+	return remote.fromCache
+}
+
+// isPending returns true if config is not fetched from endpoint yet.
+func (remote *configRemote) isPending() bool {
+	remote.lock.Lock()
+	defer remote.lock.Unlock()
+
+	return remote.pending
+}
+
+// startPoll start polling remote endpoint, nothing happens if already started or polling disabled.
+func (entry *ConfigEntry) startPoll() {
+	entry.lock.Lock()
+	defer entry.lock.Unlock()
+
+	if entry.pollStop != nil || entry.remote == nil || entry.remote.pollInterval <= 0 {
+		return
+	}
+
+	stop := make(chan struct{})
+	entry.pollStop = stop
+
+	go entry.pollLoop(entry.remote, stop)
+}
+
+// stopPoll stop polling remote endpoint.
+func (entry *ConfigEntry) stopPoll() {
+	entry.lock.Lock()
+	defer entry.lock.Unlock()
+
+	if entry.pollStop != nil {
+		close(entry.pollStop)
+		entry.pollStop = nil
+	}
+}
+
+// pollLoop fetch remote config periodically and reload if changed.
+func (entry *ConfigEntry) pollLoop(remote *configRemote, stop chan struct{}) {
+	ticker := time.NewTicker(remote.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			entry.pollRemote(remote)
+		}
+	}
+}
+
+// pollRemote fetch remote config once, keep last known good config if failed.
+func (entry *ConfigEntry) pollRemote(remote *configRemote) error {
+	changed, err := remote.fetch()
+	if err != nil {
+		LoggerEntryStdout.Warn("Failed to poll remote config, keep last known good config",
+			zap.String("entry", entry.GetName()),
+			zap.String("url", remote.url),
+			zap.Error(err))
+
+		entry.lock.Lock()
+		entry.lastReloadErr = err
+		entry.lock.Unlock()
+
+		return err
+	}
+
+	if !changed {
+		entry.lock.Lock()
+		entry.lastReloadErr = nil
+		entry.lock.Unlock()
+
+		return nil
+	}
+
+	return entry.reloadFile()
+}
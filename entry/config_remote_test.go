@@ -0,0 +1,191 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkentry
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// utConfigServer serves config with ETag and counts requests.
+type utConfigServer struct {
+	lock     sync.Mutex
+	body     string
+	etag     string
+	auth     string
+	notMatch int
+}
+
+func (s *utConfigServer) set(body, etag string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.body, s.etag = body, etag
+}
+
+func (s *utConfigServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if len(s.auth) > 0 && r.Header.Get("Authorization") != s.auth {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	if r.Header.Get("If-None-Match") == s.etag {
+		s.notMatch++
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("ETag", s.etag)
+	w.Write([]byte(s.body))
+}
+
+func TestRegisterConfigEntry_WithRemote(t *testing.T) {
+	handler := &utConfigServer{auth: "Bearer ut-token"}
+	handler.set("db:\n  host: v1\n  port: 3306\n", `"v1"`)
+	server := httptest.NewServer(handler)
+
+	cachePath := filepath.Join(t.TempDir(), "cache", "config.yaml")
+	boot := &BootConfig{
+		Config: []*BootConfigE{
+			{
+				Name: "ut-config",
+				Remote: &BootConfigRemote{
+					URL:         server.URL + "/config.yaml",
+					BearerToken: "ut-token",
+					CachePath:   cachePath,
+				},
+				Content: map[string]interface{}{
+					"db": map[string]interface{}{"port": 3307},
+				},
+			},
+		},
+	}
+
+	entries, err := RegisterConfigEntryE(boot, WithAppCtx(NewAppContext()))
+	assert.Nil(t, err)
+	entry := entries[0]
+	assert.Equal(t, "v1", entry.GetString("db.host"))
+	assert.Equal(t, 3307, entry.GetInt("db.port"))
+	assert.Equal(t, "remote:"+server.URL+"/config.yaml", entry.Sources()["db.host"])
+	assert.Equal(t, ConfigSourceContent, entry.Sources()["db.port"])
+	assert.NotContains(t, entry.String(), "ut-token")
+
+	// cache written
+	bytes, err := os.ReadFile(cachePath)
+	assert.Nil(t, err)
+	assert.Contains(t, string(bytes), "host: v1")
+
+	// changed
+	changes := make([]interface{}, 0)
+	entry.OnChange("db", func(oldValues, newValues map[string]interface{}) {
+		changes = append(changes, newValues["db.host"])
+	})
+	handler.set("db:\n  host: v2\n", `"v2"`)
+	assert.Nil(t, entry.pollRemote(entry.remote))
+	assert.Equal(t, "v2", entry.GetString("db.host"))
+	assert.Equal(t, []interface{}{"v2"}, changes)
+	assert.Equal(t, int64(1), entry.GetReloadCount())
+
+	// not modified
+	assert.Nil(t, entry.pollRemote(entry.remote))
+	assert.Equal(t, 1, handler.notMatch)
+	assert.Equal(t, int64(1), entry.GetReloadCount())
+
+	// endpoint is down, keep last known good config
+	server.Close()
+	assert.NotNil(t, entry.pollRemote(entry.remote))
+	assert.NotNil(t, entry.GetLastReloadError())
+	assert.Equal(t, "v2", entry.GetString("db.host"))
+
+	// failover to cache
+	entries, err = RegisterConfigEntryE(boot, WithAppCtx(NewAppContext()))
+	assert.Nil(t, err)
+	assert.Equal(t, "v2", entries[0].GetString("db.host"))
+	assert.True(t, entries[0].remote.isFromCache())
+
+	// without cache
+	boot.Config[0].Remote.CachePath = ""
+	entries, err = RegisterConfigEntryE(boot, WithAppCtx(NewAppContext()))
+	assert.Empty(t, entries)
+	assert.NotNil(t, err)
+
+	// with invalid remote config
+	entries, err = RegisterConfigEntryE(&BootConfig{
+		Config: []*BootConfigE{
+			{
+				Name: "ut-config",
+				Remote: &BootConfigRemote{
+					URL:         "ftp://localhost",
+					Format:      "toml",
+					BasicAuth:   "user",
+					BearerToken: "ut-token",
+				},
+			},
+		},
+	}, WithAppCtx(NewAppContext()))
+	assert.Empty(t, entries)
+	assert.Len(t, err.(*BootConfigError).Issues, 4)
+}
+
+func TestConfigEntry_RemoteWithCertEntry(t *testing.T) {
+	handler := &utConfigServer{}
+	handler.set(`{"db": {"host": "v1"}}`, `"v1"`)
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, pass, ok := r.BasicAuth(); !ok || user != "ut-user" || pass != "ut-pass" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		handler.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	appCtx := NewAppContext()
+	appCtx.AddEntry(&CertEntry{
+		entryName: "ut-cert",
+		entryType: CertEntryType,
+		RootCA:    server.Certificate(),
+	})
+
+	entries, err := RegisterConfigEntryE(&BootConfig{
+		Config: []*BootConfigE{
+			{
+				Name: "ut-config",
+				Remote: &BootConfigRemote{
+					URL:            server.URL + "/config.json",
+					CertEntry:      "ut-cert",
+					BasicAuth:      "ut-user:ut-pass",
+					PollIntervalMs: 10,
+				},
+			},
+		},
+	}, WithAppCtx(appCtx))
+	assert.Nil(t, err)
+	entry := entries[0]
+	assert.Equal(t, []EntryRef{{Type: CertEntryType, Name: "ut-cert"}}, entry.DependsOn())
+
+	// fetched while bootstrapping
+	assert.Empty(t, entry.GetString("db.host"))
+	assert.Nil(t, entry.BootstrapE(context.TODO()))
+	defer entry.Interrupt(context.TODO())
+	assert.Equal(t, "json", entry.remote.format)
+	assert.Equal(t, "v1", entry.GetString("db.host"))
+
+	// polling
+	handler.set(`{"db": {"host": "v2"}}`, `"v2"`)
+	assert.Eventually(t, func() bool {
+		return entry.GetReloadCount() > 0
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, "v2", entry.GetString("db.host"))
+}
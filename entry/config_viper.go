@@ -0,0 +1,163 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkentry
+
+import (
+	"github.com/spf13/viper"
+	"time"
+)
+
+// GetViper returns current viper instance.
+//
+// Viper is swapped as a whole while reloading, accessors of ConfigEntry read from the current one,
+// so that each call sees either the old or the new config. Values of returned viper are not changed by reload, call GetViper() again to read new config.
+// Values set on it are lost once config reloaded.
+func (entry *ConfigEntry) GetViper() *viper.Viper {
+	entry.lock.RLock()
+	defer entry.lock.RUnlock()
+
+	return entry.vp
+}
+
+// Get returns value of key.
+func (entry *ConfigEntry) Get(key string) interface{} {
+	return entry.GetViper().Get(key)
+}
+
+// GetString returns value of key as string.
+func (entry *ConfigEntry) GetString(key string) string {
+	return entry.GetViper().GetString(key)
+}
+
+// GetBool returns value of key as bool.
+func (entry *ConfigEntry) GetBool(key string) bool {
+	return entry.GetViper().GetBool(key)
+}
+
+// GetInt returns value of key as int.
+func (entry *ConfigEntry) GetInt(key string) int {
+	return entry.GetViper().GetInt(key)
+}
+
+// GetInt32 returns value of key as int32.
+func (entry *ConfigEntry) GetInt32(key string) int32 {
+	return entry.GetViper().GetInt32(key)
+}
+
+// GetInt64 returns value of key as int64.
+func (entry *ConfigEntry) GetInt64(key string) int64 {
+	return entry.GetViper().GetInt64(key)
+}
+
+// GetUint returns value of key as uint.
+func (entry *ConfigEntry) GetUint(key string) uint {
+	return entry.GetViper().GetUint(key)
+}
+
+// GetUint16 returns value of key as uint16.
+func (entry *ConfigEntry) GetUint16(key string) uint16 {
+	return entry.GetViper().GetUint16(key)
+}
+
+// GetUint32 returns value of key as uint32.
+func (entry *ConfigEntry) GetUint32(key string) uint32 {
+	return entry.GetViper().GetUint32(key)
+}
+
+// GetUint64 returns value of key as uint64.
+func (entry *ConfigEntry) GetUint64(key string) uint64 {
+	return entry.GetViper().GetUint64(key)
+}
+
+// GetFloat64 returns value of key as float64.
+func (entry *ConfigEntry) GetFloat64(key string) float64 {
+	return entry.GetViper().GetFloat64(key)
+}
+
+// GetTime returns value of key as time.Time.
+func (entry *ConfigEntry) GetTime(key string) time.Time {
+	return entry.GetViper().GetTime(key)
+}
+
+// GetDuration returns value of key as time.Duration.
+func (entry *ConfigEntry) GetDuration(key string) time.Duration {
+	return entry.GetViper().GetDuration(key)
+}
+
+// GetIntSlice returns value of key as slice of int.
+func (entry *ConfigEntry) GetIntSlice(key string) []int {
+	return entry.GetViper().GetIntSlice(key)
+}
+
+// GetStringSlice returns value of key as slice of string.
+func (entry *ConfigEntry) GetStringSlice(key string) []string {
+	return entry.GetViper().GetStringSlice(key)
+}
+
+// GetStringMap returns value of key as map.
+func (entry *ConfigEntry) GetStringMap(key string) map[string]interface{} {
+	return entry.GetViper().GetStringMap(key)
+}
+
+// GetStringMapString returns value of key as map of string.
+func (entry *ConfigEntry) GetStringMapString(key string) map[string]string {
+	return entry.GetViper().GetStringMapString(key)
+}
+
+// GetStringMapStringSlice returns value of key as map of string slice.
+func (entry *ConfigEntry) GetStringMapStringSlice(key string) map[string][]string {
+	return entry.GetViper().GetStringMapStringSlice(key)
+}
+
+// GetSizeInBytes returns size in bytes of value like 10MB.
+func (entry *ConfigEntry) GetSizeInBytes(key string) uint {
+	return entry.GetViper().GetSizeInBytes(key)
+}
+
+// IsSet returns true if key has value.
+func (entry *ConfigEntry) IsSet(key string) bool {
+	return entry.GetViper().IsSet(key)
+}
+
+// InConfig returns true if key exists in config.
+func (entry *ConfigEntry) InConfig(key string) bool {
+	return entry.GetViper().InConfig(key)
+}
+
+// Sub returns new viper of subtree under key.
+func (entry *ConfigEntry) Sub(key string) *viper.Viper {
+	return entry.GetViper().Sub(key)
+}
+
+// AllKeys returns all keys, keys are flattened and lowered, like db.port.
+func (entry *ConfigEntry) AllKeys() []string {
+	return entry.GetViper().AllKeys()
+}
+
+// AllSettings returns all values as nested map.
+func (entry *ConfigEntry) AllSettings() map[string]interface{} {
+	return entry.GetViper().AllSettings()
+}
+
+// ConfigFileUsed returns path of base config file.
+func (entry *ConfigEntry) ConfigFileUsed() string {
+	return entry.GetViper().ConfigFileUsed()
+}
+
+// UnmarshalKey decode value of key into rawVal.
+func (entry *ConfigEntry) UnmarshalKey(key string, rawVal interface{}, opts ...viper.DecoderConfigOption) error {
+	return entry.GetViper().UnmarshalKey(key, rawVal, opts...)
+}
+
+// Unmarshal decode all values into rawVal.
+func (entry *ConfigEntry) Unmarshal(rawVal interface{}, opts ...viper.DecoderConfigOption) error {
+	return entry.GetViper().Unmarshal(rawVal, opts...)
+}
+
+// UnmarshalExact decode all values into rawVal, error returned if any key is not decoded.
+func (entry *ConfigEntry) UnmarshalExact(rawVal interface{}, opts ...viper.DecoderConfigOption) error {
+	return entry.GetViper().UnmarshalExact(rawVal, opts...)
+}
@@ -0,0 +1,290 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkentry
+
+import (
+	"fmt"
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// defaultConfigWatchDebounce is default duration to wait for rapid writes to settle down
+const defaultConfigWatchDebounce = 500 * time.Millisecond
+
+// ConfigChangeFunc is called with changed keys of ConfigEntry.
+//
+// Keys are flattened and lowered as viper does, like db.port. Removed keys are missing in newValues
+// and added keys are missing in oldValues.
+type ConfigChangeFunc func(oldValues, newValues map[string]interface{})
+
+// configSubscriber is registered by ConfigEntry.OnChange
+type configSubscriber struct {
+	prefix string
+	fn     ConfigChangeFunc
+}
+
+// OnChange register function which would be called with changed keys under keyPrefix,
+// after config file reloaded by watcher or boot config reloaded.
+//
+// Empty keyPrefix subscribes every key.
+func (entry *ConfigEntry) OnChange(keyPrefix string, fn ConfigChangeFunc) {
+	if fn == nil {
+		return
+	}
+
+	entry.lock.Lock()
+	defer entry.lock.Unlock()
+
+	entry.subscribers = append(entry.subscribers, &configSubscriber{
+		prefix: strings.ToLower(keyPrefix),
+		fn:     fn,
+	})
+}
+
+// GetReloadCount returns times of config file reloaded successfully by watcher.
+func (entry *ConfigEntry) GetReloadCount() int64 {
+	entry.lock.Lock()
+	defer entry.lock.Unlock()
+
+	return entry.reloadCount
+}
+
+// GetLastReloadError returns error of last reload by watcher, nil returned if last reload succeeded.
+func (entry *ConfigEntry) GetLastReloadError() error {
+	entry.lock.Lock()
+	defer entry.lock.Unlock()
+
+	return entry.lastReloadErr
+}
+
+// startWatch start watching directories of config files, nothing happens if already started.
+//
+// Directory is watched instead of file so that atomic renames and symlink swaps of Kubernetes ConfigMap are caught.
+// Directories of overlays which do not exist are skipped.
+func (entry *ConfigEntry) startWatch() error {
+	entry.lock.Lock()
+	defer entry.lock.Unlock()
+
+	if entry.watchStop != nil || len(entry.Path) < 1 {
+		return nil
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+
+	paths := make([]string, 0)
+	dirs := map[string]bool{}
+	for _, p := range entry.layerPaths() {
+		p = filepath.Clean(p)
+		paths = append(paths, p)
+
+		dir := filepath.Dir(p)
+		if dirs[dir] {
+			continue
+		}
+		dirs[dir] = true
+
+		if err := watcher.Add(dir); err != nil && p == filepath.Clean(entry.Path) {
+			watcher.Close()
+			return fmt.Errorf("failed to watch config file, path:%s, %v", entry.Path, err)
+		}
+	}
+
+	stop := make(chan struct{})
+	entry.watchStop = stop
+
+	go entry.watchLoop(watcher, stop, paths, entry.watchDebounce)
+
+	return nil
+}
+
+// stopWatch stop watching config file.
+func (entry *ConfigEntry) stopWatch() {
+	entry.lock.Lock()
+	defer entry.lock.Unlock()
+
+	if entry.watchStop != nil {
+		close(entry.watchStop)
+		entry.watchStop = nil
+	}
+}
+
+// watchLoop reload config file after events settled down for debounce duration.
+func (entry *ConfigEntry) watchLoop(watcher *fsnotify.Watcher, stop chan struct{}, paths []string, debounce time.Duration) {
+	defer watcher.Close()
+
+	realPaths := make([]string, len(paths))
+	for i := range paths {
+		realPaths[i], _ = filepath.EvalSymlinks(paths[i])
+	}
+	var timer *time.Timer
+
+	for {
+		select {
+		case <-stop:
+			if timer != nil {
+				timer.Stop()
+			}
+			return
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+
+			changed := false
+			for i := range paths {
+				// symlink of file changed, like ..data of ConfigMap
+				currentPath, _ := filepath.EvalSymlinks(paths[i])
+				changed = changed || currentPath != realPaths[i] || filepath.Clean(event.Name) == paths[i]
+				realPaths[i] = currentPath
+			}
+
+			if !changed {
+				continue
+			}
+
+			if timer == nil {
+				timer = time.AfterFunc(debounce, func() {
+					entry.reloadFile()
+				})
+			} else {
+				timer.Reset(debounce)
+			}
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			LoggerEntryStdout.Warn("Failed to watch config file",
+				zap.String("entry", entry.GetName()), zap.Error(err))
+		}
+	}
+}
+
+// reloadFile read config files and remote config into a new viper and swap it if succeeded.
+func (entry *ConfigEntry) reloadFile() error {
+	var vp *viper.Viper
+	var sources map[string]string
+	var secrets map[string]bool
+
+	entry.lock.Lock()
+	path := entry.Path
+	entry.lock.Unlock()
+
+	err := fmt.Errorf("config file not found, path:%s", path)
+	if len(path) < 1 || fileExists(path) {
+		vp, sources, secrets, err = entry.newViper()
+	}
+
+	if err != nil {
+		LoggerEntryStdout.Warn("Failed to reload config file, keep previous config",
+			zap.String("entry", entry.GetName()), zap.Error(err))
+	} else {
+		entry.swapViper(vp, sources, secrets)
+	}
+
+	entry.lock.Lock()
+	defer entry.lock.Unlock()
+
+	entry.lastReloadErr = err
+	if err == nil {
+		entry.reloadCount++
+	}
+
+	return err
+}
+
+// swapViper replace viper with sources and notify subscribers with changed keys.
+//
+// Change is recorded in history of AppContext as well.
+func (entry *ConfigEntry) swapViper(vp *viper.Viper, sources map[string]string, secrets map[string]bool) {
+	entry.lock.Lock()
+	oldVp, oldSources, oldSecrets := entry.vp, entry.sources, entry.secrets
+	entry.vp = vp
+	entry.Viper = vp
+	entry.sources = sources
+	entry.secrets = secrets
+	subscribers := append([]*configSubscriber{}, entry.subscribers...)
+	entry.lock.Unlock()
+
+	oldValues, newValues := diffViper(oldVp, vp)
+	if len(oldValues) < 1 && len(newValues) < 1 {
+		return
+	}
+
+	if entry.appCtx != nil {
+		entry.appCtx.AddConfigChange(newConfigChange(entry.GetName(), oldValues, newValues, oldSources, sources,
+			func(key string, old bool) bool {
+				if old {
+					return isSecretBootPath(key) || oldSecrets[key]
+				}
+				return isSecretBootPath(key) || secrets[key]
+			}))
+	}
+
+	for _, sub := range subscribers {
+		oldSub, newSub := map[string]interface{}{}, map[string]interface{}{}
+		for k, v := range oldValues {
+			if hasKeyPrefix(k, sub.prefix) {
+				oldSub[k] = v
+			}
+		}
+		for k, v := range newValues {
+			if hasKeyPrefix(k, sub.prefix) {
+				newSub[k] = v
+			}
+		}
+
+		if len(oldSub) > 0 || len(newSub) > 0 {
+			sub.fn(oldSub, newSub)
+		}
+	}
+}
+
+// diffViper returns values of changed keys in old and new viper.
+func diffViper(oldVp, newVp *viper.Viper) (map[string]interface{}, map[string]interface{}) {
+	oldValues, newValues := map[string]interface{}{}, map[string]interface{}{}
+	oldSettings, newSettings := viperSettings(oldVp), viperSettings(newVp)
+
+	for k, v := range oldSettings {
+		if newV, ok := newSettings[k]; !ok || !reflect.DeepEqual(v, newV) {
+			oldValues[k] = v
+		}
+	}
+
+	for k, v := range newSettings {
+		if oldV, ok := oldSettings[k]; !ok || !reflect.DeepEqual(v, oldV) {
+			newValues[k] = v
+		}
+	}
+
+	return oldValues, newValues
+}
+
+// viperSettings returns flattened settings of viper.
+func viperSettings(vp *viper.Viper) map[string]interface{} {
+	res := map[string]interface{}{}
+	if vp == nil {
+		return res
+	}
+
+	for _, k := range vp.AllKeys() {
+		res[k] = vp.Get(k)
+	}
+
+	return res
+}
+
+// hasKeyPrefix returns true if key equals to prefix or is a child of prefix.
+func hasKeyPrefix(key, prefix string) bool {
+	return len(prefix) < 1 || key == prefix || strings.HasPrefix(key, prefix+".")
+}
@@ -0,0 +1,106 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkentry
+
+import (
+	"context"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestConfigEntry_Watch(t *testing.T) {
+	configPath := filepath.ToSlash(filepath.Join(t.TempDir(), "ut-watch.yaml"))
+	assert.Nil(t, os.WriteFile(configPath, []byte("db:\n  port: 3306\n  user: ut\nkey: value\n"), os.ModePerm))
+
+	entries := RegisterConfigEntry(&BootConfig{
+		Config: []*BootConfigE{
+			{
+				Name:            "ut-config",
+				Path:            configPath,
+				Watch:           true,
+				WatchDebounceMs: 50,
+			},
+		},
+	}, WithAppCtx(NewAppContext()))
+	entry := entries[0]
+
+	lock := sync.Mutex{}
+	var oldValues, newValues map[string]interface{}
+	entry.OnChange("db", func(o, n map[string]interface{}) {
+		lock.Lock()
+		defer lock.Unlock()
+		oldValues, newValues = o, n
+	})
+
+	assert.Nil(t, entry.BootstrapE(context.TODO()))
+	defer entry.Interrupt(context.TODO())
+
+	// rapid writes are debounced
+	for i := 0; i < 3; i++ {
+		assert.Nil(t, os.WriteFile(configPath, []byte(fmt.Sprintf("db:\n  port: %d\nkey: new\n", 3307+i)), os.ModePerm))
+	}
+
+	assert.Eventually(t, func() bool {
+		return entry.GetReloadCount() == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, 3309, entry.GetInt("db.port"))
+	assert.Equal(t, "new", entry.GetString("key"))
+	assert.Nil(t, entry.GetLastReloadError())
+
+	lock.Lock()
+	assert.Equal(t, map[string]interface{}{"db.port": 3306, "db.user": "ut"}, oldValues)
+	assert.Equal(t, map[string]interface{}{"db.port": 3309}, newValues)
+	lock.Unlock()
+
+	// invalid content is ignored
+	assert.Nil(t, os.WriteFile(configPath, []byte("db: [invalid"), os.ModePerm))
+	assert.Eventually(t, func() bool {
+		return entry.GetLastReloadError() != nil
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, 3309, entry.GetInt("db.port"))
+	assert.Equal(t, int64(1), entry.GetReloadCount())
+	assert.Contains(t, entry.String(), "lastReloadError")
+}
+
+func TestConfigEntry_OnChange(t *testing.T) {
+	entry := &ConfigEntry{}
+	entry.swapViper(nil, nil, nil)
+
+	called := 0
+	entry.OnChange("", nil)
+	entry.OnChange("db", func(o, n map[string]interface{}) {
+		called++
+	})
+
+	// without change under prefix
+	configPath := filepath.ToSlash(filepath.Join(t.TempDir(), "ut-watch.yaml"))
+	assert.Nil(t, os.WriteFile(configPath, []byte("key: value\n"), os.ModePerm))
+	entry.Path = configPath
+	assert.Nil(t, entry.reloadFile())
+	assert.Zero(t, called)
+
+	// with change under prefix
+	assert.Nil(t, os.WriteFile(configPath, []byte("key: value\ndb:\n  port: 3306\n"), os.ModePerm))
+	assert.Nil(t, entry.reloadFile())
+	assert.Equal(t, 1, called)
+
+	// with missing file
+	entry.Path = filepath.Join(t.TempDir(), "not-exist.yaml")
+	assert.NotNil(t, entry.reloadFile())
+	assert.Equal(t, int64(2), entry.GetReloadCount())
+}
+
+func TestHasKeyPrefix(t *testing.T) {
+	assert.True(t, hasKeyPrefix("db.port", ""))
+	assert.True(t, hasKeyPrefix("db.port", "db"))
+	assert.True(t, hasKeyPrefix("db", "db"))
+	assert.False(t, hasKeyPrefix("dbx.port", "db"))
+}
@@ -17,19 +17,7 @@ import (
 
 var (
 	// GlobalAppCtx global application context
-	GlobalAppCtx = &appContext{
-		startTime: time.Now(),
-		entries: map[string]map[string]Entry{
-			appInfoEntryType: {
-				appInfoEntryName: appInfoEntryDefault(),
-			},
-		},
-		embedFS:       map[string]map[string]*embed.FS{},
-		appInfoEntry:  appInfoEntryDefault(),
-		shutdownSig:   make(chan os.Signal),
-		shutdownHooks: make(map[string]ShutdownHook),
-		userValues:    make(map[string]interface{}),
-	}
+	GlobalAppCtx = NewAppContext()
 
 	builtinRegFuncList = []RegFunc{
 		registerAppInfoEntryYAML,
@@ -37,6 +25,15 @@ var (
 		RegisterEventEntryYAML,
 		RegisterConfigEntryYAML,
 		RegisterCertEntryYAML,
+		RegisterFeatureFlagEntryYAML,
+	}
+	builtinRegFuncEList = []RegFuncE{
+		registerAppInfoEntryYAMLE,
+		RegisterLoggerEntryYAMLE,
+		RegisterEventEntryYAMLE,
+		RegisterConfigEntryYAMLE,
+		RegisterCertEntryYAMLE,
+		RegisterFeatureFlagEntryYAMLE,
 	}
 	pluginRegFuncList   = make([]RegFunc, 0)
 	webFrameRegFuncList = make([]RegFunc, 0)
@@ -57,16 +54,25 @@ type LivenessCheck func(req *http.Request, resp http.ResponseWriter) bool
 // Init global app context with bellow fields.
 func init() {
 	signal.Notify(GlobalAppCtx.shutdownSig,
-		syscall.SIGHUP,
 		syscall.SIGINT,
 		syscall.SIGTERM,
 		syscall.SIGQUIT)
+
+	// SIGHUP shuts down application unless WatchReloadSig() is running
+	hupSig := make(chan os.Signal, 1)
+	signal.Notify(hupSig, syscall.SIGHUP)
+	go func() {
+		for sig := range hupSig {
+			GlobalAppCtx.dispatchHupSig(sig)
+		}
+	}()
 }
 
-// Application context which contains bellow fields.
-// It is not recommended override this value since StartTime would be assigned to current time
-// at beginning of go process in init() function.
-type appContext struct {
+// AppContext is application context which contains bellow fields.
+//
+// GlobalAppCtx is the default instance used by every registration function.
+// Use NewAppContext to create isolated instance, for example, one per tenant in tests.
+type AppContext struct {
 	startTime      time.Time                       `json:"-" yaml:"-"`
 	appInfoEntry   *appInfoEntry                   `json:"-" yaml:"-"`
 	readinessCheck ReadinessCheck                  `json:"-" yaml:"-"`
@@ -75,7 +81,122 @@ type appContext struct {
 	embedFS        map[string]map[string]*embed.FS `json:"-" yaml:"-"`
 	userValues     map[string]interface{}          `json:"-" yaml:"-"`
 	shutdownSig    chan os.Signal                  `json:"-" yaml:"-"`
-	shutdownHooks  map[string]ShutdownHook         `json:"-" yaml:"-"`
+	shutdownHooks  map[string]*shutdownHook        `json:"-" yaml:"-"`
+	lifecycle      *entryLifecycle                 `json:"-" yaml:"-"`
+	reloadSig      chan os.Signal                  `json:"-" yaml:"-"`
+	bootSource     BootSource                      `json:"-" yaml:"-"`
+	bootRaw        []byte                          `json:"-" yaml:"-"`
+	bootEffective  *EffectiveConfig                `json:"-" yaml:"-"`
+	configHistory  *configHistory                  `json:"-" yaml:"-"`
+	reloadWatchers int32                           `json:"-" yaml:"-"`
+
+	shutdownHookSeq     int           `json:"-" yaml:"-"`
+	shutdownBudget      time.Duration `json:"-" yaml:"-"`
+	shutdownHookTimeout time.Duration `json:"-" yaml:"-"`
+}
+
+// AppContextOption option for AppContext
+type AppContextOption func(*AppContext)
+
+// WithSignalsAppContext notify shutdown signal channel of AppContext with provided signals.
+//
+// Signals are not registered by default except GlobalAppCtx.
+func WithSignalsAppContext(sigs ...os.Signal) AppContextOption {
+	return func(ctx *AppContext) {
+		if len(sigs) > 0 {
+			signal.Notify(ctx.shutdownSig, sigs...)
+		}
+	}
+}
+
+// WithReloadSignalsAppContext notify reload signal channel of AppContext with provided signals.
+//
+// Signals are not registered by default. syscall.SIGHUP of GlobalAppCtx is sent to reload signal channel
+// while WatchReloadSig() is running, and to shutdown signal channel otherwise.
+func WithReloadSignalsAppContext(sigs ...os.Signal) AppContextOption {
+	return func(ctx *AppContext) {
+		if len(sigs) > 0 {
+			signal.Notify(ctx.reloadSig, sigs...)
+		}
+	}
+}
+
+// WithStartTimeAppContext provide start time of application.
+func WithStartTimeAppContext(startTime time.Time) AppContextOption {
+	return func(ctx *AppContext) {
+		ctx.startTime = startTime
+	}
+}
+
+// NewAppContext create a new AppContext which is isolated from GlobalAppCtx.
+func NewAppContext(opts ...AppContextOption) *AppContext {
+	ctx := &AppContext{
+		startTime: time.Now(),
+		entries: map[string]map[string]Entry{
+			appInfoEntryType: {
+				appInfoEntryName: appInfoEntryDefault(),
+			},
+		},
+		embedFS:       map[string]map[string]*embed.FS{},
+		appInfoEntry:  appInfoEntryDefault(),
+		shutdownSig:   make(chan os.Signal),
+		shutdownHooks: make(map[string]*shutdownHook),
+		userValues:    make(map[string]interface{}),
+		lifecycle:     newEntryLifecycle(),
+		reloadSig:     make(chan os.Signal, 1),
+		configHistory: newConfigHistory(),
+
+		shutdownBudget:      DefaultShutdownBudget,
+		shutdownHookTimeout: DefaultShutdownHookTimeout,
+	}
+
+	for i := range opts {
+		opts[i](ctx)
+	}
+
+	return ctx
+}
+
+// RegOption option for RegisterXxxEntry functions.
+type RegOption func(*RegOptions)
+
+// RegOptions contains options applied by RegOption.
+type RegOptions struct {
+	// AppCtx is AppContext which entries would be registered into, GlobalAppCtx by default
+	AppCtx *AppContext
+	// Strict validates boot config with ValidateBootYAML before entries are registered, false by default
+	Strict bool
+}
+
+// WithAppCtx register entries into provided AppContext instead of GlobalAppCtx.
+func WithAppCtx(ctx *AppContext) RegOption {
+	return func(opts *RegOptions) {
+		if ctx != nil {
+			opts.AppCtx = ctx
+		}
+	}
+}
+
+// WithStrictBootConfig validate boot config strictly with ValidateBootYAML before entries are registered.
+//
+// Every top level section should be declared by RegisterBootSchema, RegisterBootSection or RegisterEntryFactory.
+func WithStrictBootConfig() RegOption {
+	return func(opts *RegOptions) {
+		opts.Strict = true
+	}
+}
+
+// NewRegOptions apply RegOption list, GlobalAppCtx will be used if AppContext is not provided.
+func NewRegOptions(opts ...RegOption) *RegOptions {
+	res := &RegOptions{
+		AppCtx: GlobalAppCtx,
+	}
+
+	for i := range opts {
+		opts[i](res)
+	}
+
+	return res
 }
 
 // RegisterPluginRegFunc register rk plugins registration function.
@@ -139,55 +260,77 @@ func ListUserEntryRegFunc() []RegFunc {
 }
 
 // BootstrapBuiltInEntryFromYAML register and bootstrap builtin entries first
+//
+// Entries of each RegFunc are bootstrapped before the next RegFunc runs, in dependency order declared by EntryDependency.
 func BootstrapBuiltInEntryFromYAML(raw []byte) {
-	ctx := context.Background()
+	bootstrapFromYAML(raw, builtinRegFuncList)
+}
 
-	for i := range builtinRegFuncList {
-		entries := builtinRegFuncList[i](raw)
-		for _, v := range entries {
-			v.Bootstrap(ctx)
+// BootstrapBuiltInEntryFromYAMLE is the same as BootstrapBuiltInEntryFromYAML but returns error instead of panic.
+//
+// Problems of every builtin entry are collected into one *BootConfigError before any entry is bootstrapped,
+// so that unlike BootstrapBuiltInEntryFromYAML, every RegFuncE runs before the first entry is bootstrapped.
+// RegFuncE should not rely on entries registered by other RegFuncE being bootstrapped.
+//
+// Use WithAppCtx to register and bootstrap builtin entries into AppContext other than GlobalAppCtx.
+func BootstrapBuiltInEntryFromYAMLE(raw []byte, opts ...RegOption) error {
+	regOpts := NewRegOptions(opts...)
+	bootErr := &BootConfigError{}
+	entries := make([]Entry, 0)
+
+	if regOpts.Strict {
+		if err := ValidateBootYAML(raw); err != nil {
+			return err
 		}
 	}
+
+	regOpts.AppCtx.setBootRaw(raw)
+
+	for i := range builtinRegFuncEList {
+		res, err := builtinRegFuncEList[i](raw, opts...)
+		bootErr.Add("", err)
+		entries = append(entries, entryMapToList(res)...)
+	}
+
+	if err := bootErr.ErrorOrNil(); err != nil {
+		return err
+	}
+
+	return regOpts.AppCtx.BootstrapEntries(context.Background(), entries)
 }
 
 // BootstrapPluginEntryFromYAML register and bootstrap plugin entries first
+//
+// Entries of each RegFunc are bootstrapped before the next RegFunc runs, in dependency order declared by EntryDependency.
 func BootstrapPluginEntryFromYAML(raw []byte) {
-	ctx := context.Background()
-
-	for i := range pluginRegFuncList {
-		entries := pluginRegFuncList[i](raw)
-		for _, v := range entries {
-			v.Bootstrap(ctx)
-		}
-	}
+	bootstrapFromYAML(raw, pluginRegFuncList)
 }
 
 // BootstrapWebFrameEntryFromYAML register and bootstrap web framework entries first
+//
+// Entries of each RegFunc are bootstrapped before the next RegFunc runs, in dependency order declared by EntryDependency.
 func BootstrapWebFrameEntryFromYAML(raw []byte) {
-	ctx := context.Background()
-
-	for i := range webFrameRegFuncList {
-		entries := webFrameRegFuncList[i](raw)
-		for _, v := range entries {
-			v.Bootstrap(ctx)
-		}
-	}
+	bootstrapFromYAML(raw, webFrameRegFuncList)
 }
 
 // BootstrapUserEntryFromYAML register and bootstrap builtin entries first
+//
+// Entries of each RegFunc are bootstrapped before the next RegFunc runs, in dependency order declared by EntryDependency.
 func BootstrapUserEntryFromYAML(raw []byte) {
-	ctx := context.Background()
+	bootstrapFromYAML(raw, userDefRegFuncList)
+}
 
-	for i := range userDefRegFuncList {
-		entries := userDefRegFuncList[i](raw)
-		for _, v := range entries {
-			v.Bootstrap(ctx)
-		}
+// bootstrapFromYAML register and bootstrap entries RegFunc by RegFunc with dependency order
+func bootstrapFromYAML(raw []byte, regFuncList []RegFunc) {
+	GlobalAppCtx.setBootRaw(raw)
+
+	if err := GlobalAppCtx.bootstrapRegFuncs(context.Background(), raw, regFuncList); err != nil {
+		ShutdownWithError(err)
 	}
 }
 
 // AddEmbedFS add embed.FS based on name and type of Entry
-func (ctx *appContext) AddEmbedFS(entryType, entryName string, fs *embed.FS) {
+func (ctx *AppContext) AddEmbedFS(entryType, entryName string, fs *embed.FS) {
 	if len(entryType) < 1 || len(entryName) < 1 || fs == nil {
 		return
 	}
@@ -200,7 +343,7 @@ func (ctx *appContext) AddEmbedFS(entryType, entryName string, fs *embed.FS) {
 }
 
 // GetEmbedFS get embed.FS based on name and type of Entry
-func (ctx *appContext) GetEmbedFS(entryType, entryName string) *embed.FS {
+func (ctx *AppContext) GetEmbedFS(entryType, entryName string) *embed.FS {
 	if v, ok := ctx.embedFS[entryType]; !ok {
 		return nil
 	} else {
@@ -209,12 +352,12 @@ func (ctx *appContext) GetEmbedFS(entryType, entryName string) *embed.FS {
 }
 
 // SetReadinessCheck set readiness check function
-func (ctx *appContext) SetReadinessCheck(f ReadinessCheck) {
+func (ctx *AppContext) SetReadinessCheck(f ReadinessCheck) {
 	ctx.readinessCheck = f
 }
 
 // SetLivenessCheck set liveness check function
-func (ctx *appContext) SetLivenessCheck(f LivenessCheck) {
+func (ctx *AppContext) SetLivenessCheck(f LivenessCheck) {
 	ctx.livenessCheck = f
 }
 
@@ -222,28 +365,28 @@ func (ctx *appContext) SetLivenessCheck(f LivenessCheck) {
 // ****** User value related ******
 // ********************************
 
-// AddValue add value to GlobalAppCtx.
-func (ctx *appContext) AddValue(key string, value interface{}) {
+// AddValue add value to AppContext.
+func (ctx *AppContext) AddValue(key string, value interface{}) {
 	ctx.userValues[key] = value
 }
 
-// GetValue returns value from GlobalAppCtx.
-func (ctx *appContext) GetValue(key string) interface{} {
+// GetValue returns value from AppContext.
+func (ctx *AppContext) GetValue(key string) interface{} {
 	return ctx.userValues[key]
 }
 
-// ListValues list values from GlobalAppCtx.
-func (ctx *appContext) ListValues() map[string]interface{} {
+// ListValues list values from AppContext.
+func (ctx *AppContext) ListValues() map[string]interface{} {
 	return ctx.userValues
 }
 
-// RemoveValue remove value from GlobalAppCtx.
-func (ctx *appContext) RemoveValue(key string) {
+// RemoveValue remove value from AppContext.
+func (ctx *AppContext) RemoveValue(key string) {
 	delete(ctx.userValues, key)
 }
 
-// ClearValues clear values from GlobalAppCtx.
-func (ctx *appContext) ClearValues() {
+// ClearValues clear values from AppContext.
+func (ctx *AppContext) ClearValues() {
 	for k := range ctx.userValues {
 		delete(ctx.userValues, k)
 	}
@@ -253,11 +396,11 @@ func (ctx *appContext) ClearValues() {
 // ****** App info Entry related ******
 // ************************************
 
-func (ctx *appContext) GetAppInfoEntry() *appInfoEntry {
+func (ctx *AppContext) GetAppInfoEntry() *appInfoEntry {
 	return ctx.appInfoEntry
 }
 
-func (ctx *appContext) GetConfigEntry(entryName string) *ConfigEntry {
+func (ctx *AppContext) GetConfigEntry(entryName string) *ConfigEntry {
 	entries := ctx.entries[ConfigEntryType]
 
 	if v, ok := entries[entryName]; ok {
@@ -267,7 +410,7 @@ func (ctx *appContext) GetConfigEntry(entryName string) *ConfigEntry {
 	return nil
 }
 
-func (ctx *appContext) GetLoggerEntry(entryName string) *LoggerEntry {
+func (ctx *AppContext) GetLoggerEntry(entryName string) *LoggerEntry {
 	entries := ctx.entries[LoggerEntryType]
 
 	if v, ok := entries[entryName]; ok {
@@ -279,22 +422,15 @@ func (ctx *appContext) GetLoggerEntry(entryName string) *LoggerEntry {
 
 // GetLoggerEntryDefault returns LoggerEntry marked as default.
 // Return logger with STDOUT if no LoggerEntry was marked as default
-func (ctx *appContext) GetLoggerEntryDefault() *LoggerEntry {
-	res := LoggerEntryStdout
-
-	entries := ctx.entries[LoggerEntryType]
-
-	for _, v := range entries {
-		if v.(*LoggerEntry).IsDefault {
-			res = v.(*LoggerEntry)
-			break
-		}
+func (ctx *AppContext) GetLoggerEntryDefault() *LoggerEntry {
+	if res, ok := GetDefault[*LoggerEntry](ctx); ok {
+		return res
 	}
 
-	return res
+	return LoggerEntryStdout
 }
 
-func (ctx *appContext) GetEventEntry(entryName string) *EventEntry {
+func (ctx *AppContext) GetEventEntry(entryName string) *EventEntry {
 	entries := ctx.entries[EventEntryType]
 
 	if v, ok := entries[entryName]; ok {
@@ -306,22 +442,15 @@ func (ctx *appContext) GetEventEntry(entryName string) *EventEntry {
 
 // GetEventEntryDefault returns EventEntry marked as default.
 // Return logger with STDOUT if no EventEntry was marked as default
-func (ctx *appContext) GetEventEntryDefault() *EventEntry {
-	res := EventEntryStdout
-
-	entries := ctx.entries[EventEntryType]
-
-	for _, v := range entries {
-		if v.(*EventEntry).IsDefault {
-			res = v.(*EventEntry)
-			break
-		}
+func (ctx *AppContext) GetEventEntryDefault() *EventEntry {
+	if res, ok := GetDefault[*EventEntry](ctx); ok {
+		return res
 	}
 
-	return res
+	return EventEntryStdout
 }
 
-func (ctx *appContext) GetCertEntry(entryName string) *CertEntry {
+func (ctx *AppContext) GetCertEntry(entryName string) *CertEntry {
 	entries := ctx.entries[CertEntryType]
 
 	if v, ok := entries[entryName]; ok {
@@ -331,7 +460,17 @@ func (ctx *appContext) GetCertEntry(entryName string) *CertEntry {
 	return nil
 }
 
-func (ctx *appContext) AddEntry(entry Entry) {
+func (ctx *AppContext) GetFeatureFlagEntry(entryName string) *FeatureFlagEntry {
+	entries := ctx.entries[FeatureFlagEntryType]
+
+	if v, ok := entries[entryName]; ok {
+		return v.(*FeatureFlagEntry)
+	}
+
+	return nil
+}
+
+func (ctx *AppContext) AddEntry(entry Entry) {
 	if entry == nil {
 		return
 	}
@@ -343,13 +482,17 @@ func (ctx *appContext) AddEntry(entry Entry) {
 	} else {
 		v[entry.GetName()] = entry
 	}
+
+	// entry may be added again, ignore invalid transition
+	ctx.SetEntryState(entry, EntryStateRegistered, nil)
 }
 
-func (ctx *appContext) clearEntries() {
+func (ctx *AppContext) clearEntries() {
 	ctx.entries = map[string]map[string]Entry{}
+	ctx.lifecycle = newEntryLifecycle()
 }
 
-func (ctx *appContext) GetEntry(entryType, entryName string) Entry {
+func (ctx *AppContext) GetEntry(entryType, entryName string) Entry {
 	if v, ok := ctx.entries[entryType]; ok {
 		return v[entryName]
 	}
@@ -357,7 +500,7 @@ func (ctx *appContext) GetEntry(entryType, entryName string) Entry {
 	return nil
 }
 
-func (ctx *appContext) RemoveEntry(entry Entry) {
+func (ctx *AppContext) RemoveEntry(entry Entry) {
 	if entry == nil {
 		return
 	}
@@ -365,13 +508,21 @@ func (ctx *appContext) RemoveEntry(entry Entry) {
 	if v, ok := ctx.entries[entry.GetType()]; ok {
 		delete(v, entry.GetName())
 	}
+
+	ref := refOf(entry)
+	ctx.removeEntryStatus(func(v EntryRef) bool {
+		return v == ref
+	})
 }
 
-func (ctx *appContext) RemoveEntryByType(entryType string) {
+func (ctx *AppContext) RemoveEntryByType(entryType string) {
 	delete(ctx.entries, entryType)
+	ctx.removeEntryStatus(func(v EntryRef) bool {
+		return v.Type == entryType
+	})
 }
 
-func (ctx *appContext) ListEntriesByType(entryType string) map[string]Entry {
+func (ctx *AppContext) ListEntriesByType(entryType string) map[string]Entry {
 	if v, ok := ctx.entries[entryType]; ok {
 		return v
 	}
@@ -379,11 +530,11 @@ func (ctx *appContext) ListEntriesByType(entryType string) map[string]Entry {
 	return map[string]Entry{}
 }
 
-func (ctx *appContext) ListEntries() map[string]map[string]Entry {
+func (ctx *AppContext) ListEntries() map[string]map[string]Entry {
 	return ctx.entries
 }
 
-func (ctx *appContext) GetSignerJwtEntry(entryName string) SignerJwt {
+func (ctx *AppContext) GetSignerJwtEntry(entryName string) SignerJwt {
 	if v := ctx.GetEntry(SignerJwtEntryType, entryName); v != nil {
 		if res, ok := v.(SignerJwt); ok {
 			return res
@@ -393,7 +544,7 @@ func (ctx *appContext) GetSignerJwtEntry(entryName string) SignerJwt {
 	return nil
 }
 
-func (ctx *appContext) GetCryptoEntry(entryName string) Crypto {
+func (ctx *AppContext) GetCryptoEntry(entryName string) Crypto {
 	if v := ctx.GetEntry(CryptoEntryType, entryName); v != nil {
 		if res, ok := v.(Crypto); ok {
 			return res
@@ -408,37 +559,53 @@ func (ctx *appContext) GetCryptoEntry(entryName string) Crypto {
 // ***********************************
 
 // GetUpTime returns uptime of application from StartTime.
-func (ctx *appContext) GetUpTime() time.Duration {
+func (ctx *AppContext) GetUpTime() time.Duration {
 	return time.Since(ctx.startTime)
 }
 
 // GetStartTime returns start time of application.
-func (ctx *appContext) GetStartTime() time.Time {
+func (ctx *AppContext) GetStartTime() time.Time {
 	return ctx.startTime
 }
 
 // AddShutdownHook add shutdown hook with name.
-func (ctx *appContext) AddShutdownHook(name string, f ShutdownHook) {
+//
+// Hook runs in ShutdownPhaseCloseResources with default priority and deadline, use AddShutdownHookE for more control.
+func (ctx *AppContext) AddShutdownHook(name string, f ShutdownHook) {
 	if f == nil {
 		return
 	}
-	ctx.shutdownHooks[name] = f
+
+	ctx.addShutdownHook(&shutdownHook{
+		name:   name,
+		phase:  ShutdownPhaseCloseResources,
+		legacy: f,
+	})
 }
 
 // GetShutdownHook returns shutdown hook with name.
-func (ctx *appContext) GetShutdownHook(name string) ShutdownHook {
-	return ctx.shutdownHooks[name]
+func (ctx *AppContext) GetShutdownHook(name string) ShutdownHook {
+	if v, ok := ctx.shutdownHooks[name]; ok {
+		return v.toShutdownHook()
+	}
+
+	return nil
 }
 
 // ListShutdownHooks list shutdown hooks.
-func (ctx *appContext) ListShutdownHooks() map[string]ShutdownHook {
-	return ctx.shutdownHooks
+func (ctx *AppContext) ListShutdownHooks() map[string]ShutdownHook {
+	res := make(map[string]ShutdownHook, len(ctx.shutdownHooks))
+	for k, v := range ctx.shutdownHooks {
+		res[k] = v.toShutdownHook()
+	}
+
+	return res
 }
 
 // RemoveShutdownHook remove shutdown hook.
-func (ctx *appContext) RemoveShutdownHook(name string) bool {
-	if _, ok := GlobalAppCtx.shutdownHooks[name]; ok {
-		delete(GlobalAppCtx.shutdownHooks, name)
+func (ctx *AppContext) RemoveShutdownHook(name string) bool {
+	if _, ok := ctx.shutdownHooks[name]; ok {
+		delete(ctx.shutdownHooks, name)
 		return true
 	}
 
@@ -446,7 +613,7 @@ func (ctx *appContext) RemoveShutdownHook(name string) bool {
 }
 
 // Internal use only.
-func (ctx *appContext) clearShutdownHooks() {
+func (ctx *AppContext) clearShutdownHooks() {
 	for k := range ctx.shutdownHooks {
 		delete(ctx.shutdownHooks, k)
 	}
@@ -457,11 +624,13 @@ func (ctx *appContext) clearShutdownHooks() {
 // *************************************
 
 // WaitForShutdownSig waits for shutdown signal.
-func (ctx *appContext) WaitForShutdownSig() {
+//
+// Shutdown hooks and entries are not touched, call Shutdown() afterwards or use WaitForShutdownSigAndShutdown().
+func (ctx *AppContext) WaitForShutdownSig() {
 	<-ctx.shutdownSig
 }
 
 // GetShutdownSig returns shutdown signal.
-func (ctx *appContext) GetShutdownSig() chan os.Signal {
+func (ctx *AppContext) GetShutdownSig() chan os.Signal {
 	return ctx.shutdownSig
 }
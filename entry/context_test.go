@@ -49,6 +49,79 @@ cert:
 	assert.Nil(t, GlobalAppCtx.GetCertEntry("ut-cert-1"))
 }
 
+func TestNewAppContext(t *testing.T) {
+	ctx := NewAppContext(WithStartTimeAppContext(time.Unix(0, 0)))
+	assert.NotEqual(t, GlobalAppCtx, ctx)
+	assert.Equal(t, time.Unix(0, 0), ctx.GetStartTime())
+	assert.NotNil(t, ctx.GetAppInfoEntry())
+	assert.NotNil(t, ctx.GetShutdownSig())
+	assert.Empty(t, ctx.ListShutdownHooks())
+	assert.Empty(t, ctx.ListValues())
+}
+
+func TestNewAppContext_Isolation(t *testing.T) {
+	defer GlobalAppCtx.clearEntries()
+
+	bootStr := `
+---
+app:
+  name: ut-tenant
+config:
+  - name: ut-config
+logger:
+  - name: ut-logger
+event:
+  - name: ut-event
+cert:
+  - name: ut-cert
+`
+	raw := []byte(bootStr)
+	tenantA, tenantB := NewAppContext(), NewAppContext()
+
+	_, err := RegisterConfigEntryYAMLE(raw, WithAppCtx(tenantA))
+	assert.Nil(t, err)
+	_, err = RegisterLoggerEntryYAMLE(raw, WithAppCtx(tenantA))
+	assert.Nil(t, err)
+	_, err = RegisterEventEntryYAMLE(raw, WithAppCtx(tenantA))
+	assert.Nil(t, err)
+	_, err = RegisterCertEntryYAMLE(raw, WithAppCtx(tenantA))
+	assert.Nil(t, err)
+	_, err = registerAppInfoEntryYAMLE(raw, WithAppCtx(tenantA))
+	assert.Nil(t, err)
+
+	// registered into tenantA only
+	assert.NotNil(t, tenantA.GetConfigEntry("ut-config"))
+	assert.NotNil(t, tenantA.GetLoggerEntry("ut-logger"))
+	assert.NotNil(t, tenantA.GetEventEntry("ut-event"))
+	assert.NotNil(t, tenantA.GetCertEntry("ut-cert"))
+	assert.Equal(t, "ut-tenant", tenantA.GetAppInfoEntry().AppName)
+
+	assert.Nil(t, tenantB.GetConfigEntry("ut-config"))
+	assert.Nil(t, tenantB.GetLoggerEntry("ut-logger"))
+	assert.NotEqual(t, "ut-tenant", tenantB.GetAppInfoEntry().AppName)
+
+	assert.Nil(t, GlobalAppCtx.GetConfigEntry("ut-config"))
+	assert.Nil(t, GlobalAppCtx.GetCertEntry("ut-cert"))
+	assert.NotEqual(t, "ut-tenant", GlobalAppCtx.GetAppInfoEntry().AppName)
+
+	// with nil AppContext, fallback to GlobalAppCtx
+	_, err = RegisterConfigEntryYAMLE(raw, WithAppCtx(nil))
+	assert.Nil(t, err)
+	assert.NotNil(t, GlobalAppCtx.GetConfigEntry("ut-config"))
+}
+
+func TestBootstrapBuiltInEntryFromYAMLE_WithAppCtx(t *testing.T) {
+	bootStr := `
+---
+logger:
+  - name: ut-logger
+`
+	ctx := NewAppContext()
+	assert.Nil(t, BootstrapBuiltInEntryFromYAMLE([]byte(bootStr), WithAppCtx(ctx)))
+	assert.NotNil(t, ctx.GetLoggerEntry("ut-logger"))
+	assert.Nil(t, GlobalAppCtx.GetLoggerEntry("ut-logger"))
+}
+
 func TestAppContext_RemoveEntryByType(t *testing.T) {
 	defer GlobalAppCtx.clearEntries()
 
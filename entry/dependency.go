@@ -0,0 +1,308 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkentry
+
+import (
+	"context"
+	"fmt"
+	"go.uber.org/zap"
+	"sort"
+	"strings"
+)
+
+// SortEntries sort entries with dependency order declared by EntryDependency.
+//
+// Entries which depends on others will be placed after them, entries without dependency relationship
+// keep the same order as input. Dependencies missing in input are ignored since they are
+// either bootstrapped already or not registered at all.
+//
+// An error will be returned if there is a dependency cycle.
+func SortEntries(entries []Entry) ([]Entry, error) {
+	index := make(map[EntryRef]Entry)
+	for i := range entries {
+		if entries[i] == nil {
+			continue
+		}
+		index[refOf(entries[i])] = entries[i]
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+
+	res := make([]Entry, 0, len(index))
+	state := make(map[EntryRef]int)
+	path := make([]EntryRef, 0)
+
+	var visit func(ref EntryRef) error
+	visit = func(ref EntryRef) error {
+		switch state[ref] {
+		case visited:
+			return nil
+		case visiting:
+			// find beginning of cycle in current path
+			cycle := make([]string, 0)
+			for i := range path {
+				if path[i] == ref || len(cycle) > 0 {
+					cycle = append(cycle, path[i].String())
+				}
+			}
+			cycle = append(cycle, ref.String())
+			return fmt.Errorf("dependency cycle detected, %s", strings.Join(cycle, " -> "))
+		}
+
+		state[ref] = visiting
+		path = append(path, ref)
+
+		entry := index[ref]
+		if v, ok := entry.(EntryDependency); ok {
+			for _, dep := range v.DependsOn() {
+				if _, ok := index[dep]; !ok {
+					continue
+				}
+
+				if err := visit(dep); err != nil {
+					return err
+				}
+			}
+		}
+
+		path = path[:len(path)-1]
+		state[ref] = visited
+		res = append(res, entry)
+		return nil
+	}
+
+	for i := range entries {
+		if entries[i] == nil {
+			continue
+		}
+
+		if err := visit(refOf(entries[i])); err != nil {
+			return nil, err
+		}
+	}
+
+	return res, nil
+}
+
+// BootstrapEntries bootstrap entries in dependency order.
+//
+// BootstrapE will be called if entry implements EntryE and bootstrap stops at first failure.
+// Lifecycle state of entries will be changed to Bootstrapping, then Running or Failed.
+func (ctx *AppContext) BootstrapEntries(c context.Context, entries []Entry) error {
+	sorted, err := SortEntries(entries)
+	if err != nil {
+		return err
+	}
+
+	ctx.warnMissingDependencies(sorted)
+
+	for i := range sorted {
+		if err := ctx.bootstrapEntry(c, sorted[i]); err != nil {
+			return fmt.Errorf("failed to bootstrap %s, %w", refOf(sorted[i]), err)
+		}
+	}
+
+	return nil
+}
+
+// InterruptEntries interrupt entries in reverse dependency order.
+//
+// Lifecycle state of entries will be changed to Stopping, then Stopped.
+func (ctx *AppContext) InterruptEntries(c context.Context, entries []Entry) error {
+	sorted, err := SortEntries(entries)
+	if err != nil {
+		return err
+	}
+
+	for i := len(sorted) - 1; i >= 0; i-- {
+		ctx.interruptEntry(c, sorted[i])
+	}
+
+	return nil
+}
+
+// bootstrapRegFuncs register and bootstrap entries RegFunc by RegFunc.
+//
+// Entries registered by a RegFunc are bootstrapped before the next RegFunc runs, so that RegFunc could read
+// state of entries bootstrapped earlier, like default LoggerEntry. Entries which depend on entries not bootstrapped yet
+// are deferred until dependencies are registered by a later RegFunc, and bootstrapped at last if never registered.
+func (ctx *AppContext) bootstrapRegFuncs(c context.Context, raw []byte, regFuncList []RegFunc) error {
+	pending := make([]Entry, 0)
+
+	for i := range regFuncList {
+		pending = append(pending, entryMapToList(regFuncList[i](raw))...)
+
+		ready, deferred := ctx.splitByDependency(pending)
+		if err := ctx.BootstrapEntries(c, ready); err != nil {
+			return err
+		}
+		pending = deferred
+	}
+
+	return ctx.BootstrapEntries(c, pending)
+}
+
+// splitByDependency split entries into ones whose dependencies are bootstrapped or in entries,
+// and ones which depend on entries not registered yet.
+func (ctx *AppContext) splitByDependency(entries []Entry) ([]Entry, []Entry) {
+	index := make(map[EntryRef]bool)
+	for i := range entries {
+		index[refOf(entries[i])] = true
+	}
+
+	// entries depend on deferred ones are deferred as well
+	deferred := make(map[EntryRef]bool)
+	for changed := true; changed; {
+		changed = false
+
+		for i := range entries {
+			ref := refOf(entries[i])
+			v, ok := entries[i].(EntryDependency)
+			if deferred[ref] || !ok {
+				continue
+			}
+
+			for _, dep := range v.DependsOn() {
+				if (index[dep] && !deferred[dep]) || ctx.isEntryBootstrapped(dep) {
+					continue
+				}
+
+				deferred[ref] = true
+				changed = true
+				break
+			}
+		}
+	}
+
+	ready, rest := make([]Entry, 0), make([]Entry, 0)
+	for i := range entries {
+		if deferred[refOf(entries[i])] {
+			rest = append(rest, entries[i])
+		} else {
+			ready = append(ready, entries[i])
+		}
+	}
+
+	return ready, rest
+}
+
+// isEntryBootstrapped returns true if entry was bootstrapped through AppContext.
+func (ctx *AppContext) isEntryBootstrapped(ref EntryRef) bool {
+	status := ctx.GetEntryStatus(ref.Type, ref.Name)
+	return status != nil && (status.State == EntryStateRunning || status.State == EntryStateDegraded)
+}
+
+// warnMissingDependencies log warning for dependencies which are neither in entries nor bootstrapped,
+// entries would be bootstrapped without them.
+func (ctx *AppContext) warnMissingDependencies(entries []Entry) {
+	index := make(map[EntryRef]bool)
+	for i := range entries {
+		index[refOf(entries[i])] = true
+	}
+
+	for i := range entries {
+		v, ok := entries[i].(EntryDependency)
+		if !ok {
+			continue
+		}
+
+		for _, dep := range v.DependsOn() {
+			if !index[dep] && !ctx.isEntryBootstrapped(dep) {
+				LoggerEntryStdout.Warn("Dependency of entry is not bootstrapped",
+					zap.String("entry", refOf(entries[i]).String()),
+					zap.String("dependency", dep.String()))
+			}
+		}
+	}
+}
+
+// bootstrapEntry bootstrap entry and track lifecycle state, state will be Failed if Bootstrap panics.
+func (ctx *AppContext) bootstrapEntry(c context.Context, entry Entry) (err error) {
+	ctx.SetEntryState(entry, EntryStateBootstrapping, nil)
+
+	defer func() {
+		if r := recover(); r != nil {
+			ctx.SetEntryState(entry, EntryStateFailed, fmt.Errorf("panic while bootstrapping, %v", r))
+			panic(r)
+		}
+	}()
+
+	if v, ok := entry.(EntryE); ok {
+		err = v.BootstrapE(c)
+	} else {
+		entry.Bootstrap(c)
+	}
+
+	if err != nil {
+		ctx.SetEntryState(entry, EntryStateFailed, err)
+		return err
+	}
+
+	// entry may report Degraded by itself while bootstrapping
+	if status := ctx.GetEntryStatus(entry.GetType(), entry.GetName()); status == nil || status.State != EntryStateDegraded {
+		ctx.SetEntryState(entry, EntryStateRunning, nil)
+	}
+
+	return nil
+}
+
+// interruptEntry interrupt entry and track lifecycle state, state will be Failed if Interrupt panics.
+func (ctx *AppContext) interruptEntry(c context.Context, entry Entry) {
+	ctx.SetEntryState(entry, EntryStateStopping, nil)
+
+	defer func() {
+		if r := recover(); r != nil {
+			ctx.SetEntryState(entry, EntryStateFailed, fmt.Errorf("panic while interrupting, %v", r))
+			panic(r)
+		}
+	}()
+
+	entry.Interrupt(c)
+	ctx.SetEntryState(entry, EntryStateStopped, nil)
+}
+
+// ListEntriesSorted list all entries in AppContext with dependency order.
+func (ctx *AppContext) ListEntriesSorted() ([]Entry, error) {
+	res := make([]Entry, 0)
+	for _, v := range ctx.entries {
+		res = append(res, entryMapToList(v)...)
+	}
+
+	// make sure order is stable before sorting with dependencies
+	sort.SliceStable(res, func(i, j int) bool {
+		return res[i].GetType() < res[j].GetType()
+	})
+
+	return SortEntries(res)
+}
+
+// refOf returns EntryRef of entry.
+func refOf(entry Entry) EntryRef {
+	return EntryRef{
+		Type: entry.GetType(),
+		Name: entry.GetName(),
+	}
+}
+
+// entryMapToList convert map returned from RegFunc into list ordered by name.
+func entryMapToList(entries map[string]Entry) []Entry {
+	names := make([]string, 0, len(entries))
+	for k := range entries {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	res := make([]Entry, 0, len(names))
+	for i := range names {
+		res = append(res, entries[names[i]])
+	}
+
+	return res
+}
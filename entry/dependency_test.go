@@ -0,0 +1,156 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkentry
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestSortEntries(t *testing.T) {
+	a := &dependentEntryMock{EntryMock: EntryMock{Name: "a"}, deps: []string{"b", "c"}}
+	b := &dependentEntryMock{EntryMock: EntryMock{Name: "b"}, deps: []string{"c"}}
+	c := &dependentEntryMock{EntryMock: EntryMock{Name: "c"}}
+	d := &dependentEntryMock{EntryMock: EntryMock{Name: "d"}, deps: []string{"non-exist"}}
+
+	res, err := SortEntries([]Entry{a, d, b, c})
+	assert.Nil(t, err)
+	assert.Equal(t, []Entry{c, b, a, d}, res)
+
+	// with nil entry
+	res, err = SortEntries([]Entry{nil, c})
+	assert.Nil(t, err)
+	assert.Equal(t, []Entry{c}, res)
+}
+
+func TestSortEntries_WithCycle(t *testing.T) {
+	a := &dependentEntryMock{EntryMock: EntryMock{Name: "a"}, deps: []string{"b"}}
+	b := &dependentEntryMock{EntryMock: EntryMock{Name: "b"}, deps: []string{"c"}}
+	c := &dependentEntryMock{EntryMock: EntryMock{Name: "c"}, deps: []string{"a"}}
+
+	res, err := SortEntries([]Entry{a, b, c})
+	assert.Nil(t, res)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "mock/a -> mock/b -> mock/c -> mock/a")
+
+	// self dependency
+	self := &dependentEntryMock{EntryMock: EntryMock{Name: "self"}, deps: []string{"self"}}
+	_, err = SortEntries([]Entry{self})
+	assert.NotNil(t, err)
+}
+
+func TestAppContext_BootstrapAndInterruptEntries(t *testing.T) {
+	order := make([]string, 0)
+
+	a := &dependentEntryMock{EntryMock: EntryMock{Name: "a"}, deps: []string{"b"}, order: &order}
+	b := &dependentEntryMock{EntryMock: EntryMock{Name: "b"}, order: &order}
+
+	assert.Nil(t, GlobalAppCtx.BootstrapEntries(context.TODO(), []Entry{a, b}))
+	assert.Nil(t, GlobalAppCtx.InterruptEntries(context.TODO(), []Entry{a, b}))
+	assert.Equal(t, []string{"bootstrap-b", "bootstrap-a", "interrupt-a", "interrupt-b"}, order)
+
+	// with cycle
+	b.deps = []string{"a"}
+	assert.NotNil(t, GlobalAppCtx.BootstrapEntries(context.TODO(), []Entry{a, b}))
+	assert.NotNil(t, GlobalAppCtx.InterruptEntries(context.TODO(), []Entry{a, b}))
+}
+
+func TestAppContext_ListEntriesSorted(t *testing.T) {
+	defer GlobalAppCtx.clearEntries()
+
+	a := &dependentEntryMock{EntryMock: EntryMock{Name: "a"}, deps: []string{"b"}}
+	b := &dependentEntryMock{EntryMock: EntryMock{Name: "b"}}
+	GlobalAppCtx.AddEntry(a)
+	GlobalAppCtx.AddEntry(b)
+
+	res, err := GlobalAppCtx.ListEntriesSorted()
+	assert.Nil(t, err)
+	assert.Equal(t, []Entry{b, a}, res)
+}
+
+func TestPromEntry_DependsOn(t *testing.T) {
+	defer GlobalAppCtx.clearEntries()
+
+	RegisterCertEntry(&BootCert{
+		Cert: []*BootCertE{
+			{
+				Name: "ut-cert",
+			},
+		},
+	})
+
+	boot := &BootProm{
+		Enabled: true,
+	}
+	boot.Pusher.Enabled = true
+	boot.Pusher.CertEntry = "ut-cert"
+
+	entry := RegisterPromEntry(boot)
+	assert.Contains(t, entry.DependsOn(), EntryRef{Type: CertEntryType, Name: "ut-cert"})
+
+	// without pusher
+	entry = RegisterPromEntry(&BootProm{Enabled: true})
+	assert.Empty(t, entry.DependsOn())
+}
+
+type dependentEntryMock struct {
+	EntryMock
+	deps  []string
+	order *[]string
+}
+
+func (entry *dependentEntryMock) Bootstrap(context.Context) {
+	if entry.order != nil {
+		*entry.order = append(*entry.order, "bootstrap-"+entry.Name)
+	}
+}
+
+func (entry *dependentEntryMock) Interrupt(context.Context) {
+	if entry.order != nil {
+		*entry.order = append(*entry.order, "interrupt-"+entry.Name)
+	}
+}
+
+func (entry *dependentEntryMock) DependsOn() []EntryRef {
+	res := make([]EntryRef, 0)
+	for i := range entry.deps {
+		res = append(res, EntryRef{Type: entry.GetType(), Name: entry.deps[i]})
+	}
+
+	return res
+}
+
+func TestAppContext_BootstrapRegFuncs(t *testing.T) {
+	ctx := NewAppContext()
+	order := make([]string, 0)
+
+	// a depends on c which is registered by later RegFunc
+	a := &dependentEntryMock{EntryMock: EntryMock{Name: "a"}, deps: []string{"c"}, order: &order}
+	b := &dependentEntryMock{EntryMock: EntryMock{Name: "b"}, order: &order}
+	c := &dependentEntryMock{EntryMock: EntryMock{Name: "c"}, order: &order}
+	d := &dependentEntryMock{EntryMock: EntryMock{Name: "d"}, deps: []string{"non-exist"}, order: &order}
+
+	regFunc := func(entries ...Entry) RegFunc {
+		return func([]byte) map[string]Entry {
+			res := make(map[string]Entry)
+			for i := range entries {
+				ctx.AddEntry(entries[i])
+				res[entries[i].GetName()] = entries[i]
+			}
+			return res
+		}
+	}
+
+	// entries of first RegFunc are bootstrapped before second RegFunc runs
+	second := func(raw []byte) map[string]Entry {
+		order = append(order, "register-c")
+		return regFunc(c)(raw)
+	}
+
+	assert.Nil(t, ctx.bootstrapRegFuncs(context.TODO(), nil, []RegFunc{regFunc(a, b, d), second}))
+	assert.Equal(t, []string{"bootstrap-b", "register-c", "bootstrap-c", "bootstrap-a", "bootstrap-d"}, order)
+}
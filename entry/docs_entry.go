@@ -72,7 +72,8 @@ type DocsEntry struct {
 		Theme           string `yaml:"-" json:"-"`
 		UsePathInNavBar bool   `yaml:"-" json:"-"`
 	} `yaml:"-" json:"-"`
-	embedFS *embed.FS `json:"-" yaml:"-"`
+	embedFS *embed.FS   `json:"-" yaml:"-"`
+	appCtx  *AppContext `json:"-" yaml:"-"`
 }
 
 // WithNameDocsEntry provide name of DocsEntry
@@ -82,6 +83,15 @@ func WithNameDocsEntry(name string) DocsEntryOption {
 	}
 }
 
+// WithAppCtxDocsEntry provide AppContext, GlobalAppCtx by default
+func WithAppCtxDocsEntry(ctx *AppContext) DocsEntryOption {
+	return func(entry *DocsEntry) {
+		if ctx != nil {
+			entry.appCtx = ctx
+		}
+	}
+}
+
 // RegisterDocsEntry register DocsEntry
 func RegisterDocsEntry(boot *BootDocs, opts ...DocsEntryOption) *DocsEntry {
 	var docsEntry *DocsEntry
@@ -103,13 +113,14 @@ func RegisterDocsEntry(boot *BootDocs, opts ...DocsEntryOption) *DocsEntry {
 			Path:             boot.Path,
 			SpecPaths:        boot.SpecPaths,
 			Headers:          headers,
+			appCtx:           GlobalAppCtx,
 		}
 
 		for i := range opts {
 			opts[i](docsEntry)
 		}
 
-		docsEntry.embedFS = GlobalAppCtx.GetEmbedFS(docsEntry.GetType(), docsEntry.GetName())
+		docsEntry.embedFS = docsEntry.appCtx.GetEmbedFS(docsEntry.GetType(), docsEntry.GetName())
 
 		if len(docsEntry.Path) < 1 {
 			docsEntry.Path = "/docs"
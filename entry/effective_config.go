@@ -0,0 +1,217 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkentry
+
+import (
+	"fmt"
+	"gopkg.in/yaml.v2"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+const (
+	// EffectiveSourceFile value comes from boot config file
+	EffectiveSourceFile = "file"
+	// EffectiveSourceEnv value comes from environment variable, source would be like env:RK_GIN_0_PORT
+	EffectiveSourceEnv = "env"
+	// EffectiveSourceFlag value comes from flag, source would be like flag:--rkset
+	EffectiveSourceFlag = "flag"
+
+	// EffectiveMaskedValue is value of secrets in EffectiveConfig
+	EffectiveMaskedValue = "******"
+)
+
+// secretBootKeys are suffixes of lowered keys whose values would be masked
+var secretBootKeys = []string{
+	"password",
+	"passwd",
+	"secret",
+	"token",
+	"apikey",
+	"privatekey",
+	"credential",
+	"credentials",
+}
+
+// EffectiveValue is a leaf value of merged boot config with the source it comes from.
+type EffectiveValue struct {
+	Path   string      `json:"path" yaml:"path" example:"gin[0].port"`
+	Value  interface{} `json:"value" yaml:"value"`
+	Source string      `json:"source" yaml:"source" example:"env:RK_GIN_0_PORT"`
+	Masked bool        `json:"masked,omitempty" yaml:"masked,omitempty"`
+}
+
+// EffectiveConfig is boot config merged from file, ENV and --rkset flags.
+//
+// Every leaf is annotated with its source, values of secrets like password and token are masked.
+//
+// Example:
+//
+//	gin[0].port: 2008 (env:RK_GIN_0_PORT)
+//	gin[0].name: greeter (file)
+type EffectiveConfig struct {
+	Values []*EffectiveValue `json:"values" yaml:"values"`
+}
+
+// NewEffectiveConfig merge raw boot config with ENV, envMapping, --rkset-file and --rkset flags the same way as UnmarshalBootYAML does,
+// and annotate each leaf with its source.
+//
+// Keys are lowered as UnmarshalBootYAML does, overrides applied later win.
+func NewEffectiveConfig(raw []byte) (*EffectiveConfig, error) {
+	bootM, err := parseBootYAML(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	leaves := map[string]interface{}{}
+	flattenBootValue("", lowerKeyMap(bootM), leaves)
+
+	// ENV names in envMapping are case-sensitive
+	originalBootM := map[interface{}]interface{}{}
+	yaml.Unmarshal(raw, &originalBootM)
+	fileOverrides, _ := listFlagFileOverrides(newBootFlagSet())
+
+	sources := map[string]string{}
+	overrides := append(listEnvOverrides("RK"), listEnvMappingOverrides(bootEnvMapping(originalBootM))...)
+	overrides = append(overrides, fileOverrides...)
+	overrides = append(overrides, listFlagOverrides(newBootFlagSet())...)
+	for _, override := range overrides {
+		// invalid overrides are rejected by parseBootYAML already
+		overrideM, _ := override.parse()
+
+		overrideLeaves := map[string]interface{}{}
+		flattenBootValue("", lowerKeyMap(overrideM), overrideLeaves)
+
+		for k, v := range overrideLeaves {
+			// override could be ignored because of type mismatch
+			if effective, ok := leaves[k]; ok && v != nil && reflect.DeepEqual(effective, v) {
+				sources[k] = override.source
+			}
+		}
+	}
+
+	res := &EffectiveConfig{
+		Values: make([]*EffectiveValue, 0),
+	}
+
+	for k, v := range leaves {
+		value := &EffectiveValue{
+			Path:   k,
+			Value:  v,
+			Source: EffectiveSourceFile,
+		}
+
+		if source, ok := sources[k]; ok {
+			value.Source = source
+		}
+
+		// value of ENV and flag is shown as guessed type, raw string is kept if invalid
+		if override, ok := v.(bootOverrideValue); ok {
+			value.Source = override.source
+			if value.Value, err = override.natural(); err != nil {
+				value.Value = override.value
+			}
+		}
+
+		if isSecretBootPath(k) {
+			value.Value = EffectiveMaskedValue
+			value.Masked = true
+		}
+
+		res.Values = append(res.Values, value)
+	}
+
+	sort.Slice(res.Values, func(i, j int) bool {
+		return res.Values[i].Path < res.Values[j].Path
+	})
+
+	return res, nil
+}
+
+// Get returns value of path like gin[0].port, nil returned if missing.
+func (c *EffectiveConfig) Get(path string) *EffectiveValue {
+	if c == nil {
+		return nil
+	}
+
+	path = strings.ToLower(path)
+	for _, v := range c.Values {
+		if v.Path == path {
+			return v
+		}
+	}
+
+	return nil
+}
+
+// List returns values under path prefix like gin[0], all values returned if prefix is empty.
+func (c *EffectiveConfig) List(prefix string) []*EffectiveValue {
+	res := make([]*EffectiveValue, 0)
+	if c == nil {
+		return res
+	}
+
+	prefix = strings.ToLower(prefix)
+	for _, v := range c.Values {
+		if len(prefix) < 1 || v.Path == prefix ||
+			strings.HasPrefix(v.Path, prefix+".") || strings.HasPrefix(v.Path, prefix+"[") {
+			res = append(res, v)
+		}
+	}
+
+	return res
+}
+
+// flattenBootValue flatten parsed boot config into leaves keyed by path like gin[0].port.
+//
+// Empty map and slice are considered as leaves.
+func flattenBootValue(path string, input interface{}, res map[string]interface{}) {
+	switch v := input.(type) {
+	case map[interface{}]interface{}:
+		if len(v) < 1 && len(path) > 0 {
+			res[path] = v
+			return
+		}
+
+		for key, value := range v {
+			flattenBootValue(joinBootPath(path, fmt.Sprintf("%v", key)), value, res)
+		}
+	case []interface{}:
+		if len(v) < 1 {
+			res[path] = v
+			return
+		}
+
+		for i := range v {
+			flattenBootValue(fmt.Sprintf("%s[%d]", path, i), v[i], res)
+		}
+	default:
+		res[path] = v
+	}
+}
+
+// isSecretBootPath returns true if any key in path looks like a secret, like password or token.
+func isSecretBootPath(path string) bool {
+	for _, key := range strings.Split(strings.ToLower(path), ".") {
+		// trim index of slice
+		if i := strings.Index(key, "["); i >= 0 {
+			key = key[:i]
+		}
+
+		if key == "basic" {
+			return true
+		}
+
+		for _, suffix := range secretBootKeys {
+			if strings.HasSuffix(key, suffix) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,111 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkentry
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"testing"
+)
+
+func TestNewEffectiveConfig(t *testing.T) {
+	raw := `
+gin:
+  - name: greeter
+    port: 1949
+    commonService:
+      enabled: true
+    middleware:
+      auth:
+        basic: ["user:pass"]
+      jwt:
+        symmetric:
+          token: my-token
+config:
+  - name: ut-config
+    content: {}
+`
+	assert.Nil(t, os.Setenv("RK_GIN_0_PORT", "2008"))
+	defer os.Unsetenv("RK_GIN_0_PORT")
+
+	args := os.Args
+	os.Args = []string{args[0], "--rkset", "gin[0].name=greeter-flag"}
+	defer func() { os.Args = args }()
+
+	effective, err := NewEffectiveConfig([]byte(raw))
+	assert.Nil(t, err)
+
+	// from env
+	value := effective.Get("gin[0].port")
+	assert.Equal(t, 2008, value.Value)
+	assert.Equal(t, "env:RK_GIN_0_PORT", value.Source)
+
+	// from flag
+	value = effective.Get("gin[0].name")
+	assert.Equal(t, "greeter-flag", value.Value)
+	assert.Equal(t, "flag:--rkset", value.Source)
+
+	// from file with lowered key
+	value = effective.Get("gin[0].commonService.enabled")
+	assert.Equal(t, true, value.Value)
+	assert.Equal(t, EffectiveSourceFile, value.Source)
+
+	// empty map is kept
+	assert.NotNil(t, effective.Get("config[0].content"))
+
+	// masked
+	value = effective.Get("gin[0].middleware.auth.basic[0]")
+	assert.True(t, value.Masked)
+	assert.Equal(t, EffectiveMaskedValue, value.Value)
+	assert.True(t, effective.Get("gin[0].middleware.jwt.symmetric.token").Masked)
+
+	// list with prefix
+	assert.Len(t, effective.List("gin[0].middleware"), 2)
+	assert.Len(t, effective.List("config"), 2)
+	assert.Len(t, effective.List(""), len(effective.Values))
+	assert.Empty(t, effective.List("gin[1]"))
+
+	// missing
+	assert.Nil(t, effective.Get("gin[1].port"))
+}
+
+func TestNewEffectiveConfig_WithIgnoredOverride(t *testing.T) {
+	// type mismatch in slice is ignored while overriding
+	assert.Nil(t, os.Setenv("RK_GIN_0", "invalid"))
+	defer os.Unsetenv("RK_GIN_0")
+
+	effective, err := NewEffectiveConfig([]byte("gin:\n  - port: 1949\n"))
+	assert.Nil(t, err)
+	assert.Equal(t, EffectiveSourceFile, effective.Get("gin[0].port").Source)
+
+	// with invalid YAML
+	effective, err = NewEffectiveConfig([]byte("gin: [invalid"))
+	assert.Nil(t, effective)
+	assert.NotNil(t, err)
+}
+
+func TestAppContext_GetEffectiveConfig(t *testing.T) {
+	ctx := NewAppContext()
+	assert.Nil(t, ctx.GetEffectiveConfig())
+	assert.Nil(t, (*EffectiveConfig)(nil).Get("app.name"))
+	assert.Empty(t, (*EffectiveConfig)(nil).List(""))
+
+	assert.Nil(t, BootstrapBuiltInEntryFromYAMLE([]byte("app:\n  name: ut-app\n"), WithAppCtx(ctx)))
+	assert.Equal(t, "ut-app", ctx.GetEffectiveConfig().Get("app.name").Value)
+
+	// refreshed by SetBootSource
+	ctx.SetBootSource(nil, []byte("app:\n  name: ut-app-new\n"))
+	assert.Equal(t, "ut-app-new", ctx.GetEffectiveConfig().Get("app.name").Value)
+}
+
+func TestIsSecretBootPath(t *testing.T) {
+	assert.True(t, isSecretBootPath("redis[0].password"))
+	assert.True(t, isSecretBootPath("gin[0].middleware.auth.apiKey[1]"))
+	assert.True(t, isSecretBootPath("oauth.clientSecret"))
+	assert.True(t, isSecretBootPath("db.credentials.user"))
+	assert.False(t, isSecretBootPath("gin[0].middleware.jwt.tokenLookup"))
+	assert.False(t, isSecretBootPath("gin[0].port"))
+}
@@ -20,6 +20,8 @@ const (
 	ConfigEntryType = "ConfigEntry"
 	// EventEntryType public access
 	EventEntryType = "EventEntry"
+	// FeatureFlagEntryType public access
+	FeatureFlagEntryType = "FeatureFlagEntry"
 	// LoggerEntryType public access
 	LoggerEntryType = "LoggerEntry"
 	// CommonServiceEntryType public access
@@ -61,6 +63,8 @@ const (
 // Step 4:
 // Register your reg function in init() in order to register your entry while application starts
 //
+// RegisterEntryFactory could be used instead of Step 3 and Step 4, so that boot config is parsed once by framework.
+//
 // How entry interact with rk-boot.Bootstrapper?
 // 1: Entry will be created and registered into rkentry.GlobalAppCtx
 // 2: Bootstrap will be called from Bootstrapper.Bootstrap() function
@@ -90,6 +94,21 @@ type Entry interface {
 	String() string
 }
 
+// RegFuncE is the same as RegFunc but returns error instead of panic.
+//
+// RegOption could be provided to register entries into AppContext other than GlobalAppCtx.
+type RegFuncE func(raw []byte, opts ...RegOption) (map[string]Entry, error)
+
+// EntryE is an optional interface which could be implemented by Entry.
+//
+// BootstrapE will be called instead of Bootstrap by AppContext, so that error could be returned instead of panic.
+type EntryE interface {
+	Entry
+
+	// BootstrapE bootstrap entry and returns error if any
+	BootstrapE(context.Context) error
+}
+
 // SignerJwt interface which must be implemented for JWT signer
 type SignerJwt interface {
 	Entry
@@ -114,3 +133,22 @@ type Crypto interface {
 
 	Decrypt(plaintext []byte) ([]byte, error)
 }
+
+// EntryRef refers to an Entry registered in AppContext by type and name.
+type EntryRef struct {
+	Type string `yaml:"type" json:"type"`
+	Name string `yaml:"name" json:"name"`
+}
+
+// String returns ref as <type>/<name>.
+func (ref EntryRef) String() string {
+	return ref.Type + "/" + ref.Name
+}
+
+// EntryDependency is an optional interface which could be implemented by Entry.
+//
+// Entries returned by DependsOn() will be bootstrapped before this Entry and interrupted after it.
+type EntryDependency interface {
+	// DependsOn returns entries which needs to be bootstrapped first
+	DependsOn() []EntryRef
+}
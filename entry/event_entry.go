@@ -55,35 +55,25 @@ func NewEventEntryStdout() *EventEntry {
 }
 
 // RegisterEventEntry create event logger entry with options.
-func RegisterEventEntry(boot *BootEvent) []*EventEntry {
-	res := make([]*EventEntry, 0)
-
-	// filter out based domain
-	configMap := make(map[string]*BootEventE)
-	for _, config := range boot.Event {
-		if len(config.Name) < 1 {
-			continue
-		}
-
-		if !IsValidDomain(config.Domain) {
-			continue
-		}
+func RegisterEventEntry(boot *BootEvent, opts ...RegOption) []*EventEntry {
+	res, err := RegisterEventEntryE(boot, opts...)
+	if err != nil {
+		ShutdownWithError(err)
+	}
 
-		// * or matching domain
-		// 1: add it to map if missing
-		if _, ok := configMap[config.Name]; !ok {
-			configMap[config.Name] = config
-			continue
-		}
+	return res
+}
 
-		// 2: already has an entry, then compare domain,
-		//    only one case would occur, previous one is already the correct one, continue
-		if config.Domain == "" || config.Domain == "*" {
-			continue
-		}
+// RegisterEventEntryE is the same as RegisterEventEntry but returns error instead of panic.
+func RegisterEventEntryE(boot *BootEvent, opts ...RegOption) ([]*EventEntry, error) {
+	appCtx := NewRegOptions(opts...).AppCtx
+	res := make([]*EventEntry, 0)
+	bootErr := &BootConfigError{}
 
-		configMap[config.Name] = config
-	}
+	// filter out based domain
+	configMap := filterByDomain(boot.Event, func(config *BootEventE) (string, string) {
+		return config.Name, config.Domain
+	})
 
 	for _, event := range configMap {
 		entry := &EventEntry{
@@ -123,8 +113,8 @@ func RegisterEventEntry(boot *BootEvent) []*EventEntry {
 			// default labels
 			opts = append(opts,
 				rklogger.WithLokiLabel(rkmid.Domain.Key, rkmid.Domain.String),
-				rklogger.WithLokiLabel("app_name", GlobalAppCtx.GetAppInfoEntry().AppName),
-				rklogger.WithLokiLabel("app_version", GlobalAppCtx.GetAppInfoEntry().Version),
+				rklogger.WithLokiLabel("app_name", appCtx.GetAppInfoEntry().AppName),
+				rklogger.WithLokiLabel("app_version", appCtx.GetAppInfoEntry().Version),
 				rklogger.WithLokiLabel("logger_type", "event"),
 			)
 
@@ -146,12 +136,13 @@ func RegisterEventEntry(boot *BootEvent) []*EventEntry {
 		var eventLogger *zap.Logger
 		var err error
 		if eventLogger, err = rklogger.NewZapLoggerWithConfAndSyncer(eventLoggerConfig, eventLoggerLumberjackConfig, syncers); err != nil {
-			ShutdownWithError(err)
+			bootErr.Add(bootPath("event", boot.Event, event), err)
+			continue
 		} else {
 			eventFactory = rkquery.NewEventFactory(
 				rkquery.WithZapLogger(eventLogger),
-				rkquery.WithAppName(GlobalAppCtx.GetAppInfoEntry().AppName),
-				rkquery.WithAppVersion(GlobalAppCtx.GetAppInfoEntry().Version),
+				rkquery.WithAppName(appCtx.GetAppInfoEntry().AppName),
+				rkquery.WithAppVersion(appCtx.GetAppInfoEntry().Version),
 				rkquery.WithEncoding(rkquery.ToEncoding(event.Encoding)))
 		}
 
@@ -162,27 +153,39 @@ func RegisterEventEntry(boot *BootEvent) []*EventEntry {
 		entry.LoggerConfig = eventLoggerConfig
 		entry.LumberjackConfig = eventLoggerLumberjackConfig
 
-		GlobalAppCtx.AddEntry(entry)
+		appCtx.AddEntry(entry)
 		res = append(res, entry)
 	}
 
-	return res
+	return res, bootErr.ErrorOrNil()
 }
 
 // RegisterEventEntryYAML register function
 func RegisterEventEntryYAML(raw []byte) map[string]Entry {
+	res, err := RegisterEventEntryYAMLE(raw)
+	if err != nil {
+		ShutdownWithError(err)
+	}
+
+	return res
+}
+
+// RegisterEventEntryYAMLE is the same as RegisterEventEntryYAML but returns error instead of panic.
+func RegisterEventEntryYAMLE(raw []byte, opts ...RegOption) (map[string]Entry, error) {
 	boot := &BootEvent{}
-	UnmarshalBootYAML(raw, boot)
+	if err := unmarshalBootYAMLE(NewRegOptions(opts...).AppCtx, raw, boot); err != nil {
+		return nil, err
+	}
 
 	res := map[string]Entry{}
 
-	entries := RegisterEventEntry(boot)
+	entries, err := RegisterEventEntryE(boot, opts...)
 	for i := range entries {
 		entry := entries[i]
 		res[entry.GetName()] = entry
 	}
 
-	return res
+	return res, err
 }
 
 // BootEvent bootstrap config of Event Logger information.
@@ -246,6 +249,16 @@ func (entry *EventEntry) Interrupt(ctx context.Context) {
 	}
 }
 
+// ShutdownPhase implements EntryShutdown, Loki syncer would be flushed with telemetry.
+func (entry *EventEntry) ShutdownPhase() ShutdownPhase {
+	return ShutdownPhaseFlushTelemetry
+}
+
+// ShutdownTimeout implements EntryShutdown, default timeout of AppContext is used.
+func (entry *EventEntry) ShutdownTimeout() time.Duration {
+	return 0
+}
+
 // GetName returns name of entry.
 func (entry *EventEntry) GetName() string {
 	return entry.entryName
@@ -294,6 +307,11 @@ func (entry *EventEntry) UnmarshalJSON([]byte) error {
 	return nil
 }
 
+// IsDefaultEntry returns true if EventEntry was marked as default.
+func (entry *EventEntry) IsDefaultEntry() bool {
+	return entry.IsDefault
+}
+
 // GetDescription return description of entry.
 func (entry *EventEntry) GetDescription() string {
 	return entry.entryDescription
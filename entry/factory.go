@@ -0,0 +1,218 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkentry
+
+import (
+	"context"
+	"fmt"
+	"go.uber.org/zap"
+	"sort"
+	"strings"
+)
+
+var (
+	// entryFactories keyed by lowered section of boot config
+	entryFactories = make(map[string]*entryFactory)
+	// entryFactorySections keeps registration order of factories
+	entryFactorySections = make([]string, 0)
+)
+
+// entryFactory creates Entry from element of boot config section.
+type entryFactory struct {
+	section string
+	create  func(appCtx *AppContext, input interface{}) (Entry, error)
+}
+
+// bootElement is element of boot config section with YAML path.
+type bootElement struct {
+	path   string
+	input  interface{}
+	name   string
+	domain string
+}
+
+// RegisterEntryFactory register factory of Entry keyed by top level section of boot config.
+//
+// newConfig returns boot config of a single element which will be decoded from the section,
+// newEntry creates Entry from decoded boot config, nil Entry could be returned if entry is disabled.
+//
+// If section is a list, each element will be decoded and created separately, elements are filtered
+// by domain with name and domain fields.
+//
+// Example:
+//
+//	rkentry.RegisterEntryFactory("myEntry",
+//	    func() *BootMyEntry { return &BootMyEntry{} },
+//	    func(config *BootMyEntry) (rkentry.Entry, error) { return NewMyEntry(config), nil })
+func RegisterEntryFactory[T any](section string, newConfig func() T, newEntry func(T) (Entry, error)) {
+	if len(section) < 1 || newConfig == nil || newEntry == nil {
+		return
+	}
+
+	// section could be either single element or list of elements
+	schema := NewJSONSchema(newConfig())
+	registerBootSection(section, &JSONSchema{
+		OneOf: []*JSONSchema{schema, {Type: jsonSchemaArray, Items: schema}},
+	})
+
+	key := strings.ToLower(section)
+	if _, ok := entryFactories[key]; !ok {
+		entryFactorySections = append(entryFactorySections, key)
+	}
+
+	entryFactories[key] = &entryFactory{
+		section: section,
+		create: func(appCtx *AppContext, input interface{}) (Entry, error) {
+			config := newConfig()
+			if err := decodeBootYAML(appCtx, "", input, &config); err != nil {
+				return nil, err
+			}
+
+			return newEntry(config)
+		},
+	}
+}
+
+// RegisterFactoryEntryYAML register entries with factories registered by RegisterEntryFactory.
+func RegisterFactoryEntryYAML(raw []byte, opts ...RegOption) []Entry {
+	res, err := RegisterFactoryEntryYAMLE(raw, opts...)
+	if err != nil {
+		ShutdownWithError(err)
+	}
+
+	return res
+}
+
+// RegisterFactoryEntryYAMLE is the same as RegisterFactoryEntryYAML but returns error instead of panic.
+//
+// Boot config is parsed once with ENV and flag overrides, and each top level section is routed to its factory.
+// Sections unknown by factories and RegisterBootSection are skipped with warning, since they are usually parsed by RegFunc,
+// they are rejected by ValidateBootYAML before any entry is created with WithStrictBootConfig.
+func RegisterFactoryEntryYAMLE(raw []byte, opts ...RegOption) ([]Entry, error) {
+	regOpts := NewRegOptions(opts...)
+	appCtx := regOpts.AppCtx
+	res := make([]Entry, 0)
+	bootErr := &BootConfigError{}
+
+	if regOpts.Strict {
+		if err := ValidateBootYAML(raw); err != nil {
+			return nil, err
+		}
+	}
+
+	bootM, err := parseBootYAML(raw)
+	if err != nil {
+		return nil, err
+	}
+	appCtx.setBootRaw(raw)
+
+	// unknown sections are already rejected in strict mode
+	keys := make([]string, 0)
+	for k := range bootM {
+		keys = append(keys, fmt.Sprintf("%v", k))
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		if _, ok := entryFactories[k]; !ok && bootSections[k] == nil {
+			LoggerEntryStdout.Warn("Unknown section of boot config is skipped, register it with RegisterEntryFactory() or RegisterBootSection()",
+				zap.String("section", k))
+		}
+	}
+
+	for _, section := range entryFactorySections {
+		input, ok := bootM[section]
+		if !ok {
+			continue
+		}
+
+		factory := entryFactories[section]
+		for _, elem := range factory.elements(appCtx, input) {
+			entry, err := factory.create(appCtx, elem.input)
+			if err != nil {
+				bootErr.Add(elem.path, err)
+				continue
+			}
+
+			if entry == nil {
+				continue
+			}
+
+			appCtx.AddEntry(entry)
+			res = append(res, entry)
+		}
+	}
+
+	return res, bootErr.ErrorOrNil()
+}
+
+// BootstrapFactoryEntryFromYAML register entries with factories and bootstrap them.
+//
+// Entries will be bootstrapped in dependency order declared by EntryDependency.
+func BootstrapFactoryEntryFromYAML(raw []byte, opts ...RegOption) {
+	if err := BootstrapFactoryEntryFromYAMLE(raw, opts...); err != nil {
+		ShutdownWithError(err)
+	}
+}
+
+// BootstrapFactoryEntryFromYAMLE is the same as BootstrapFactoryEntryFromYAML but returns error instead of panic.
+func BootstrapFactoryEntryFromYAMLE(raw []byte, opts ...RegOption) error {
+	entries, err := RegisterFactoryEntryYAMLE(raw, opts...)
+	if err != nil {
+		return err
+	}
+
+	return NewRegOptions(opts...).AppCtx.BootstrapEntries(context.Background(), entries)
+}
+
+// elements split section into elements which passed domain filtering, order is kept.
+func (factory *entryFactory) elements(appCtx *AppContext, input interface{}) []*bootElement {
+	list, ok := input.([]interface{})
+	if !ok {
+		elem := newBootElement(appCtx, factory.section, input)
+		if !IsValidDomain(elem.domain) {
+			return []*bootElement{}
+		}
+
+		return []*bootElement{elem}
+	}
+
+	elems := make([]*bootElement, 0)
+	for i := range list {
+		elems = append(elems, newBootElement(appCtx, fmt.Sprintf("%s[%d]", factory.section, i), list[i]))
+	}
+
+	filtered := filterByDomain(elems, func(elem *bootElement) (string, string) {
+		return elem.name, elem.domain
+	})
+
+	res := make([]*bootElement, 0)
+	for _, elem := range elems {
+		if filtered[elem.name] == elem {
+			res = append(res, elem)
+		}
+	}
+
+	return res
+}
+
+// newBootElement read name and domain of element.
+func newBootElement(appCtx *AppContext, path string, input interface{}) *bootElement {
+	meta := &struct {
+		Name   string `yaml:"name"`
+		Domain string `yaml:"domain"`
+	}{}
+
+	// ignore error, it will be reported while decoding into boot config of factory
+	decodeBootYAML(appCtx, "", input, meta)
+
+	return &bootElement{
+		path:   path,
+		input:  input,
+		name:   meta.Name,
+		domain: meta.Domain,
+	}
+}
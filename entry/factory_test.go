@@ -0,0 +1,191 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkentry
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"strings"
+	"testing"
+)
+
+type factoryConfigMock struct {
+	Enabled bool   `yaml:"enabled"`
+	Name    string `yaml:"name"`
+	Domain  string `yaml:"domain"`
+	Port    int    `yaml:"port"`
+}
+
+func registerFactoryMock(t *testing.T, section string) {
+	RegisterEntryFactory(section, func() *factoryConfigMock {
+		return &factoryConfigMock{}
+	}, func(config *factoryConfigMock) (Entry, error) {
+		if !config.Enabled {
+			return nil, nil
+		}
+		return &EntryMock{Name: config.Name}, nil
+	})
+
+	t.Cleanup(func() {
+		key := strings.ToLower(section)
+		delete(entryFactories, key)
+		delete(bootSections, key)
+		for i := range entryFactorySections {
+			if entryFactorySections[i] == key {
+				entryFactorySections = append(entryFactorySections[:i], entryFactorySections[i+1:]...)
+				break
+			}
+		}
+	})
+}
+
+func TestRegisterEntryFactory_WithInvalidArgs(t *testing.T) {
+	RegisterEntryFactory[*factoryConfigMock]("", nil, nil)
+	RegisterEntryFactory[*factoryConfigMock]("ut-section", nil, nil)
+	assert.NotContains(t, entryFactories, "ut-section")
+}
+
+func TestRegisterFactoryEntryYAMLE(t *testing.T) {
+	registerFactoryMock(t, "utSingle")
+	registerFactoryMock(t, "utList")
+
+	raw := `
+app:
+  name: ut-app
+utSingle:
+  enabled: true
+  name: ut-single
+utList:
+  - enabled: true
+    name: ut-list-0
+  - enabled: false
+    name: ut-list-1
+  - enabled: true
+    name: ut-list-2
+`
+	ctx := NewAppContext()
+	entries, err := RegisterFactoryEntryYAMLE([]byte(raw), WithAppCtx(ctx))
+	assert.Nil(t, err)
+	assert.Len(t, entries, 3)
+	assert.Equal(t, "ut-single", entries[0].GetName())
+	assert.Equal(t, "ut-list-0", entries[1].GetName())
+	assert.Equal(t, "ut-list-2", entries[2].GetName())
+
+	assert.NotNil(t, ctx.GetEntry("mock", "ut-single"))
+	assert.Nil(t, GlobalAppCtx.GetEntry("mock", "ut-single"))
+}
+
+func TestRegisterFactoryEntryYAMLE_WithDomain(t *testing.T) {
+	registerFactoryMock(t, "utList")
+
+	raw := `
+utList:
+  - enabled: true
+    name: ut-entry
+    port: 1
+  - enabled: true
+    name: ut-entry
+    domain: prod
+    port: 2
+  - enabled: true
+    name: ut-test
+    domain: test
+`
+	assert.Nil(t, os.Setenv("DOMAIN", "prod"))
+	defer os.Setenv("DOMAIN", "")
+
+	entries, err := RegisterFactoryEntryYAMLE([]byte(raw), WithAppCtx(NewAppContext()))
+	assert.Nil(t, err)
+	assert.Len(t, entries, 1)
+	assert.Equal(t, "ut-entry", entries[0].GetName())
+}
+
+func TestRegisterFactoryEntryYAMLE_WithUnknownSection(t *testing.T) {
+	registerFactoryMock(t, "utSingle")
+
+	raw := `
+utSingle:
+  enabled: true
+  name: ut-single
+utUnknown:
+  key: value
+`
+	// skipped by default
+	ctx := NewAppContext()
+	entries, err := RegisterFactoryEntryYAMLE([]byte(raw), WithAppCtx(ctx))
+	assert.Nil(t, err)
+	assert.Len(t, entries, 1)
+
+	// rejected in strict mode
+	ctx = NewAppContext()
+	entries, err = RegisterFactoryEntryYAMLE([]byte(raw), WithAppCtx(ctx), WithStrictBootConfig())
+	assert.Nil(t, entries)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "utUnknown")
+	assert.Nil(t, ctx.GetEntry("mock", "ut-single"))
+
+	// declare section
+	RegisterBootSection("utUnknown")
+	defer delete(bootSections, "utunknown")
+
+	entries, err = RegisterFactoryEntryYAMLE([]byte(raw), WithAppCtx(ctx), WithStrictBootConfig())
+	assert.Nil(t, err)
+	assert.Len(t, entries, 1)
+}
+
+func TestRegisterFactoryEntryYAMLE_WithInvalidConfig(t *testing.T) {
+	registerFactoryMock(t, "utList")
+
+	raw := `
+utList:
+  - enabled: true
+    name: ut-list-0
+  - enabled: true
+    name: ut-list-1
+    port: invalid
+`
+	entries, err := RegisterFactoryEntryYAMLE([]byte(raw), WithAppCtx(NewAppContext()))
+	assert.Len(t, entries, 1)
+	assert.NotNil(t, err)
+
+	bootErr := err.(*BootConfigError)
+	assert.Len(t, bootErr.Issues, 1)
+	assert.Equal(t, "utList[1].port", bootErr.Issues[0].Path)
+}
+
+func TestBootstrapFactoryEntryFromYAMLE(t *testing.T) {
+	registerFactoryMock(t, "utSingle")
+
+	ctx := NewAppContext()
+	assert.Nil(t, BootstrapFactoryEntryFromYAMLE([]byte(`
+utSingle:
+  enabled: true
+  name: ut-single
+`), WithAppCtx(ctx)))
+	assert.Equal(t, EntryStateRunning, ctx.GetEntryStatus("mock", "ut-single").State)
+
+	// with unknown section in strict mode
+	defer assertPanic(t)
+	BootstrapFactoryEntryFromYAML([]byte(`utUnknown: {}`), WithAppCtx(ctx), WithStrictBootConfig())
+}
+
+func TestFilterByDomain(t *testing.T) {
+	assert.Nil(t, os.Setenv("DOMAIN", "prod"))
+	defer os.Setenv("DOMAIN", "")
+
+	list := []*BootConfigE{
+		{Name: "", Domain: "*"},
+		{Name: "ut-config", Domain: "prod"},
+		{Name: "ut-config", Domain: "*"},
+		{Name: "ut-test", Domain: "test"},
+	}
+
+	res := filterByDomain(list, func(config *BootConfigE) (string, string) {
+		return config.Name, config.Domain
+	})
+	assert.Len(t, res, 1)
+	assert.Equal(t, list[1], res["ut-config"])
+}
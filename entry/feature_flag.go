@@ -0,0 +1,399 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkentry
+
+import (
+	"errors"
+	"fmt"
+	"github.com/prometheus/client_golang/prometheus"
+	"hash/fnv"
+	"net/http"
+	"path"
+	"strings"
+)
+
+const (
+	// FeatureFlagVariantOn is variant served to subjects which flag is on for if flag has no variants
+	FeatureFlagVariantOn = "on"
+	// FeatureFlagVariantOff is variant served to subjects which flag is off for
+	FeatureFlagVariantOff = "off"
+
+	// FeatureFlagReasonMissing flag not found
+	FeatureFlagReasonMissing = "missing"
+	// FeatureFlagReasonDisabled flag is disabled for every subject
+	FeatureFlagReasonDisabled = "disabled"
+	// FeatureFlagReasonRule variant is served by matched rule
+	FeatureFlagReasonRule = "rule"
+	// FeatureFlagReasonRollout variant is served by percentage rollout
+	FeatureFlagReasonRollout = "rollout"
+
+	// FeatureFlagCountMissing is flag name which evaluations of missing flags are counted under
+	FeatureFlagCountMissing = "_missing"
+
+	// featureFlagBuckets is number of buckets of percentage rollout, which is 0.01%
+	featureFlagBuckets = 10000
+)
+
+// FeatureFlag is definition of a feature flag.
+//
+// Flag is evaluated in order of enabled, rules and rollout, variants are picked by stable hash of subject key.
+type FeatureFlag struct {
+	Name        string `yaml:"name" json:"name"`
+	Description string `yaml:"description" json:"description"`
+	// Enabled is kill switch of flag, flag is off for every subject if disabled
+	Enabled bool `yaml:"enabled" json:"enabled"`
+	// Rollout is percentage of subjects which flag is on for, 100 if missing.
+	// Subjects without key are included only if rollout is 100.
+	Rollout *float64 `yaml:"rollout" json:"rollout,omitempty"`
+	// Variants are served to subjects which flag is on for by weight, on is served if empty
+	Variants []*FeatureFlagVariant `yaml:"variants" json:"variants,omitempty"`
+	// Rules are matched in order before rollout, variant of first matched rule is served
+	Rules []*FeatureFlagRule `yaml:"rules" json:"rules,omitempty"`
+}
+
+// FeatureFlagVariant is variant of FeatureFlag with weight.
+type FeatureFlagVariant struct {
+	Name   string `yaml:"name" json:"name"`
+	Weight int    `yaml:"weight" json:"weight"`
+}
+
+// FeatureFlagRule targets subjects with request attributes.
+//
+// One of header, claim and domains should be provided, values and domains support wildcard like path.Match.
+type FeatureFlagRule struct {
+	// Header is name of request header which is compared with values
+	Header string `yaml:"header" json:"header,omitempty"`
+	// Claim is name of JWT claim which is compared with values, any element matches if claim is a list
+	Claim  string   `yaml:"claim" json:"claim,omitempty"`
+	Values []string `yaml:"values" json:"values,omitempty"`
+	// Domains are compared with host of request, like *.beta.example.com
+	Domains []string `yaml:"domains" json:"domains,omitempty"`
+	// Variant served if matched, off excludes subjects, variant is picked by rollout and weight if empty
+	Variant string `yaml:"variant" json:"variant,omitempty"`
+}
+
+// FeatureFlagContext contains attributes of subject which flags are evaluated with.
+type FeatureFlagContext struct {
+	// SubjectKey is stable key of subject like user ID, used by percentage rollout and variants
+	SubjectKey string
+	Headers    http.Header
+	Claims     map[string]interface{}
+	// Domain is host of request without port
+	Domain string
+}
+
+// FeatureFlagResult is result of evaluation.
+type FeatureFlagResult struct {
+	Flag    string `json:"flag" yaml:"flag"`
+	Variant string `json:"variant" yaml:"variant"`
+	Enabled bool   `json:"enabled" yaml:"enabled"`
+	Reason  string `json:"reason" yaml:"reason"`
+}
+
+// validate flag, rules and variants.
+func (flag *FeatureFlag) validate() error {
+	if len(flag.Name) < 1 {
+		return errors.New("name is required")
+	}
+
+	if flag.Rollout != nil && (*flag.Rollout < 0 || *flag.Rollout > 100) {
+		return fmt.Errorf("rollout %v out of range, expect 0 to 100", *flag.Rollout)
+	}
+
+	for _, v := range flag.Variants {
+		if len(v.Name) < 1 {
+			return errors.New("name of variant is required")
+		}
+
+		if v.Weight < 0 {
+			return fmt.Errorf("weight of variant %s is negative", v.Name)
+		}
+	}
+
+	for i, rule := range flag.Rules {
+		targets := 0
+		for _, v := range []bool{len(rule.Header) > 0, len(rule.Claim) > 0, len(rule.Domains) > 0} {
+			if v {
+				targets++
+			}
+		}
+
+		if targets != 1 {
+			return fmt.Errorf("rules[%d] expect one of header, claim and domains", i)
+		}
+
+		if len(rule.Domains) < 1 && len(rule.Values) < 1 {
+			return fmt.Errorf("rules[%d] values are required", i)
+		}
+
+		if len(rule.Variant) > 0 && !flag.hasVariant(rule.Variant) {
+			return fmt.Errorf("rules[%d] variant %s not found", i, rule.Variant)
+		}
+	}
+
+	return nil
+}
+
+// hasVariant returns true if variant could be served by flag.
+func (flag *FeatureFlag) hasVariant(name string) bool {
+	if name == FeatureFlagVariantOff {
+		return true
+	}
+
+	if len(flag.Variants) < 1 {
+		return name == FeatureFlagVariantOn
+	}
+
+	for _, v := range flag.Variants {
+		if v.Name == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// evaluate flag with context.
+func (flag *FeatureFlag) evaluate(ctx *FeatureFlagContext) *FeatureFlagResult {
+	if ctx == nil {
+		ctx = &FeatureFlagContext{}
+	}
+
+	res := &FeatureFlagResult{
+		Flag:    flag.Name,
+		Variant: FeatureFlagVariantOff,
+	}
+
+	if !flag.Enabled {
+		res.Reason = FeatureFlagReasonDisabled
+		return res
+	}
+
+	res.Reason = FeatureFlagReasonRollout
+	included := flag.inRollout(ctx.SubjectKey)
+
+	for _, rule := range flag.Rules {
+		if rule.match(ctx) {
+			res.Reason = FeatureFlagReasonRule
+			if len(rule.Variant) > 0 {
+				res.Variant = rule.Variant
+				res.Enabled = rule.Variant != FeatureFlagVariantOff
+				return res
+			}
+
+			included = true
+			break
+		}
+	}
+
+	if included {
+		res.Variant = flag.pickVariant(ctx.SubjectKey)
+		res.Enabled = true
+	}
+
+	return res
+}
+
+// inRollout returns true if subject falls into percentage of rollout.
+func (flag *FeatureFlag) inRollout(key string) bool {
+	if flag.Rollout == nil || *flag.Rollout >= 100 {
+		return true
+	}
+
+	if len(key) < 1 {
+		return false
+	}
+
+	return featureFlagHash(flag.Name, key)%featureFlagBuckets < uint32(*flag.Rollout*featureFlagBuckets/100)
+}
+
+// pickVariant returns variant by weight with stable hash of subject key.
+func (flag *FeatureFlag) pickVariant(key string) string {
+	total := 0
+	for _, v := range flag.Variants {
+		total += v.Weight
+	}
+
+	if total < 1 {
+		if len(flag.Variants) > 0 {
+			return flag.Variants[0].Name
+		}
+		return FeatureFlagVariantOn
+	}
+
+	// hashed with different salt from rollout, so that variants are not skewed by rollout
+	bucket := int(featureFlagHash(flag.Name+"/variant", key) % uint32(total))
+	for _, v := range flag.Variants {
+		if bucket < v.Weight {
+			return v.Name
+		}
+		bucket -= v.Weight
+	}
+
+	return flag.Variants[len(flag.Variants)-1].Name
+}
+
+// match returns true if attributes in context match the rule.
+func (rule *FeatureFlagRule) match(ctx *FeatureFlagContext) bool {
+	switch {
+	case len(rule.Header) > 0:
+		if ctx.Headers == nil {
+			return false
+		}
+		return matchAny(rule.Values, ctx.Headers.Values(rule.Header)...)
+	case len(rule.Claim) > 0:
+		claim, ok := ctx.Claims[rule.Claim]
+		if !ok {
+			return false
+		}
+
+		if list, ok := claim.([]interface{}); ok {
+			values := make([]string, 0, len(list))
+			for i := range list {
+				values = append(values, fmt.Sprint(list[i]))
+			}
+			return matchAny(rule.Values, values...)
+		}
+
+		return matchAny(rule.Values, fmt.Sprint(claim))
+	case len(rule.Domains) > 0:
+		return len(ctx.Domain) > 0 && matchAny(rule.Domains, strings.ToLower(ctx.Domain))
+	default:
+		return false
+	}
+}
+
+// matchAny returns true if any value matches any pattern.
+func matchAny(patterns []string, values ...string) bool {
+	for _, v := range values {
+		for _, p := range patterns {
+			if ok, _ := path.Match(p, v); ok || p == v {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// featureFlagHash returns FNV-1a hash of flag name and subject key.
+func featureFlagHash(name, key string) uint32 {
+	h := fnv.New32a()
+	h.Write([]byte(name + ":" + key))
+	return h.Sum32()
+}
+
+// Evaluate flag with context, flag is off with reason of missing if not found.
+//
+// Evaluation is counted by flag and variant, missing flags are counted under FeatureFlagCountMissing
+// so that names passed by caller would not grow counts unbounded.
+func (entry *FeatureFlagEntry) Evaluate(name string, ctx *FeatureFlagContext) *FeatureFlagResult {
+	var res *FeatureFlagResult
+	countName := name
+	if flag := entry.GetFlag(name); flag != nil {
+		res = flag.evaluate(ctx)
+	} else {
+		countName = FeatureFlagCountMissing
+		res = &FeatureFlagResult{
+			Flag:    name,
+			Variant: FeatureFlagVariantOff,
+			Reason:  FeatureFlagReasonMissing,
+		}
+	}
+
+	entry.countLock.Lock()
+	entry.counts[featureFlagCountKey{flag: countName, variant: res.Variant}]++
+	entry.countLock.Unlock()
+
+	return res
+}
+
+// IsEnabled returns true if flag is on for context.
+func (entry *FeatureFlagEntry) IsEnabled(name string, ctx *FeatureFlagContext) bool {
+	return entry.Evaluate(name, ctx).Enabled
+}
+
+// Evaluator returns FeatureFlagEvaluator bound to context, which is put into request context by middleware.
+func (entry *FeatureFlagEntry) Evaluator(ctx *FeatureFlagContext) *FeatureFlagEvaluator {
+	return &FeatureFlagEvaluator{
+		entry: entry,
+		ctx:   ctx,
+	}
+}
+
+// FeatureFlagEvaluator evaluates flags of FeatureFlagEntry with attributes of a request.
+//
+// Flags are off if evaluator is nil.
+type FeatureFlagEvaluator struct {
+	entry *FeatureFlagEntry
+	ctx   *FeatureFlagContext
+}
+
+// Evaluate flag with attributes of request.
+func (e *FeatureFlagEvaluator) Evaluate(name string) *FeatureFlagResult {
+	if e == nil || e.entry == nil {
+		return &FeatureFlagResult{
+			Flag:    name,
+			Variant: FeatureFlagVariantOff,
+			Reason:  FeatureFlagReasonMissing,
+		}
+	}
+
+	return e.entry.Evaluate(name, e.ctx)
+}
+
+// IsEnabled returns true if flag is on for request.
+func (e *FeatureFlagEvaluator) IsEnabled(name string) bool {
+	return e.Evaluate(name).Enabled
+}
+
+// Variant returns variant of flag served to request.
+func (e *FeatureFlagEvaluator) Variant(name string) string {
+	return e.Evaluate(name).Variant
+}
+
+// GetContext returns attributes of request which flags are evaluated with.
+func (e *FeatureFlagEvaluator) GetContext() *FeatureFlagContext {
+	if e == nil {
+		return nil
+	}
+
+	return e.ctx
+}
+
+// featureFlagCollector exports evaluation counts of FeatureFlagEntry in AppContext.
+//
+// rk_feature_flag_evaluations_total is number of evaluations by flag and served variant.
+type featureFlagCollector struct {
+	appCtx      *AppContext
+	evaluations *prometheus.Desc
+}
+
+func newFeatureFlagCollector(appCtx *AppContext) *featureFlagCollector {
+	return &featureFlagCollector{
+		appCtx: appCtx,
+		evaluations: prometheus.NewDesc("rk_feature_flag_evaluations_total",
+			"Number of feature flag evaluations by served variant.",
+			[]string{"entry_name", "flag", "variant"}, nil),
+	}
+}
+
+// Describe implements prometheus.Collector
+func (c *featureFlagCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.evaluations
+}
+
+// Collect implements prometheus.Collector
+func (c *featureFlagCollector) Collect(ch chan<- prometheus.Metric) {
+	for _, entry := range ListEntriesAs[*FeatureFlagEntry](c.appCtx) {
+		for flag, variants := range entry.GetEvaluationCounts() {
+			for variant, count := range variants {
+				ch <- prometheus.MustNewConstMetric(c.evaluations, prometheus.CounterValue, float64(count),
+					entry.GetName(), flag, variant)
+			}
+		}
+	}
+}
@@ -0,0 +1,489 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkentry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/fsnotify/fsnotify"
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v3"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// RegisterFeatureFlagEntry create FeatureFlagEntry with BootFeatureFlag.
+func RegisterFeatureFlagEntry(boot *BootFeatureFlag, opts ...RegOption) []*FeatureFlagEntry {
+	res, err := RegisterFeatureFlagEntryE(boot, opts...)
+	if err != nil {
+		ShutdownWithError(err)
+	}
+
+	return res
+}
+
+// RegisterFeatureFlagEntryE is the same as RegisterFeatureFlagEntry but returns error instead of panic.
+func RegisterFeatureFlagEntryE(boot *BootFeatureFlag, opts ...RegOption) ([]*FeatureFlagEntry, error) {
+	appCtx := NewRegOptions(opts...).AppCtx
+	res := make([]*FeatureFlagEntry, 0)
+	bootErr := &BootConfigError{}
+
+	// filter out based domain
+	flagMap := filterByDomain(boot.FeatureFlag, func(config *BootFeatureFlagE) (string, string) {
+		return config.Name, config.Domain
+	})
+
+	for _, config := range flagMap {
+		entry := &FeatureFlagEntry{
+			entryName:        config.Name,
+			entryType:        FeatureFlagEntryType,
+			entryDescription: config.Description,
+			Path:             config.Path,
+			inline:           config.Flags,
+			watch:            config.Watch,
+			watchDebounce:    time.Duration(config.WatchDebounceMs) * time.Millisecond,
+			counts:           map[featureFlagCountKey]int64{},
+			appCtx:           appCtx,
+		}
+
+		if entry.watchDebounce <= 0 {
+			entry.watchDebounce = defaultConfigWatchDebounce
+		}
+
+		if len(entry.Path) > 0 {
+			if p, err := absConfigPath(entry.Path); err != nil {
+				bootErr.Add(bootPath("featureFlag", boot.FeatureFlag, config)+".path", err)
+				continue
+			} else {
+				entry.Path = p
+			}
+		}
+
+		flags, err := entry.loadFlags()
+		if err != nil {
+			bootErr.Add(bootPath("featureFlag", boot.FeatureFlag, config), err)
+			continue
+		}
+		entry.flags = flags
+
+		appCtx.AddEntry(entry)
+		res = append(res, entry)
+	}
+
+	return res, bootErr.ErrorOrNil()
+}
+
+// RegisterFeatureFlagEntryYAML register function
+func RegisterFeatureFlagEntryYAML(raw []byte) map[string]Entry {
+	res, err := RegisterFeatureFlagEntryYAMLE(raw)
+	if err != nil {
+		ShutdownWithError(err)
+	}
+
+	return res
+}
+
+// RegisterFeatureFlagEntryYAMLE is the same as RegisterFeatureFlagEntryYAML but returns error instead of panic.
+func RegisterFeatureFlagEntryYAMLE(raw []byte, opts ...RegOption) (map[string]Entry, error) {
+	boot := &BootFeatureFlag{}
+	if err := unmarshalBootYAMLE(NewRegOptions(opts...).AppCtx, raw, boot); err != nil {
+		return nil, err
+	}
+
+	res := map[string]Entry{}
+
+	entries, err := RegisterFeatureFlagEntryE(boot, opts...)
+	for i := range entries {
+		entry := entries[i]
+		res[entry.GetName()] = entry
+	}
+
+	return res, err
+}
+
+// BootFeatureFlag is bootstrap config of FeatureFlagEntry.
+type BootFeatureFlag struct {
+	FeatureFlag []*BootFeatureFlagE `yaml:"featureFlag" json:"featureFlag"`
+}
+
+// BootFeatureFlagE element of FeatureFlagEntry
+//
+// Flags in file of path win over flags in boot config with the same name.
+// File contains flags in the same format, like:
+//
+//	flags:
+//	  - name: new-checkout
+//	    enabled: true
+//	    rollout: 20
+type BootFeatureFlagE struct {
+	Name        string `yaml:"name" json:"name"`
+	Description string `yaml:"description" json:"description"`
+	Domain      string `yaml:"domain" json:"domain"`
+	Path        string `yaml:"path" json:"path"`
+	// Watch reload file of path once changed, previous flags are kept if new file is invalid
+	Watch           bool           `yaml:"watch" json:"watch"`
+	WatchDebounceMs int            `yaml:"watchDebounceMs" json:"watchDebounceMs"`
+	Flags           []*FeatureFlag `yaml:"flags" json:"flags"`
+}
+
+// featureFlagFile is content of file of BootFeatureFlagE.Path
+type featureFlagFile struct {
+	Flags []*FeatureFlag `yaml:"flags" json:"flags"`
+}
+
+// featureFlagCountKey is key of evaluation counts
+type featureFlagCountKey struct {
+	flag    string
+	variant string
+}
+
+// FeatureFlagEntry contains feature flags which could be evaluated with FeatureFlagContext.
+//
+// Evaluation counts are exported as rk_feature_flag_evaluations_total by PromEntry in the same AppContext.
+type FeatureFlagEntry struct {
+	entryName        string                        `yaml:"-" json:"-"`
+	entryType        string                        `yaml:"-" json:"-"`
+	entryDescription string                        `yaml:"-" json:"-"`
+	Path             string                        `yaml:"-" json:"-"`
+	inline           []*FeatureFlag                `yaml:"-" json:"-"`
+	flags            map[string]*FeatureFlag       `yaml:"-" json:"-"`
+	watch            bool                          `yaml:"-" json:"-"`
+	watchDebounce    time.Duration                 `yaml:"-" json:"-"`
+	watchStop        chan struct{}                 `yaml:"-" json:"-"`
+	reloadCount      int64                         `yaml:"-" json:"-"`
+	lastReloadErr    error                         `yaml:"-" json:"-"`
+	counts           map[featureFlagCountKey]int64 `yaml:"-" json:"-"`
+	appCtx           *AppContext                   `yaml:"-" json:"-"`
+	countLock        sync.Mutex                    `yaml:"-" json:"-"`
+	lock             sync.RWMutex                  `yaml:"-" json:"-"`
+}
+
+// Bootstrap entry, file of flags would be watched if enabled.
+func (entry *FeatureFlagEntry) Bootstrap(ctx context.Context) {
+	if err := entry.BootstrapE(ctx); err != nil {
+		ShutdownWithError(err)
+	}
+}
+
+// BootstrapE is the same as Bootstrap but returns error instead of panic.
+func (entry *FeatureFlagEntry) BootstrapE(context.Context) error {
+	if !entry.watch {
+		return nil
+	}
+
+	return entry.startWatch()
+}
+
+// Interrupt entry, stop watching file of flags.
+func (entry *FeatureFlagEntry) Interrupt(context.Context) {
+	entry.stopWatch()
+}
+
+// GetName returns name of entry.
+func (entry *FeatureFlagEntry) GetName() string {
+	return entry.entryName
+}
+
+// GetType returns type of entry.
+func (entry *FeatureFlagEntry) GetType() string {
+	return entry.entryType
+}
+
+// GetDescription return description of entry.
+func (entry *FeatureFlagEntry) GetDescription() string {
+	return entry.entryDescription
+}
+
+// String convert entry into JSON style string.
+func (entry *FeatureFlagEntry) String() string {
+	bytes, _ := json.Marshal(entry)
+	return string(bytes)
+}
+
+// MarshalJSON marshal entry.
+func (entry *FeatureFlagEntry) MarshalJSON() ([]byte, error) {
+	m := map[string]interface{}{
+		"name":        entry.GetName(),
+		"type":        entry.GetType(),
+		"description": entry.GetDescription(),
+		"path":        entry.Path,
+		"watch":       entry.watch,
+		"flags":       entry.ListFlags(),
+		"reloadCount": entry.GetReloadCount(),
+	}
+
+	if err := entry.GetLastReloadError(); err != nil {
+		m["lastReloadError"] = err.Error()
+	}
+
+	return json.Marshal(m)
+}
+
+// UnmarshalJSON is not supported.
+func (entry *FeatureFlagEntry) UnmarshalJSON([]byte) error {
+	return nil
+}
+
+// GetFlag returns flag with name, nil returned if missing.
+func (entry *FeatureFlagEntry) GetFlag(name string) *FeatureFlag {
+	entry.lock.RLock()
+	defer entry.lock.RUnlock()
+
+	return entry.flags[name]
+}
+
+// ListFlags returns flags sorted by name.
+func (entry *FeatureFlagEntry) ListFlags() []*FeatureFlag {
+	entry.lock.RLock()
+	defer entry.lock.RUnlock()
+
+	res := make([]*FeatureFlag, 0, len(entry.flags))
+	for _, v := range entry.flags {
+		res = append(res, v)
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Name < res[j].Name
+	})
+
+	return res
+}
+
+// GetEvaluationCounts returns evaluation counts keyed by flag and variant.
+func (entry *FeatureFlagEntry) GetEvaluationCounts() map[string]map[string]int64 {
+	entry.countLock.Lock()
+	defer entry.countLock.Unlock()
+
+	res := map[string]map[string]int64{}
+	for k, v := range entry.counts {
+		if _, ok := res[k.flag]; !ok {
+			res[k.flag] = map[string]int64{}
+		}
+		res[k.flag][k.variant] = v
+	}
+
+	return res
+}
+
+// GetState returns flags with evaluation counts, used by common service.
+func (entry *FeatureFlagEntry) GetState() *FeatureFlagState {
+	res := &FeatureFlagState{
+		EntryName:   entry.GetName(),
+		Path:        entry.Path,
+		ReloadCount: entry.GetReloadCount(),
+		Flags:       entry.ListFlags(),
+		Evaluations: entry.GetEvaluationCounts(),
+	}
+
+	if err := entry.GetLastReloadError(); err != nil {
+		res.LastReloadError = err.Error()
+	}
+
+	return res
+}
+
+// GetReloadCount returns times of file of flags reloaded successfully by watcher.
+func (entry *FeatureFlagEntry) GetReloadCount() int64 {
+	entry.lock.RLock()
+	defer entry.lock.RUnlock()
+
+	return entry.reloadCount
+}
+
+// GetLastReloadError returns error of last reload by watcher, nil returned if last reload succeeded.
+func (entry *FeatureFlagEntry) GetLastReloadError() error {
+	entry.lock.RLock()
+	defer entry.lock.RUnlock()
+
+	return entry.lastReloadErr
+}
+
+// Reload implements Reloadable, flags in boot config and file would be read again.
+//
+// Evaluation counts are kept, file watcher restarts if path or watch option changed.
+func (entry *FeatureFlagEntry) Reload(_ context.Context, raw []byte) error {
+	newEntry, err := reloadedEntry[*FeatureFlagEntry](entry.appCtx, raw, entry.GetName(), RegisterFeatureFlagEntryYAMLE)
+	if err != nil {
+		return err
+	}
+
+	entry.lock.Lock()
+	restartWatch := entry.watchStop != nil && (entry.Path != newEntry.Path || !newEntry.watch)
+	entry.Path = newEntry.Path
+	entry.inline = newEntry.inline
+	entry.flags = newEntry.flags
+	entry.watch = newEntry.watch
+	entry.watchDebounce = newEntry.watchDebounce
+	entry.lock.Unlock()
+
+	if restartWatch {
+		entry.stopWatch()
+	}
+
+	if entry.watch {
+		return entry.startWatch()
+	}
+
+	return nil
+}
+
+// loadFlags merge flags in boot config and file of path, flags are validated.
+func (entry *FeatureFlagEntry) loadFlags() (map[string]*FeatureFlag, error) {
+	list := append([]*FeatureFlag{}, entry.inline...)
+
+	if len(entry.Path) > 0 {
+		bytes, err := readFileE(entry.Path, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read flags, path:%s, %v", entry.Path, err)
+		}
+
+		file := &featureFlagFile{}
+		if err := yaml.Unmarshal(bytes, file); err != nil {
+			return nil, fmt.Errorf("failed to parse flags, path:%s, %v", entry.Path, err)
+		}
+
+		list = append(list, file.Flags...)
+	}
+
+	res := map[string]*FeatureFlag{}
+	for i := range list {
+		if list[i] == nil {
+			continue
+		}
+
+		if err := list[i].validate(); err != nil {
+			return nil, fmt.Errorf("invalid flag %s, %v", list[i].Name, err)
+		}
+
+		// later one wins
+		res[list[i].Name] = list[i]
+	}
+
+	return res, nil
+}
+
+// reloadFile read file of flags and swap flags if succeeded.
+func (entry *FeatureFlagEntry) reloadFile() error {
+	entry.lock.RLock()
+	loader := &FeatureFlagEntry{Path: entry.Path, inline: entry.inline}
+	entry.lock.RUnlock()
+
+	flags, err := loader.loadFlags()
+	if err != nil {
+		LoggerEntryStdout.Warn("Failed to reload feature flags, keep previous flags",
+			zap.String("entry", entry.GetName()), zap.Error(err))
+	}
+
+	entry.lock.Lock()
+	defer entry.lock.Unlock()
+
+	entry.lastReloadErr = err
+	if err == nil {
+		entry.flags = flags
+		entry.reloadCount++
+	}
+
+	return err
+}
+
+// startWatch start watching directory of file, nothing happens if already started.
+//
+// Directory is watched instead of file so that atomic renames and symlink swaps of Kubernetes ConfigMap are caught.
+func (entry *FeatureFlagEntry) startWatch() error {
+	entry.lock.Lock()
+	defer entry.lock.Unlock()
+
+	if entry.watchStop != nil || len(entry.Path) < 1 {
+		return nil
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+
+	p := filepath.Clean(entry.Path)
+	if err := watcher.Add(filepath.Dir(p)); err != nil {
+		watcher.Close()
+		return fmt.Errorf("failed to watch flags, path:%s, %v", entry.Path, err)
+	}
+
+	stop := make(chan struct{})
+	entry.watchStop = stop
+
+	go entry.watchLoop(watcher, stop, p, entry.watchDebounce)
+
+	return nil
+}
+
+// stopWatch stop watching file of flags.
+func (entry *FeatureFlagEntry) stopWatch() {
+	entry.lock.Lock()
+	defer entry.lock.Unlock()
+
+	if entry.watchStop != nil {
+		close(entry.watchStop)
+		entry.watchStop = nil
+	}
+}
+
+// watchLoop reload file after events settled down for debounce duration.
+func (entry *FeatureFlagEntry) watchLoop(watcher *fsnotify.Watcher, stop chan struct{}, p string, debounce time.Duration) {
+	defer watcher.Close()
+
+	realPath, _ := filepath.EvalSymlinks(p)
+	var timer *time.Timer
+
+	for {
+		select {
+		case <-stop:
+			if timer != nil {
+				timer.Stop()
+			}
+			return
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+
+			// symlink of file changed, like ..data of ConfigMap
+			currentPath, _ := filepath.EvalSymlinks(p)
+			changed := currentPath != realPath || filepath.Clean(event.Name) == p
+			realPath = currentPath
+
+			if !changed {
+				continue
+			}
+
+			if timer == nil {
+				timer = time.AfterFunc(debounce, func() {
+					entry.reloadFile()
+				})
+			} else {
+				timer.Reset(debounce)
+			}
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			LoggerEntryStdout.Warn("Failed to watch feature flags",
+				zap.String("entry", entry.GetName()), zap.Error(err))
+		}
+	}
+}
+
+// FeatureFlagState is state of FeatureFlagEntry returned by common service.
+type FeatureFlagState struct {
+	EntryName       string                      `json:"entryName" yaml:"entryName" example:"my-flags"`
+	Path            string                      `json:"path,omitempty" yaml:"path,omitempty" example:"/app/flags.yaml"`
+	ReloadCount     int64                       `json:"reloadCount" yaml:"reloadCount" example:"0"`
+	LastReloadError string                      `json:"lastReloadError,omitempty" yaml:"lastReloadError,omitempty"`
+	Flags           []*FeatureFlag              `json:"flags" yaml:"flags"`
+	Evaluations     map[string]map[string]int64 `json:"evaluations" yaml:"evaluations"`
+}
@@ -0,0 +1,130 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkentry
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRegisterFeatureFlagEntryYAMLE(t *testing.T) {
+	flagPath := filepath.ToSlash(filepath.Join(t.TempDir(), "flags.yaml"))
+	assert.Nil(t, os.WriteFile(flagPath, []byte(`
+flags:
+  - name: new-checkout
+    enabled: true
+    rollout: 20
+`), os.ModePerm))
+
+	raw := `
+featureFlag:
+  - name: ut-flags
+    description: ut
+    path: ` + flagPath + `
+    flags:
+      - name: new-checkout
+        enabled: false
+      - name: dark-mode
+        enabled: true
+        variants:
+          - name: blue
+            weight: 1
+        rules:
+          - header: X-Beta
+            values: ["true"]
+            variant: blue
+`
+	appCtx := NewAppContext()
+	entries, err := RegisterFeatureFlagEntryYAMLE([]byte(raw), WithAppCtx(appCtx))
+	assert.Nil(t, err)
+	entry := entries["ut-flags"].(*FeatureFlagEntry)
+	assert.Equal(t, entry, appCtx.GetFeatureFlagEntry("ut-flags"))
+	assert.Equal(t, FeatureFlagEntryType, entry.GetType())
+	assert.Equal(t, "ut", entry.GetDescription())
+	assert.Nil(t, ValidateBootYAML([]byte(raw)))
+
+	// flag in file wins
+	assert.True(t, entry.GetFlag("new-checkout").Enabled)
+	assert.Equal(t, 20.0, *entry.GetFlag("new-checkout").Rollout)
+	assert.Len(t, entry.ListFlags(), 2)
+	assert.Equal(t, "dark-mode", entry.ListFlags()[0].Name)
+	assert.Contains(t, entry.String(), "new-checkout")
+	assert.Nil(t, entry.UnmarshalJSON(nil))
+
+	// with invalid flag
+	entries, err = RegisterFeatureFlagEntryYAMLE([]byte(strings.ReplaceAll(raw, "variant: blue", "variant: green")), WithAppCtx(NewAppContext()))
+	assert.Empty(t, entries)
+	assert.Contains(t, err.Error(), "featureFlag[0]")
+	assert.Contains(t, err.Error(), "green")
+
+	// with missing file
+	entries, err = RegisterFeatureFlagEntryYAMLE([]byte(strings.ReplaceAll(raw, flagPath, flagPath+".missing")), WithAppCtx(NewAppContext()))
+	assert.Empty(t, entries)
+	assert.NotNil(t, err)
+}
+
+func TestFeatureFlagEntry_Watch(t *testing.T) {
+	flagPath := filepath.ToSlash(filepath.Join(t.TempDir(), "flags.yaml"))
+	assert.Nil(t, os.WriteFile(flagPath, []byte("flags:\n  - name: ut\n    enabled: false\n"), os.ModePerm))
+
+	entries := RegisterFeatureFlagEntry(&BootFeatureFlag{
+		FeatureFlag: []*BootFeatureFlagE{
+			{
+				Name:            "ut-flags",
+				Path:            flagPath,
+				Watch:           true,
+				WatchDebounceMs: 50,
+			},
+		},
+	}, WithAppCtx(NewAppContext()))
+	entry := entries[0]
+	assert.False(t, entry.IsEnabled("ut", nil))
+
+	assert.Nil(t, entry.BootstrapE(context.TODO()))
+	defer entry.Interrupt(context.TODO())
+
+	assert.Nil(t, os.WriteFile(flagPath, []byte("flags:\n  - name: ut\n    enabled: true\n"), os.ModePerm))
+	assert.Eventually(t, func() bool {
+		return entry.GetReloadCount() == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.True(t, entry.IsEnabled("ut", nil))
+
+	// invalid flags are ignored
+	assert.Nil(t, os.WriteFile(flagPath, []byte("flags:\n  - name: ut\n    rollout: 200\n"), os.ModePerm))
+	assert.Eventually(t, func() bool {
+		return entry.GetLastReloadError() != nil
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.True(t, entry.IsEnabled("ut", nil))
+	assert.Contains(t, entry.GetState().LastReloadError, "rollout")
+}
+
+func TestFeatureFlagEntry_Reload(t *testing.T) {
+	raw := `
+featureFlag:
+  - name: ut-flags
+    flags:
+      - name: ut
+        enabled: false
+`
+	entries, err := RegisterFeatureFlagEntryYAMLE([]byte(raw), WithAppCtx(NewAppContext()))
+	assert.Nil(t, err)
+	entry := entries["ut-flags"].(*FeatureFlagEntry)
+	assert.False(t, entry.IsEnabled("ut", nil))
+
+	// counts are kept
+	assert.Nil(t, entry.Reload(context.TODO(), []byte(strings.ReplaceAll(raw, "enabled: false", "enabled: true"))))
+	assert.True(t, entry.IsEnabled("ut", nil))
+	assert.Equal(t, map[string]int64{"off": 1, "on": 1}, entry.GetEvaluationCounts()["ut"])
+
+	// removed from boot config
+	err = entry.Reload(context.TODO(), []byte("featureFlag: []"))
+	assert.ErrorIs(t, err, ErrRestartRequired)
+}
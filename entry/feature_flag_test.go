@@ -0,0 +1,171 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkentry
+
+import (
+	"fmt"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+)
+
+func TestFeatureFlag_Validate(t *testing.T) {
+	rollout := 120.0
+
+	assert.NotNil(t, (&FeatureFlag{}).validate())
+	assert.NotNil(t, (&FeatureFlag{Name: "ut", Rollout: &rollout}).validate())
+	assert.NotNil(t, (&FeatureFlag{Name: "ut", Variants: []*FeatureFlagVariant{{Name: "a", Weight: -1}}}).validate())
+
+	// rule without target or values
+	assert.NotNil(t, (&FeatureFlag{Name: "ut", Rules: []*FeatureFlagRule{{Values: []string{"a"}}}}).validate())
+	assert.NotNil(t, (&FeatureFlag{Name: "ut", Rules: []*FeatureFlagRule{{Header: "X-Beta"}}}).validate())
+	assert.NotNil(t, (&FeatureFlag{Name: "ut", Rules: []*FeatureFlagRule{{Header: "X-Beta", Claim: "tier", Values: []string{"a"}}}}).validate())
+
+	// rule with unknown variant
+	err := (&FeatureFlag{Name: "ut", Rules: []*FeatureFlagRule{{Header: "X-Beta", Values: []string{"a"}, Variant: "blue"}}}).validate()
+	assert.Contains(t, err.Error(), "blue")
+
+	assert.Nil(t, (&FeatureFlag{
+		Name:     "ut",
+		Variants: []*FeatureFlagVariant{{Name: "blue", Weight: 1}},
+		Rules: []*FeatureFlagRule{
+			{Header: "X-Beta", Values: []string{"a"}, Variant: "blue"},
+			{Domains: []string{"*.beta.ut"}, Variant: "off"},
+		},
+	}).validate())
+}
+
+func TestFeatureFlag_Evaluate(t *testing.T) {
+	// disabled
+	flag := &FeatureFlag{Name: "ut"}
+	res := flag.evaluate(nil)
+	assert.False(t, res.Enabled)
+	assert.Equal(t, FeatureFlagVariantOff, res.Variant)
+	assert.Equal(t, FeatureFlagReasonDisabled, res.Reason)
+
+	// boolean flag
+	flag.Enabled = true
+	res = flag.evaluate(nil)
+	assert.True(t, res.Enabled)
+	assert.Equal(t, FeatureFlagVariantOn, res.Variant)
+	assert.Equal(t, FeatureFlagReasonRollout, res.Reason)
+
+	// percentage rollout is stable and close to percentage
+	rollout := 30.0
+	flag.Rollout = &rollout
+	on := 0
+	for i := 0; i < 10000; i++ {
+		ctx := &FeatureFlagContext{SubjectKey: fmt.Sprintf("user-%d", i)}
+		enabled := flag.evaluate(ctx).Enabled
+		assert.Equal(t, enabled, flag.evaluate(ctx).Enabled)
+		if enabled {
+			on++
+		}
+	}
+	assert.InDelta(t, 3000, on, 300)
+
+	// subject without key is excluded
+	assert.False(t, flag.evaluate(&FeatureFlagContext{}).Enabled)
+
+	// variants by weight
+	rollout = 100
+	flag.Variants = []*FeatureFlagVariant{{Name: "blue", Weight: 1}, {Name: "green", Weight: 3}}
+	counts := map[string]int{}
+	for i := 0; i < 10000; i++ {
+		counts[flag.evaluate(&FeatureFlagContext{SubjectKey: fmt.Sprintf("user-%d", i)}).Variant]++
+	}
+	assert.InDelta(t, 2500, counts["blue"], 300)
+	assert.InDelta(t, 7500, counts["green"], 300)
+}
+
+func TestFeatureFlag_EvaluateWithRules(t *testing.T) {
+	rollout := 0.0
+	flag := &FeatureFlag{
+		Name:     "ut",
+		Enabled:  true,
+		Rollout:  &rollout,
+		Variants: []*FeatureFlagVariant{{Name: "blue", Weight: 1}},
+		Rules: []*FeatureFlagRule{
+			{Domains: []string{"*.blocked.ut"}, Variant: FeatureFlagVariantOff},
+			{Header: "X-Beta", Values: []string{"true"}},
+			{Claim: "roles", Values: []string{"admin*"}, Variant: "blue"},
+			{Domains: []string{"*.beta.ut"}},
+		},
+	}
+
+	// no rule matched, excluded by rollout
+	res := flag.evaluate(&FeatureFlagContext{SubjectKey: "ut-user"})
+	assert.False(t, res.Enabled)
+	assert.Equal(t, FeatureFlagReasonRollout, res.Reason)
+
+	// header
+	headers := http.Header{}
+	headers.Set("X-Beta", "true")
+	res = flag.evaluate(&FeatureFlagContext{Headers: headers})
+	assert.True(t, res.Enabled)
+	assert.Equal(t, "blue", res.Variant)
+	assert.Equal(t, FeatureFlagReasonRule, res.Reason)
+
+	// claim with list
+	res = flag.evaluate(&FeatureFlagContext{Claims: map[string]interface{}{"roles": []interface{}{"user", "admin-ro"}}})
+	assert.True(t, res.Enabled)
+	assert.Equal(t, "blue", res.Variant)
+
+	// domain
+	assert.True(t, flag.evaluate(&FeatureFlagContext{Domain: "api.beta.ut"}).Enabled)
+
+	// first matched rule wins
+	res = flag.evaluate(&FeatureFlagContext{Headers: headers, Domain: "api.blocked.ut"})
+	assert.False(t, res.Enabled)
+	assert.Equal(t, FeatureFlagReasonRule, res.Reason)
+}
+
+func TestFeatureFlagEntry_Evaluate(t *testing.T) {
+	entry := &FeatureFlagEntry{
+		entryName: "ut-flags",
+		flags: map[string]*FeatureFlag{
+			"ut": {Name: "ut", Enabled: true},
+		},
+		counts: map[featureFlagCountKey]int64{},
+	}
+
+	evaluator := entry.Evaluator(&FeatureFlagContext{SubjectKey: "ut-user"})
+	assert.True(t, evaluator.IsEnabled("ut"))
+	assert.Equal(t, FeatureFlagVariantOn, evaluator.Variant("ut"))
+	assert.Equal(t, "ut-user", evaluator.GetContext().SubjectKey)
+
+	// missing flag
+	res := entry.Evaluate("ut-missing", nil)
+	assert.False(t, res.Enabled)
+	assert.Equal(t, FeatureFlagReasonMissing, res.Reason)
+	assert.Equal(t, "ut-missing", res.Flag)
+
+	// missing flags are counted under one name
+	entry.Evaluate("ut-missing-other", nil)
+
+	assert.Equal(t, map[string]map[string]int64{
+		"ut":                    {"on": 2},
+		FeatureFlagCountMissing: {"off": 2},
+	}, entry.GetEvaluationCounts())
+
+	// nil evaluator
+	var nilEvaluator *FeatureFlagEvaluator
+	assert.False(t, nilEvaluator.IsEnabled("ut"))
+	assert.Nil(t, nilEvaluator.GetContext())
+
+	// exported by collector
+	appCtx := NewAppContext()
+	appCtx.AddEntry(entry)
+	registry := prometheus.NewRegistry()
+	assert.Nil(t, registry.Register(newFeatureFlagCollector(appCtx)))
+
+	families, err := registry.Gather()
+	assert.Nil(t, err)
+	assert.Len(t, families, 1)
+	assert.Equal(t, "rk_feature_flag_evaluations_total", families[0].GetName())
+	assert.Len(t, families[0].GetMetric(), 2)
+}
@@ -21,7 +21,7 @@ func validAlgorithm(e string, list []string) bool {
 }
 
 // RegisterSymmetricJwtSigner create symmetricJwtSigner
-func RegisterSymmetricJwtSigner(entryName, algo string, rawKey []byte) *symmetricJwtSigner {
+func RegisterSymmetricJwtSigner(entryName, algo string, rawKey []byte, opts ...RegOption) *symmetricJwtSigner {
 	res := &symmetricJwtSigner{
 		entryName: entryName,
 	}
@@ -42,7 +42,7 @@ func RegisterSymmetricJwtSigner(entryName, algo string, rawKey []byte) *symmetri
 		res.SigningMethod = jwt.SigningMethodHS512
 	}
 
-	GlobalAppCtx.AddEntry(res)
+	NewRegOptions(opts...).AppCtx.AddEntry(res)
 
 	return res
 }
@@ -130,14 +130,28 @@ func (s *symmetricJwtSigner) Algorithms() []string {
 	}
 }
 
-// RegisterAsymmetricJwtSigner create asymmetricJwtSigner
-func RegisterAsymmetricJwtSigner(entryName, algo string, privPEM, pubPEM []byte) *asymmetricJwtSigner {
+// RegisterAsymmetricJwtSigner create asymmetricJwtSigner, nil will be returned if algorithm is not supported
+func RegisterAsymmetricJwtSigner(entryName, algo string, privPEM, pubPEM []byte, opts ...RegOption) *asymmetricJwtSigner {
+	if !validAlgorithm(algo, (&asymmetricJwtSigner{}).Algorithms()) {
+		return nil
+	}
+
+	res, err := RegisterAsymmetricJwtSignerE(entryName, algo, privPEM, pubPEM, opts...)
+	if err != nil {
+		ShutdownWithError(err)
+	}
+
+	return res
+}
+
+// RegisterAsymmetricJwtSignerE is the same as RegisterAsymmetricJwtSigner but returns error instead of panic.
+func RegisterAsymmetricJwtSignerE(entryName, algo string, privPEM, pubPEM []byte, opts ...RegOption) (*asymmetricJwtSigner, error) {
 	res := &asymmetricJwtSigner{
 		entryName: entryName,
 	}
 
 	if !validAlgorithm(algo, res.Algorithms()) {
-		return nil
+		return nil, fmt.Errorf("unsupported jwt signing algorithm=%s", algo)
 	}
 
 	res.Algorithm = algo
@@ -146,12 +160,12 @@ func RegisterAsymmetricJwtSigner(entryName, algo string, privPEM, pubPEM []byte)
 	case jwt.SigningMethodRS256.Name, jwt.SigningMethodRS384.Name, jwt.SigningMethodRS512.Name:
 		parsedPrivKey, err := jwt.ParseRSAPrivateKeyFromPEM(privPEM)
 		if err != nil {
-			ShutdownWithError(err)
+			return nil, err
 		}
 
 		parsedPubKey, err := jwt.ParseRSAPublicKeyFromPEM(pubPEM)
 		if err != nil {
-			ShutdownWithError(err)
+			return nil, err
 		}
 		res.privKey = parsedPrivKey
 		res.pubKey = parsedPubKey
@@ -168,12 +182,12 @@ func RegisterAsymmetricJwtSigner(entryName, algo string, privPEM, pubPEM []byte)
 	case jwt.SigningMethodES256.Name, jwt.SigningMethodES384.Name, jwt.SigningMethodES512.Name:
 		parsedPrivKey, err := jwt.ParseECPrivateKeyFromPEM(privPEM)
 		if err != nil {
-			ShutdownWithError(err)
+			return nil, err
 		}
 
 		parsedPubKey, err := jwt.ParseECPublicKeyFromPEM(pubPEM)
 		if err != nil {
-			ShutdownWithError(err)
+			return nil, err
 		}
 		res.privKey = parsedPrivKey
 		res.pubKey = parsedPubKey
@@ -188,9 +202,9 @@ func RegisterAsymmetricJwtSigner(entryName, algo string, privPEM, pubPEM []byte)
 		}
 	}
 
-	GlobalAppCtx.AddEntry(res)
+	NewRegOptions(opts...).AppCtx.AddEntry(res)
 
-	return res
+	return res, nil
 }
 
 // asymmetricJwtSigner a signer which will use asymmetric key
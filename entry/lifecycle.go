@@ -0,0 +1,251 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkentry
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// EntryState is lifecycle state of entry tracked by AppContext.
+//
+//	Registered -> Bootstrapping -> Running <-> Degraded
+//	                    |             |            |
+//	                    v             v            v
+//	                  Failed  <->  Stopping  ->  Stopped
+//
+// Entry could be bootstrapped again from Stopped or Failed.
+type EntryState string
+
+const (
+	// EntryStateRegistered entry was added into AppContext but not bootstrapped yet
+	EntryStateRegistered EntryState = "Registered"
+	// EntryStateBootstrapping entry is bootstrapping
+	EntryStateBootstrapping EntryState = "Bootstrapping"
+	// EntryStateRunning entry bootstrapped successfully
+	EntryStateRunning EntryState = "Running"
+	// EntryStateDegraded entry is running but partially functional, reported by entry itself
+	EntryStateDegraded EntryState = "Degraded"
+	// EntryStateStopping entry is interrupting
+	EntryStateStopping EntryState = "Stopping"
+	// EntryStateStopped entry interrupted
+	EntryStateStopped EntryState = "Stopped"
+	// EntryStateFailed entry failed to bootstrap or run
+	EntryStateFailed EntryState = "Failed"
+)
+
+// EntryStates list all EntryState in lifecycle order.
+var EntryStates = []EntryState{
+	EntryStateRegistered,
+	EntryStateBootstrapping,
+	EntryStateRunning,
+	EntryStateDegraded,
+	EntryStateStopping,
+	EntryStateStopped,
+	EntryStateFailed,
+}
+
+var entryStateTransitions = map[EntryState][]EntryState{
+	EntryStateRegistered:    {EntryStateBootstrapping, EntryStateStopping, EntryStateFailed},
+	EntryStateBootstrapping: {EntryStateRunning, EntryStateDegraded, EntryStateFailed},
+	EntryStateRunning:       {EntryStateDegraded, EntryStateStopping, EntryStateFailed},
+	EntryStateDegraded:      {EntryStateRunning, EntryStateStopping, EntryStateFailed},
+	EntryStateStopping:      {EntryStateStopped, EntryStateFailed},
+	EntryStateStopped:       {EntryStateBootstrapping},
+	EntryStateFailed:        {EntryStateBootstrapping, EntryStateStopping},
+}
+
+// CanTransitTo returns true if state could be changed into next.
+//
+// Transition to the same state is always allowed which refresh timestamp and last error.
+func (s EntryState) CanTransitTo(next EntryState) bool {
+	if s == next {
+		return true
+	}
+
+	for _, v := range entryStateTransitions[s] {
+		if v == next {
+			return true
+		}
+	}
+
+	return false
+}
+
+// EntryOptional could be implemented by entries which should not affect readiness of application.
+type EntryOptional interface {
+	IsOptional() bool
+}
+
+// EntryStatus is lifecycle status of entry.
+type EntryStatus struct {
+	EntryType  string                   `json:"entryType" yaml:"entryType" example:"LoggerEntry"`
+	EntryName  string                   `json:"entryName" yaml:"entryName" example:"logger"`
+	State      EntryState               `json:"state" yaml:"state" example:"Running"`
+	Required   bool                     `json:"required" yaml:"required" example:"true"`
+	UpdatedAt  time.Time                `json:"updatedAt" yaml:"updatedAt" example:"2022-03-15T20:43:05+08:00"`
+	Timestamps map[EntryState]time.Time `json:"timestamps" yaml:"timestamps"`
+	LastError  string                   `json:"lastError,omitempty" yaml:"lastError,omitempty"`
+}
+
+// IsReady returns true if entry is running or not required.
+//
+// Entries which are still Registered were not bootstrapped through AppContext and are not considered.
+func (s *EntryStatus) IsReady() bool {
+	return !s.Required || s.State == EntryStateRunning || s.State == EntryStateRegistered
+}
+
+// copy returns deep copy of status.
+func (s *EntryStatus) copy() *EntryStatus {
+	res := *s
+	res.Timestamps = make(map[EntryState]time.Time, len(s.Timestamps))
+	for k, v := range s.Timestamps {
+		res.Timestamps[k] = v
+	}
+
+	return &res
+}
+
+// entryLifecycle stores EntryStatus of entries in AppContext.
+type entryLifecycle struct {
+	lock     sync.RWMutex
+	statuses map[EntryRef]*EntryStatus
+	// strictReady treats required entries which are still Registered as not ready
+	strictReady bool
+}
+
+func newEntryLifecycle() *entryLifecycle {
+	return &entryLifecycle{
+		statuses: make(map[EntryRef]*EntryStatus),
+	}
+}
+
+// SetEntryState change lifecycle state of entry.
+//
+// Entries could report EntryStateDegraded or EntryStateFailed by themselves while running.
+// Error will be returned if transition is not allowed, see EntryState for details.
+func (ctx *AppContext) SetEntryState(entry Entry, state EntryState, err error) error {
+	if entry == nil {
+		return nil
+	}
+
+	ctx.lifecycle.lock.Lock()
+	defer ctx.lifecycle.lock.Unlock()
+
+	ref := refOf(entry)
+	status, ok := ctx.lifecycle.statuses[ref]
+	if !ok {
+		status = &EntryStatus{
+			EntryType:  ref.Type,
+			EntryName:  ref.Name,
+			State:      EntryStateRegistered,
+			Required:   true,
+			Timestamps: make(map[EntryState]time.Time),
+		}
+
+		if v, ok := entry.(EntryOptional); ok && v.IsOptional() {
+			status.Required = false
+		}
+
+		ctx.lifecycle.statuses[ref] = status
+	}
+
+	if !status.State.CanTransitTo(state) {
+		return fmt.Errorf("invalid state transition of %s, %s -> %s", ref, status.State, state)
+	}
+
+	now := time.Now()
+	status.State = state
+	status.UpdatedAt = now
+	status.Timestamps[state] = now
+
+	if err != nil {
+		status.LastError = err.Error()
+	}
+
+	return nil
+}
+
+// SetEntryRequired mark entry as required or not, required entries must be running to make application ready.
+func (ctx *AppContext) SetEntryRequired(entryType, entryName string, required bool) {
+	ctx.lifecycle.lock.Lock()
+	defer ctx.lifecycle.lock.Unlock()
+
+	if v, ok := ctx.lifecycle.statuses[EntryRef{Type: entryType, Name: entryName}]; ok {
+		v.Required = required
+	}
+}
+
+// GetEntryStatus returns copy of EntryStatus, nil will be returned if entry is not tracked.
+func (ctx *AppContext) GetEntryStatus(entryType, entryName string) *EntryStatus {
+	ctx.lifecycle.lock.RLock()
+	defer ctx.lifecycle.lock.RUnlock()
+
+	if v, ok := ctx.lifecycle.statuses[EntryRef{Type: entryType, Name: entryName}]; ok {
+		return v.copy()
+	}
+
+	return nil
+}
+
+// ListEntryStatuses returns copy of EntryStatus of all entries, sorted by entry type and entry name.
+func (ctx *AppContext) ListEntryStatuses() []*EntryStatus {
+	ctx.lifecycle.lock.RLock()
+	defer ctx.lifecycle.lock.RUnlock()
+
+	res := make([]*EntryStatus, 0, len(ctx.lifecycle.statuses))
+	for _, v := range ctx.lifecycle.statuses {
+		res = append(res, v.copy())
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].EntryType != res[j].EntryType {
+			return res[i].EntryType < res[j].EntryType
+		}
+		return res[i].EntryName < res[j].EntryName
+	})
+
+	return res
+}
+
+// WithStrictReadinessAppContext treat required entries which are still Registered as not ready.
+//
+// Entries bootstrapped outside of AppContext stay Registered, mark them with SetEntryRequired() if strict readiness
+// is enabled and they should not affect readiness.
+func WithStrictReadinessAppContext() AppContextOption {
+	return func(ctx *AppContext) {
+		ctx.lifecycle.strictReady = true
+	}
+}
+
+// IsEntriesReady returns true if all required entries are running, entries not ready will be returned as well.
+//
+// Required entries which are still Registered are not ready only if WithStrictReadinessAppContext() is provided.
+func (ctx *AppContext) IsEntriesReady() (bool, []*EntryStatus) {
+	notReady := make([]*EntryStatus, 0)
+
+	for _, v := range ctx.ListEntryStatuses() {
+		if !v.IsReady() || (ctx.lifecycle.strictReady && v.Required && v.State == EntryStateRegistered) {
+			notReady = append(notReady, v)
+		}
+	}
+
+	return len(notReady) < 1, notReady
+}
+
+// removeEntryStatus remove status of entries matches filter.
+func (ctx *AppContext) removeEntryStatus(filter func(ref EntryRef) bool) {
+	ctx.lifecycle.lock.Lock()
+	defer ctx.lifecycle.lock.Unlock()
+
+	for k := range ctx.lifecycle.statuses {
+		if filter(k) {
+			delete(ctx.lifecycle.statuses, k)
+		}
+	}
+}
@@ -0,0 +1,190 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkentry
+
+import (
+	"context"
+	"errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestEntryState_CanTransitTo(t *testing.T) {
+	assert.True(t, EntryStateRegistered.CanTransitTo(EntryStateBootstrapping))
+	assert.True(t, EntryStateBootstrapping.CanTransitTo(EntryStateRunning))
+	assert.True(t, EntryStateRunning.CanTransitTo(EntryStateDegraded))
+	assert.True(t, EntryStateDegraded.CanTransitTo(EntryStateRunning))
+	assert.True(t, EntryStateRunning.CanTransitTo(EntryStateStopping))
+	assert.True(t, EntryStateStopping.CanTransitTo(EntryStateStopped))
+	assert.True(t, EntryStateStopped.CanTransitTo(EntryStateBootstrapping))
+	assert.True(t, EntryStateFailed.CanTransitTo(EntryStateBootstrapping))
+	assert.True(t, EntryStateRunning.CanTransitTo(EntryStateRunning))
+
+	assert.False(t, EntryStateRegistered.CanTransitTo(EntryStateRunning))
+	assert.False(t, EntryStateStopped.CanTransitTo(EntryStateRunning))
+	assert.False(t, EntryStateRunning.CanTransitTo(EntryStateRegistered))
+}
+
+func TestAppContext_SetEntryState(t *testing.T) {
+	ctx := NewAppContext()
+	entry := &EntryMock{Name: "ut-entry"}
+
+	// with nil entry
+	assert.Nil(t, ctx.SetEntryState(nil, EntryStateRunning, nil))
+
+	ctx.AddEntry(entry)
+	status := ctx.GetEntryStatus("mock", "ut-entry")
+	assert.Equal(t, EntryStateRegistered, status.State)
+	assert.True(t, status.Required)
+	assert.False(t, status.Timestamps[EntryStateRegistered].IsZero())
+
+	// with invalid transition
+	assert.NotNil(t, ctx.SetEntryState(entry, EntryStateRunning, nil))
+	assert.Equal(t, EntryStateRegistered, ctx.GetEntryStatus("mock", "ut-entry").State)
+
+	// with error
+	assert.Nil(t, ctx.SetEntryState(entry, EntryStateFailed, errors.New("ut-error")))
+	status = ctx.GetEntryStatus("mock", "ut-entry")
+	assert.Equal(t, EntryStateFailed, status.State)
+	assert.Equal(t, "ut-error", status.LastError)
+
+	// returned status is a copy
+	status.State = EntryStateRunning
+	assert.Equal(t, EntryStateFailed, ctx.GetEntryStatus("mock", "ut-entry").State)
+
+	// remove entry
+	ctx.RemoveEntry(entry)
+	assert.Nil(t, ctx.GetEntryStatus("mock", "ut-entry"))
+	assert.Nil(t, ctx.GetEntryStatus("mock", "non-exist"))
+}
+
+func TestAppContext_EntryLifecycle(t *testing.T) {
+	ctx := NewAppContext()
+	entry := &EntryMock{Name: "ut-entry"}
+	ctx.AddEntry(entry)
+
+	assert.Nil(t, ctx.BootstrapEntries(context.Background(), []Entry{entry}))
+	status := ctx.GetEntryStatus("mock", "ut-entry")
+	assert.Equal(t, EntryStateRunning, status.State)
+	assert.False(t, status.Timestamps[EntryStateBootstrapping].IsZero())
+	assert.False(t, status.Timestamps[EntryStateRunning].IsZero())
+
+	ready, notReady := ctx.IsEntriesReady()
+	assert.True(t, ready)
+	assert.Empty(t, notReady)
+
+	// report degraded by entry itself
+	assert.Nil(t, ctx.SetEntryState(entry, EntryStateDegraded, errors.New("ut-degraded")))
+	ready, notReady = ctx.IsEntriesReady()
+	assert.False(t, ready)
+	assert.Len(t, notReady, 1)
+
+	assert.Nil(t, ctx.InterruptEntries(context.Background(), []Entry{entry}))
+	status = ctx.GetEntryStatus("mock", "ut-entry")
+	assert.Equal(t, EntryStateStopped, status.State)
+	assert.Equal(t, "ut-degraded", status.LastError)
+	assert.False(t, status.Timestamps[EntryStateStopping].IsZero())
+}
+
+func TestAppContext_IsEntriesReady_WithStrictReadiness(t *testing.T) {
+	// registered entry is not considered by default
+	ctx := NewAppContext()
+	ctx.AddEntry(&EntryMock{Name: "ut-entry"})
+	ready, _ := ctx.IsEntriesReady()
+	assert.True(t, ready)
+
+	// registered entry is not ready with strict readiness
+	ctx = NewAppContext(WithStrictReadinessAppContext())
+	entry := &EntryMock{Name: "ut-entry"}
+	ctx.AddEntry(entry)
+	ready, notReady := ctx.IsEntriesReady()
+	assert.False(t, ready)
+	assert.Len(t, notReady, 1)
+
+	assert.Nil(t, ctx.BootstrapEntries(context.Background(), []Entry{entry}))
+	ready, _ = ctx.IsEntriesReady()
+	assert.True(t, ready)
+
+	// entry bootstrapped outside of AppContext could be marked as not required
+	ctx = NewAppContext(WithStrictReadinessAppContext())
+	ctx.AddEntry(&EntryMock{Name: "ut-entry"})
+	ctx.SetEntryRequired("mock", "ut-entry", false)
+	ready, _ = ctx.IsEntriesReady()
+	assert.True(t, ready)
+}
+
+func TestAppContext_EntryLifecycle_WithFailure(t *testing.T) {
+	ctx := NewAppContext()
+
+	// with error
+	failed := &entryEMock{EntryMock: EntryMock{Name: "ut-failed"}, err: errors.New("ut-error")}
+	assert.NotNil(t, ctx.BootstrapEntries(context.Background(), []Entry{failed}))
+	status := ctx.GetEntryStatus("mock", "ut-failed")
+	assert.Equal(t, EntryStateFailed, status.State)
+	assert.Equal(t, "ut-error", status.LastError)
+
+	// with panic
+	panicked := &entryEMock{EntryMock: EntryMock{Name: "ut-panic"}, panic: true}
+	assert.Panics(t, func() {
+		ctx.BootstrapEntries(context.Background(), []Entry{panicked})
+	})
+	status = ctx.GetEntryStatus("mock", "ut-panic")
+	assert.Equal(t, EntryStateFailed, status.State)
+	assert.Contains(t, status.LastError, "panic")
+
+	// with optional entry
+	optional := &entryEMock{EntryMock: EntryMock{Name: "ut-optional"}, err: errors.New("ut-error"), optional: true}
+	ctx.RemoveEntryByType("mock")
+	assert.NotNil(t, ctx.BootstrapEntries(context.Background(), []Entry{optional}))
+	assert.False(t, ctx.GetEntryStatus("mock", "ut-optional").Required)
+	ready, _ := ctx.IsEntriesReady()
+	assert.True(t, ready)
+}
+
+func TestEntryStatusCollector(t *testing.T) {
+	ctx := NewAppContext()
+	entry := &EntryMock{Name: "ut-entry"}
+	ctx.AddEntry(entry)
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(newEntryStatusCollector(ctx))
+
+	expected := `
+# HELP rk_entry_state Lifecycle state of entry, 1 for current state.
+# TYPE rk_entry_state gauge
+rk_entry_state{entry_name="ut-entry",entry_type="mock",state="Bootstrapping"} 0
+rk_entry_state{entry_name="ut-entry",entry_type="mock",state="Degraded"} 0
+rk_entry_state{entry_name="ut-entry",entry_type="mock",state="Failed"} 0
+rk_entry_state{entry_name="ut-entry",entry_type="mock",state="Registered"} 1
+rk_entry_state{entry_name="ut-entry",entry_type="mock",state="Running"} 0
+rk_entry_state{entry_name="ut-entry",entry_type="mock",state="Stopped"} 0
+rk_entry_state{entry_name="ut-entry",entry_type="mock",state="Stopping"} 0
+`
+	assert.Nil(t, testutil.GatherAndCompare(registry, strings.NewReader(expected), "rk_entry_state"))
+	assert.Equal(t, len(EntryStates)+1, testutil.CollectAndCount(newEntryStatusCollector(ctx)))
+}
+
+type entryEMock struct {
+	EntryMock
+	err      error
+	panic    bool
+	optional bool
+}
+
+func (entry *entryEMock) BootstrapE(context.Context) error {
+	if entry.panic {
+		panic("ut-panic")
+	}
+
+	return entry.err
+}
+
+func (entry *entryEMock) IsOptional() bool {
+	return entry.optional
+}
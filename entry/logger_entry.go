@@ -43,35 +43,25 @@ func NewLoggerEntryStdout() *LoggerEntry {
 }
 
 // RegisterLoggerEntry create event logger entry with options.
-func RegisterLoggerEntry(boot *BootLogger) []*LoggerEntry {
-	res := make([]*LoggerEntry, 0)
-
-	// filter out based domain
-	configMap := make(map[string]*BootLoggerE)
-	for _, config := range boot.Logger {
-		if len(config.Name) < 1 {
-			continue
-		}
-
-		if !IsValidDomain(config.Domain) {
-			continue
-		}
+func RegisterLoggerEntry(boot *BootLogger, opts ...RegOption) []*LoggerEntry {
+	res, err := RegisterLoggerEntryE(boot, opts...)
+	if err != nil {
+		ShutdownWithError(err)
+	}
 
-		// * or matching domain
-		// 1: add it to map if missing
-		if _, ok := configMap[config.Name]; !ok {
-			configMap[config.Name] = config
-			continue
-		}
+	return res
+}
 
-		// 2: already has an entry, then compare domain,
-		//    only one case would occur, previous one is already the correct one, continue
-		if config.Domain == "" || config.Domain == "*" {
-			continue
-		}
+// RegisterLoggerEntryE is the same as RegisterLoggerEntry but returns error instead of panic.
+func RegisterLoggerEntryE(boot *BootLogger, opts ...RegOption) ([]*LoggerEntry, error) {
+	appCtx := NewRegOptions(opts...).AppCtx
+	res := make([]*LoggerEntry, 0)
+	bootErr := &BootConfigError{}
 
-		configMap[config.Name] = config
-	}
+	// filter out based domain
+	configMap := filterByDomain(boot.Logger, func(config *BootLoggerE) (string, string) {
+		return config.Name, config.Domain
+	})
 
 	for _, logger := range configMap {
 		entry := &LoggerEntry{
@@ -79,6 +69,7 @@ func RegisterLoggerEntry(boot *BootLogger) []*LoggerEntry {
 			entryType:        LoggerEntryType,
 			entryDescription: logger.Description,
 			IsDefault:        logger.Default,
+			appCtx:           appCtx,
 		}
 
 		// Assign default zap config and lumberjack config
@@ -110,8 +101,8 @@ func RegisterLoggerEntry(boot *BootLogger) []*LoggerEntry {
 			// default labels
 			opts = append(opts,
 				rklogger.WithLokiLabel(rkmid.Domain.Key, rkmid.Domain.String),
-				rklogger.WithLokiLabel("app_name", GlobalAppCtx.GetAppInfoEntry().AppName),
-				rklogger.WithLokiLabel("app_version", GlobalAppCtx.GetAppInfoEntry().Version),
+				rklogger.WithLokiLabel("app_name", appCtx.GetAppInfoEntry().AppName),
+				rklogger.WithLokiLabel("app_version", appCtx.GetAppInfoEntry().Version),
 				rklogger.WithLokiLabel("logger_type", "zap"),
 			)
 
@@ -129,7 +120,8 @@ func RegisterLoggerEntry(boot *BootLogger) []*LoggerEntry {
 		zapLogger, err := rklogger.NewZapLoggerWithConfAndSyncer(zapLoggerConfig, zapLoggerLumberjackConfig, syncers, zap.AddCaller())
 
 		if err != nil {
-			ShutdownWithError(err)
+			bootErr.Add(bootPath("logger", boot.Logger, logger), err)
+			continue
 		}
 
 		entry.Logger = zapLogger
@@ -137,27 +129,39 @@ func RegisterLoggerEntry(boot *BootLogger) []*LoggerEntry {
 		entry.LumberjackConfig = zapLoggerLumberjackConfig
 		entry.lokiSyncer = lokiSyncer
 
-		GlobalAppCtx.AddEntry(entry)
+		appCtx.AddEntry(entry)
 		res = append(res, entry)
 	}
 
-	return res
+	return res, bootErr.ErrorOrNil()
 }
 
 // RegisterLoggerEntryYAML register function
 func RegisterLoggerEntryYAML(raw []byte) map[string]Entry {
+	res, err := RegisterLoggerEntryYAMLE(raw)
+	if err != nil {
+		ShutdownWithError(err)
+	}
+
+	return res
+}
+
+// RegisterLoggerEntryYAMLE is the same as RegisterLoggerEntryYAML but returns error instead of panic.
+func RegisterLoggerEntryYAMLE(raw []byte, opts ...RegOption) (map[string]Entry, error) {
 	boot := &BootLogger{}
-	UnmarshalBootYAML(raw, boot)
+	if err := unmarshalBootYAMLE(NewRegOptions(opts...).AppCtx, raw, boot); err != nil {
+		return nil, err
+	}
 
 	res := map[string]Entry{}
 
-	entries := RegisterLoggerEntry(boot)
+	entries, err := RegisterLoggerEntryE(boot, opts...)
 	for i := range entries {
 		entry := entries[i]
 		res[entry.GetName()] = entry
 	}
 
-	return res
+	return res, err
 }
 
 // BootLogger bootstrap config of Zap Logger information.
@@ -186,6 +190,7 @@ type LoggerEntry struct {
 	LoggerConfig     *zap.Config          `yaml:"-" json:"-"`
 	LumberjackConfig *lumberjack.Logger   `yaml:"-" json:"-"`
 	lokiSyncer       *rklogger.LokiSyncer `yaml:"-" json:"-"`
+	appCtx           *AppContext          `yaml:"-" json:"-"`
 	bootstrapOnce    sync.Once            `yaml:"-" json:"-"`
 }
 
@@ -205,6 +210,32 @@ func (entry *LoggerEntry) Interrupt(ctx context.Context) {
 	}
 }
 
+// Reload implements Reloadable, only log level would be applied, the others require restart.
+func (entry *LoggerEntry) Reload(_ context.Context, raw []byte) error {
+	if entry.LoggerConfig == nil {
+		return ErrRestartRequired
+	}
+
+	newEntry, err := reloadedEntry[*LoggerEntry](entry.appCtx, raw, entry.GetName(), RegisterLoggerEntryYAMLE)
+	if err != nil {
+		return err
+	}
+
+	entry.LoggerConfig.Level.SetLevel(newEntry.LoggerConfig.Level.Level())
+
+	return nil
+}
+
+// ShutdownPhase implements EntryShutdown, Loki syncer would be flushed with telemetry.
+func (entry *LoggerEntry) ShutdownPhase() ShutdownPhase {
+	return ShutdownPhaseFlushTelemetry
+}
+
+// ShutdownTimeout implements EntryShutdown, default timeout of AppContext is used.
+func (entry *LoggerEntry) ShutdownTimeout() time.Duration {
+	return 0
+}
+
 // GetName returns name of entry.
 func (entry *LoggerEntry) GetName() string {
 	return entry.entryName
@@ -252,6 +283,11 @@ func (entry *LoggerEntry) MarshalJSON() ([]byte, error) {
 	})
 }
 
+// IsDefaultEntry returns true if LoggerEntry was marked as default.
+func (entry *LoggerEntry) IsDefaultEntry() bool {
+	return entry.IsDefault
+}
+
 // UnmarshalJSON not supported.
 func (entry *LoggerEntry) UnmarshalJSON([]byte) error {
 	return nil
@@ -19,7 +19,46 @@ type aliveResp struct {
 
 // readyResp response of /ready
 type readyResp struct {
-	Ready bool `json:"ready" yaml:"ready" example:"true"`
+	Ready   bool           `json:"ready" yaml:"ready" example:"true"`
+	Entries []*EntryStatus `json:"entries,omitempty" yaml:"entries,omitempty"`
+	Certs   []*CertInfo    `json:"certs,omitempty" yaml:"certs,omitempty"`
+}
+
+// statusResp response of /status
+// Returns lifecycle status of entries.
+type statusResp struct {
+	Ready   bool           `json:"ready" yaml:"ready" example:"true"`
+	Entries []*EntryStatus `json:"entries" yaml:"entries"`
+}
+
+// effectiveConfigResp response of /effectiveConfig
+// Returns merged boot config with source of each value.
+type effectiveConfigResp struct {
+	Values []*EffectiveValue `json:"values" yaml:"values"`
+}
+
+// configResp response of /config
+// Returns current values of config entries.
+type configResp struct {
+	Entries []*configEntryValues `json:"entries" yaml:"entries"`
+}
+
+// configEntryValues is current values of ConfigEntry with layer of each key.
+type configEntryValues struct {
+	EntryName string            `json:"entryName" yaml:"entryName" example:"my-config"`
+	Values    []*EffectiveValue `json:"values" yaml:"values"`
+}
+
+// configHistoryResp response of /config/history
+// Returns config changes at runtime, oldest first.
+type configHistoryResp struct {
+	Changes []*ConfigChange `json:"changes" yaml:"changes"`
+}
+
+// featureFlagsResp response of /featureFlags
+// Returns flags and evaluation counts of feature flag entries.
+type featureFlagsResp struct {
+	Entries []*FeatureFlagState `json:"entries" yaml:"entries"`
 }
 
 // gcResp response of /gc
@@ -56,6 +95,11 @@ type ProcessInfo struct {
 
 // NewProcessInfo creates a new ProcessInfo instance
 func NewProcessInfo() *ProcessInfo {
+	return newProcessInfo(GlobalAppCtx)
+}
+
+// newProcessInfo creates a new ProcessInfo instance with AppContext
+func newProcessInfo(ctx *AppContext) *ProcessInfo {
 	u, err := user.Current()
 	// Assign unknown value to user in order to prevent panic
 	if err != nil {
@@ -67,18 +111,18 @@ func NewProcessInfo() *ProcessInfo {
 	}
 
 	return &ProcessInfo{
-		AppName:     GlobalAppCtx.GetAppInfoEntry().AppName,
-		Version:     GlobalAppCtx.GetAppInfoEntry().Version,
-		Description: GlobalAppCtx.GetAppInfoEntry().GetDescription(),
-		Keywords:    GlobalAppCtx.GetAppInfoEntry().Keywords,
-		HomeUrl:     GlobalAppCtx.GetAppInfoEntry().HomeUrl,
-		DocsUrl:     GlobalAppCtx.GetAppInfoEntry().DocsUrl,
-		Maintainers: GlobalAppCtx.GetAppInfoEntry().Maintainers,
+		AppName:     ctx.GetAppInfoEntry().AppName,
+		Version:     ctx.GetAppInfoEntry().Version,
+		Description: ctx.GetAppInfoEntry().GetDescription(),
+		Keywords:    ctx.GetAppInfoEntry().Keywords,
+		HomeUrl:     ctx.GetAppInfoEntry().HomeUrl,
+		DocsUrl:     ctx.GetAppInfoEntry().DocsUrl,
+		Maintainers: ctx.GetAppInfoEntry().Maintainers,
 		Username:    u.Name,
 		UID:         u.Uid,
 		GID:         u.Gid,
-		StartTime:   GlobalAppCtx.GetStartTime().Format(time.RFC3339),
-		UpTimeSec:   int64(GlobalAppCtx.GetUpTime().Seconds()),
+		StartTime:   ctx.GetStartTime().Format(time.RFC3339),
+		UpTimeSec:   int64(ctx.GetUpTime().Seconds()),
 		Realm:       getDefaultIfEmptyString(os.Getenv("REALM"), ""),
 		Region:      getDefaultIfEmptyString(os.Getenv("REGION"), ""),
 		AZ:          getDefaultIfEmptyString(os.Getenv("AZ"), ""),
@@ -3,7 +3,6 @@ package rkentry
 import (
 	"context"
 	"crypto/tls"
-	"crypto/x509"
 	"encoding/json"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/collectors"
@@ -22,6 +21,15 @@ func WithRegistryPromEntry(registry *prometheus.Registry) PromEntryOption {
 	}
 }
 
+// WithAppCtxPromEntry provide AppContext which CertEntry and LoggerEntry of pusher would be looked up from
+func WithAppCtxPromEntry(ctx *AppContext) PromEntryOption {
+	return func(entry *PromEntry) {
+		if ctx != nil {
+			entry.appCtx = ctx
+		}
+	}
+}
+
 // RegisterPromEntry Create a prom entry with options and add prom entry to rkentry.GlobalAppCtx
 func RegisterPromEntry(boot *BootProm, opts ...PromEntryOption) *PromEntry {
 	if !boot.Enabled {
@@ -35,6 +43,7 @@ func RegisterPromEntry(boot *BootProm, opts ...PromEntryOption) *PromEntry {
 		Path:             boot.Path,
 		Registerer:       prometheus.DefaultRegisterer,
 		Gatherer:         prometheus.DefaultGatherer,
+		appCtx:           GlobalAppCtx,
 	}
 
 	for i := range opts {
@@ -45,6 +54,9 @@ func RegisterPromEntry(boot *BootProm, opts ...PromEntryOption) *PromEntry {
 		entry.Registry = prometheus.NewRegistry()
 	}
 	entry.Registry.Register(collectors.NewGoCollector())
+	entry.Registry.Register(newEntryStatusCollector(entry.appCtx))
+	entry.Registry.Register(newFeatureFlagCollector(entry.appCtx))
+	entry.Registry.Register(newCertExpiryCollector(entry.appCtx))
 
 	if entry.Registry != nil {
 		entry.Registerer = entry.Registry
@@ -63,7 +75,7 @@ func RegisterPromEntry(boot *BootProm, opts ...PromEntryOption) *PromEntry {
 		entry.Path = "/" + entry.Path
 	}
 
-	entry.Pusher = newPushGatewayPusher(boot, entry.Gatherer)
+	entry.Pusher = newPushGatewayPusher(boot, entry.Gatherer, entry.appCtx)
 
 	return entry
 }
@@ -94,6 +106,7 @@ type PromEntry struct {
 	entryDescription string             `json:"-" yaml:"-"`
 	Path             string             `json:"-" yaml:"-"`
 	Pusher           *PushGatewayPusher `json:"-" yaml:"-"`
+	appCtx           *AppContext        `json:"-" yaml:"-"`
 }
 
 type PromEntryOption func(entry *PromEntry)
@@ -113,6 +126,16 @@ func (entry *PromEntry) Interrupt(ctx context.Context) {
 	}
 }
 
+// ShutdownPhase implements EntryShutdown, pushGateway pusher would be flushed with telemetry.
+func (entry *PromEntry) ShutdownPhase() ShutdownPhase {
+	return ShutdownPhaseFlushTelemetry
+}
+
+// ShutdownTimeout implements EntryShutdown, default timeout of AppContext is used.
+func (entry *PromEntry) ShutdownTimeout() time.Duration {
+	return 0
+}
+
 // GetName Return name of prom entry
 func (entry *PromEntry) GetName() string {
 	return entry.entryName
@@ -151,6 +174,23 @@ func (entry *PromEntry) UnmarshalJSON([]byte) error {
 	return nil
 }
 
+// DependsOn returns CertEntry and LoggerEntry used by pusher
+func (entry *PromEntry) DependsOn() []EntryRef {
+	res := make([]EntryRef, 0)
+
+	if entry.Pusher != nil {
+		if entry.Pusher.certEntry != nil {
+			res = append(res, refOf(entry.Pusher.certEntry))
+		}
+
+		if entry.Pusher.loggerEntry != nil {
+			res = append(res, refOf(entry.Pusher.loggerEntry))
+		}
+	}
+
+	return res
+}
+
 // RegisterCollectors Register collectors in default registry
 func (entry *PromEntry) RegisterCollectors(collectors ...prometheus.Collector) {
 	for i := range collectors {
@@ -170,12 +210,12 @@ type PushGatewayPusher struct {
 }
 
 // newPushGatewayPusher creates a new pushGateway periodic job instances with intervalMS, remote URL and job name
-func newPushGatewayPusher(boot *BootProm, gatherer prometheus.Gatherer) *PushGatewayPusher {
+func newPushGatewayPusher(boot *BootProm, gatherer prometheus.Gatherer, appCtx *AppContext) *PushGatewayPusher {
 	if !boot.Pusher.Enabled {
 		return nil
 	}
 
-	certEntry := GlobalAppCtx.GetCertEntry(boot.Pusher.CertEntry)
+	certEntry := appCtx.GetCertEntry(boot.Pusher.CertEntry)
 
 	pg := &PushGatewayPusher{
 		IntervalMs:    time.Duration(boot.Pusher.IntervalMs) * time.Millisecond,
@@ -197,7 +237,7 @@ func newPushGatewayPusher(boot *BootProm, gatherer prometheus.Gatherer) *PushGat
 		pg.JobName = "rk"
 	}
 
-	pg.loggerEntry = GlobalAppCtx.GetLoggerEntry(boot.Pusher.LoggerEntry)
+	pg.loggerEntry = appCtx.GetLoggerEntry(boot.Pusher.LoggerEntry)
 	if pg.loggerEntry == nil {
 		pg.loggerEntry = LoggerEntryStdout
 	}
@@ -222,29 +262,7 @@ func newPushGatewayPusher(boot *BootProm, gatherer prometheus.Gatherer) *PushGat
 func (pub *PushGatewayPusher) Bootstrap(ctx context.Context) {
 	httpClient := http.DefaultClient
 
-	var conf *tls.Config
-	if pub.certEntry != nil {
-		if pub.certEntry.Certificate != nil {
-			conf = &tls.Config{}
-			conf.Certificates = []tls.Certificate{
-				*pub.certEntry.Certificate,
-			}
-		}
-
-		if pub.certEntry.RootCA != nil {
-			caCert := x509.NewCertPool()
-			caCert.AddCert(pub.certEntry.RootCA)
-
-			if conf != nil {
-				conf.RootCAs = caCert
-			} else {
-				conf = &tls.Config{}
-				conf.RootCAs = caCert
-			}
-		}
-	}
-
-	if conf != nil {
+	if conf := pub.tlsConfig(); conf != nil {
 		httpClient.Transport = &http.Transport{
 			TLSClientConfig: conf,
 		}
@@ -261,6 +279,17 @@ func (pub *PushGatewayPusher) Bootstrap(ctx context.Context) {
 	go pub.push()
 }
 
+// tlsConfig returns tls.Config of cert entry, nil returned if cert entry has neither certificate nor CA,
+// so that default TLS settings of client are kept.
+func (pub *PushGatewayPusher) tlsConfig() *tls.Config {
+	if pub.certEntry == nil || !pub.certEntry.hasCertOrCA() {
+		return nil
+	}
+
+	// certificate is picked up on each handshake, so that reloaded one is used
+	return pub.certEntry.ClientTLSConfig()
+}
+
 // Interrupt stops periodic job
 func (pub *PushGatewayPusher) Interrupt(ctx context.Context) {
 	pub.running.CAS(true, false)
@@ -281,3 +310,49 @@ func (pub *PushGatewayPusher) push() {
 		time.Sleep(pub.IntervalMs)
 	}
 }
+
+// entryStatusCollector exports lifecycle status of entries in AppContext.
+//
+// rk_entry_state is 1 for current state of entry and 0 for others.
+// rk_entry_state_timestamp_seconds is unix time of latest state change.
+type entryStatusCollector struct {
+	appCtx    *AppContext
+	state     *prometheus.Desc
+	timestamp *prometheus.Desc
+}
+
+func newEntryStatusCollector(appCtx *AppContext) *entryStatusCollector {
+	return &entryStatusCollector{
+		appCtx: appCtx,
+		state: prometheus.NewDesc("rk_entry_state",
+			"Lifecycle state of entry, 1 for current state.",
+			[]string{"entry_type", "entry_name", "state"}, nil),
+		timestamp: prometheus.NewDesc("rk_entry_state_timestamp_seconds",
+			"Unix time of latest lifecycle state change of entry.",
+			[]string{"entry_type", "entry_name"}, nil),
+	}
+}
+
+// Describe implements prometheus.Collector
+func (c *entryStatusCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.state
+	ch <- c.timestamp
+}
+
+// Collect implements prometheus.Collector
+func (c *entryStatusCollector) Collect(ch chan<- prometheus.Metric) {
+	for _, status := range c.appCtx.ListEntryStatuses() {
+		for _, state := range EntryStates {
+			val := 0.0
+			if status.State == state {
+				val = 1
+			}
+
+			ch <- prometheus.MustNewConstMetric(c.state, prometheus.GaugeValue, val,
+				status.EntryType, status.EntryName, string(state))
+		}
+
+		ch <- prometheus.MustNewConstMetric(c.timestamp, prometheus.GaugeValue,
+			float64(status.UpdatedAt.UnixNano())/1e9, status.EntryType, status.EntryName)
+	}
+}
@@ -2,6 +2,7 @@ package rkentry
 
 import (
 	"context"
+	"crypto/x509"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/collectors"
 	"github.com/stretchr/testify/assert"
@@ -91,3 +92,26 @@ func TestPromEntry_Bootstrap(t *testing.T) {
 
 	entry.Interrupt(context.TODO())
 }
+
+func TestPushGatewayPusher_TLSConfig(t *testing.T) {
+	// without cert entry
+	pub := &PushGatewayPusher{}
+	assert.Nil(t, pub.tlsConfig())
+
+	// cert entry without cert and CA
+	appCtx := NewAppContext()
+	RegisterCertEntry(&BootCert{
+		Cert: []*BootCertE{
+			{
+				Name: "ut-cert",
+			},
+		},
+	}, WithAppCtx(appCtx))
+	pub.certEntry = appCtx.GetCertEntry("ut-cert")
+	assert.NotNil(t, pub.certEntry)
+	assert.Nil(t, pub.tlsConfig())
+
+	// cert entry with CA
+	pub.certEntry.RootCA = &x509.Certificate{}
+	assert.NotNil(t, pub.tlsConfig())
+}
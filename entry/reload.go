@@ -0,0 +1,231 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkentry
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/rookie-ninja/rk-entry/v2/middleware"
+	"go.uber.org/zap"
+	"os"
+	"sync/atomic"
+	"time"
+)
+
+// ErrRestartRequired could be returned (or wrapped) by Reloadable to indicate new config could only be applied after restart.
+//
+// It is the same error returned by reloadable middleware.
+var ErrRestartRequired = rkmid.ErrRestartRequired
+
+// Reloadable could be implemented by entries which could apply new boot config at runtime.
+//
+// raw is the whole boot config YAML, entries should unmarshal it with UnmarshalBootYAMLE
+// so that ENV and --rkset overrides would be applied as well.
+type Reloadable interface {
+	Reload(ctx context.Context, raw []byte) error
+}
+
+// BootSource returns raw boot config YAML, used by AppContext.Reload.
+type BootSource func() ([]byte, error)
+
+// BootSourceFromFile returns BootSource which reads boot config from file path.
+//
+// Relative path will be joined with working directory.
+func BootSourceFromFile(p string) BootSource {
+	return func() ([]byte, error) {
+		return readFileE(p, nil)
+	}
+}
+
+const (
+	ReloadStatusReloaded        = "reloaded"
+	ReloadStatusFailed          = "failed"
+	ReloadStatusRestartRequired = "restartRequired"
+)
+
+// EntryReloadReport is result of reloading an entry.
+type EntryReloadReport struct {
+	Entry  string `json:"entry" yaml:"entry"`
+	Status string `json:"status" yaml:"status"`
+	Error  string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// ReloadReport is result of AppContext.Reload.
+type ReloadReport struct {
+	StartTime time.Time            `json:"startTime" yaml:"startTime"`
+	Elapsed   time.Duration        `json:"elapsed" yaml:"elapsed"`
+	Changed   bool                 `json:"changed" yaml:"changed"`
+	Entries   []*EntryReloadReport `json:"entries" yaml:"entries"`
+}
+
+// RestartRequired returns entries which need restart to apply new boot config.
+func (r *ReloadReport) RestartRequired() []string {
+	res := make([]string, 0)
+	for _, v := range r.Entries {
+		if v.Status == ReloadStatusRestartRequired {
+			res = append(res, v.Entry)
+		}
+	}
+
+	return res
+}
+
+// SetBootSource set source of boot config which would be read again while reloading.
+func (ctx *AppContext) SetBootSource(source BootSource, raw []byte) {
+	ctx.bootSource = source
+	ctx.setBootRaw(raw)
+}
+
+// GetEffectiveConfig returns boot config merged from file, ENV and --rkset flags with source of each value.
+//
+// It is refreshed each time boot config is bootstrapped, set by SetBootSource or reloaded.
+// Nil returned if AppContext is not bootstrapped from boot config.
+func (ctx *AppContext) GetEffectiveConfig() *EffectiveConfig {
+	return ctx.bootEffective
+}
+
+// setBootRaw keep raw boot config and refresh effective config.
+func (ctx *AppContext) setBootRaw(raw []byte) {
+	ctx.bootRaw = raw
+	if effective, err := NewEffectiveConfig(raw); err == nil {
+		ctx.bootEffective = effective
+	}
+}
+
+// Reload read boot config from BootSource and reload entries in dependency order.
+//
+// Boot config is validated first, entries won't be touched if boot config is invalid.
+// Entries implement Reloadable will receive new boot config, the others will be reported as
+// restart required if boot config changed since last load.
+//
+// Report will be logged via default LoggerEntry.
+func (ctx *AppContext) Reload(c context.Context) (*ReloadReport, error) {
+	if ctx.bootSource == nil {
+		return nil, errors.New("boot source is not set, call SetBootSource() first")
+	}
+
+	raw, err := ctx.bootSource()
+	if err != nil {
+		return nil, err
+	}
+
+	// validate before touching any entry
+	if err := unmarshalBootYAMLE(ctx, raw, &map[string]interface{}{}); err != nil {
+		return nil, err
+	}
+
+	entries, err := ctx.ListEntriesSorted()
+	if err != nil {
+		return nil, err
+	}
+
+	report := &ReloadReport{
+		StartTime: time.Now(),
+		Changed:   !bytes.Equal(raw, ctx.bootRaw),
+		Entries:   make([]*EntryReloadReport, 0),
+	}
+
+	for _, entry := range entries {
+		entryReport := &EntryReloadReport{
+			Entry:  refOf(entry).String(),
+			Status: ReloadStatusReloaded,
+		}
+
+		if v, ok := entry.(Reloadable); ok {
+			if err := v.Reload(c, raw); err != nil {
+				entryReport.Status = ReloadStatusFailed
+				if errors.Is(err, ErrRestartRequired) {
+					entryReport.Status = ReloadStatusRestartRequired
+				}
+				entryReport.Error = err.Error()
+			}
+		} else if report.Changed {
+			entryReport.Status = ReloadStatusRestartRequired
+		} else {
+			continue
+		}
+
+		report.Entries = append(report.Entries, entryReport)
+	}
+
+	ctx.setBootRaw(raw)
+	report.Elapsed = time.Since(report.StartTime)
+
+	ctx.GetLoggerEntryDefault().Info("Boot config reloaded",
+		zap.Bool("changed", report.Changed),
+		zap.Duration("elapsed", report.Elapsed),
+		zap.Any("entries", report.Entries),
+		zap.Strings("restartRequired", report.RestartRequired()))
+
+	return report, nil
+}
+
+// WatchReloadSig start a goroutine which calls Reload each time reload signal received, until ctx done.
+//
+// syscall.SIGHUP of GlobalAppCtx is treated as reload signal only while watching, it shuts down application otherwise.
+func (ctx *AppContext) WatchReloadSig(c context.Context) {
+	atomic.AddInt32(&ctx.reloadWatchers, 1)
+
+	go func() {
+		defer atomic.AddInt32(&ctx.reloadWatchers, -1)
+
+		for {
+			select {
+			case <-c.Done():
+				return
+			case <-ctx.reloadSig:
+				if _, err := ctx.Reload(c); err != nil {
+					ctx.GetLoggerEntryDefault().Warn("Failed to reload boot config", zap.Error(err))
+				}
+			}
+		}
+	}()
+}
+
+// dispatchHupSig send syscall.SIGHUP to reload signal channel while WatchReloadSig is running,
+// and to shutdown signal channel otherwise. Signal is dropped if receiver is not ready as signal.Notify does.
+func (ctx *AppContext) dispatchHupSig(sig os.Signal) {
+	ch := ctx.shutdownSig
+	if atomic.LoadInt32(&ctx.reloadWatchers) > 0 {
+		ch = ctx.reloadSig
+	}
+
+	select {
+	case ch <- sig:
+	default:
+	}
+}
+
+// reloadedEntry register entries from raw boot config into a throwaway AppContext and returns the one with same name.
+//
+// It is used by Reloadable entries to parse new boot config in the same way as registration.
+// Crypto entries of appCtx are added into throwaway AppContext in order to resolve secret references.
+func reloadedEntry[T Entry](appCtx *AppContext, raw []byte, name string, regFunc RegFuncE, opts ...AppContextOption) (T, error) {
+	var res T
+
+	throwaway := NewAppContext(opts...)
+	for _, v := range appCtxOrGlobal(appCtx).ListEntriesByType(CryptoEntryType) {
+		throwaway.AddEntry(v)
+	}
+
+	entries, err := regFunc(raw, WithAppCtx(throwaway))
+	if err != nil {
+		return res, err
+	}
+
+	if v, ok := entries[name].(T); ok {
+		return v, nil
+	}
+
+	return res, fmt.Errorf("entry %s not found in boot config, %w", name, ErrRestartRequired)
+}
+
+// GetReloadSig returns reload signal channel.
+func (ctx *AppContext) GetReloadSig() chan os.Signal {
+	return ctx.reloadSig
+}
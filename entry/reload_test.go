@@ -0,0 +1,177 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkentry
+
+import (
+	"context"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zapcore"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+)
+
+const reloadBootConfig = `
+config:
+  - name: ut-config
+    content:
+      key: %s
+logger:
+  - name: ut-logger
+    zap:
+      level: %s
+`
+
+func TestAppContext_Reload_WithoutBootSource(t *testing.T) {
+	ctx := NewAppContext()
+	report, err := ctx.Reload(context.Background())
+	assert.Nil(t, report)
+	assert.NotNil(t, err)
+}
+
+func TestAppContext_Reload(t *testing.T) {
+	ctx := NewAppContext()
+
+	raw := []byte(fmt.Sprintf(reloadBootConfig, "v1", "info"))
+	assert.Nil(t, BootstrapBuiltInEntryFromYAMLE(raw, WithAppCtx(ctx)))
+	ctx.AddEntry(&EntryMock{Name: "ut-mock"})
+
+	path := filepath.Join(t.TempDir(), "boot.yaml")
+	assert.Nil(t, os.WriteFile(path, raw, os.ModePerm))
+	ctx.SetBootSource(BootSourceFromFile(path), raw)
+
+	// with same boot config
+	report, err := ctx.Reload(context.Background())
+	assert.Nil(t, err)
+	assert.False(t, report.Changed)
+	assert.Empty(t, report.RestartRequired())
+
+	// with new boot config
+	assert.Nil(t, os.WriteFile(path, []byte(fmt.Sprintf(reloadBootConfig, "v2", "debug")), os.ModePerm))
+	report, err = ctx.Reload(context.Background())
+	assert.Nil(t, err)
+	assert.True(t, report.Changed)
+	assert.Contains(t, report.RestartRequired(), "mock/ut-mock")
+
+	config := MustGetEntryAs[*ConfigEntry](ctx, "ut-config")
+	assert.Equal(t, "v2", config.GetString("key"))
+
+	logger := MustGetEntryAs[*LoggerEntry](ctx, "ut-logger")
+	assert.Equal(t, zapcore.DebugLevel, logger.LoggerConfig.Level.Level())
+
+	// with invalid boot config, entries should stay untouched
+	assert.Nil(t, os.WriteFile(path, []byte("config: [invalid"), os.ModePerm))
+	report, err = ctx.Reload(context.Background())
+	assert.Nil(t, report)
+	assert.NotNil(t, err)
+	assert.Equal(t, "v2", config.GetString("key"))
+}
+
+func TestAppContext_Reload_WithMissingEntry(t *testing.T) {
+	ctx := NewAppContext()
+
+	raw := []byte(fmt.Sprintf(reloadBootConfig, "v1", "info"))
+	assert.Nil(t, BootstrapBuiltInEntryFromYAMLE(raw, WithAppCtx(ctx)))
+
+	ctx.SetBootSource(func() ([]byte, error) {
+		return []byte("config: []"), nil
+	}, raw)
+
+	report, err := ctx.Reload(context.Background())
+	assert.Nil(t, err)
+	assert.Contains(t, report.RestartRequired(), "ConfigEntry/ut-config")
+	assert.Contains(t, report.RestartRequired(), "LoggerEntry/ut-logger")
+}
+
+func TestAppContext_WatchReloadSig(t *testing.T) {
+	ctx := NewAppContext()
+
+	raw := []byte(fmt.Sprintf(reloadBootConfig, "v1", "info"))
+	assert.Nil(t, BootstrapBuiltInEntryFromYAMLE(raw, WithAppCtx(ctx)))
+	ctx.SetBootSource(func() ([]byte, error) {
+		return []byte(fmt.Sprintf(reloadBootConfig, "v2", "info")), nil
+	}, raw)
+
+	c, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ctx.WatchReloadSig(c)
+	ctx.GetReloadSig() <- syscall.SIGHUP
+
+	config := MustGetEntryAs[*ConfigEntry](ctx, "ut-config")
+	assert.Eventually(t, func() bool {
+		return config.GetString("key") == "v2"
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestAppContext_DispatchHupSig(t *testing.T) {
+	ctx := NewAppContext()
+
+	// shutdown signal is received without watcher, signal is dropped until receiver is ready
+	received := make(chan os.Signal, 1)
+	go func() {
+		received <- <-ctx.GetShutdownSig()
+	}()
+	assert.Eventually(t, func() bool {
+		ctx.dispatchHupSig(syscall.SIGHUP)
+		return len(received) > 0
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, syscall.SIGHUP, <-received)
+
+	// reload signal is received while watching
+	c, cancel := context.WithCancel(context.Background())
+	ctx.WatchReloadSig(c)
+	cancel()
+	assert.Eventually(t, func() bool {
+		return atomic.LoadInt32(&ctx.reloadWatchers) == 0
+	}, time.Second, 10*time.Millisecond)
+
+	atomic.AddInt32(&ctx.reloadWatchers, 1)
+	ctx.dispatchHupSig(syscall.SIGHUP)
+	assert.Equal(t, syscall.SIGHUP, <-ctx.GetReloadSig())
+}
+
+func TestCertEntry_Reload(t *testing.T) {
+	certPem, keyPem := generateCerts(t)
+
+	certPemDir := filepath.ToSlash(filepath.Join(t.TempDir(), "cert.pem"))
+	keyPemDir := filepath.ToSlash(filepath.Join(t.TempDir(), "key.pem"))
+
+	assert.Nil(t, os.WriteFile(certPemDir, certPem, os.ModePerm))
+	assert.Nil(t, os.WriteFile(keyPemDir, keyPem, os.ModePerm))
+
+	entry := RegisterCertEntry(&BootCert{
+		Cert: []*BootCertE{
+			{
+				Name: "ut-cert",
+			},
+		},
+	}, WithAppCtx(NewAppContext()))[0]
+	assert.Nil(t, entry.BootstrapE(context.TODO()))
+	assert.Nil(t, entry.Certificate)
+
+	// with invalid key path, current certificate should be kept
+	raw := fmt.Sprintf(`
+cert:
+  - name: ut-cert
+    certPemPath: %s
+    keyPemPath: %s
+`, certPemDir, "/invalid/key.pem")
+	assert.NotNil(t, entry.Reload(context.TODO(), []byte(raw)))
+	assert.Nil(t, entry.Certificate)
+
+	raw = fmt.Sprintf(`
+cert:
+  - name: ut-cert
+    certPemPath: %s
+    keyPemPath: %s
+`, certPemDir, keyPemDir)
+	assert.Nil(t, entry.Reload(context.TODO(), []byte(raw)))
+	assert.NotNil(t, entry.Certificate)
+}
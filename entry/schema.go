@@ -0,0 +1,453 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkentry
+
+import (
+	"fmt"
+	"gopkg.in/yaml.v2"
+	yaml3 "gopkg.in/yaml.v3"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+const (
+	// JSONSchemaDraft is JSON Schema draft of generated schema
+	JSONSchemaDraft = "http://json-schema.org/draft-07/schema#"
+
+	jsonSchemaObject  = "object"
+	jsonSchemaArray   = "array"
+	jsonSchemaString  = "string"
+	jsonSchemaInteger = "integer"
+	jsonSchemaNumber  = "number"
+	jsonSchemaBoolean = "boolean"
+)
+
+// bootSections keyed by lowered top level section of boot config
+var bootSections = map[string]*bootSection{
+	"app":         {name: "app", schema: NewJSONSchema(&bootConfigAppInfo{}).Properties["app"]},
+	"logger":      {name: "logger", schema: NewJSONSchema(&BootLogger{}).Properties["logger"]},
+	"event":       {name: "event", schema: NewJSONSchema(&BootEvent{}).Properties["event"]},
+	"config":      {name: "config", schema: NewJSONSchema(&BootConfig{}).Properties["config"]},
+	"cert":        {name: "cert", schema: NewJSONSchema(&BootCert{}).Properties["cert"]},
+	"featureflag": {name: "featureFlag", schema: NewJSONSchema(&BootFeatureFlag{}).Properties["featureFlag"]},
+	// ENV mapped to key, like LOKI_TIER: logger[0].loki.labels.app_tier
+	"envmapping": {name: bootEnvMappingSection, schema: NewJSONSchema(map[string]string{})},
+}
+
+// bootSection is top level section of boot config.
+type bootSection struct {
+	name   string
+	schema *JSONSchema
+}
+
+// JSONSchema is a subset of JSON Schema which is generated from boot config structs.
+//
+// Marshal it with encoding/json and configure editors with it in order to autocomplete boot config.
+type JSONSchema struct {
+	Schema               string                 `json:"$schema,omitempty" yaml:"$schema,omitempty"`
+	Title                string                 `json:"title,omitempty" yaml:"title,omitempty"`
+	Type                 string                 `json:"type,omitempty" yaml:"type,omitempty"`
+	Properties           map[string]*JSONSchema `json:"properties,omitempty" yaml:"properties,omitempty"`
+	AdditionalProperties interface{}            `json:"additionalProperties,omitempty" yaml:"additionalProperties,omitempty"`
+	Items                *JSONSchema            `json:"items,omitempty" yaml:"items,omitempty"`
+	OneOf                []*JSONSchema          `json:"oneOf,omitempty" yaml:"oneOf,omitempty"`
+}
+
+// NewJSONSchema generate JSON Schema from struct, pointer, slice or map with yaml tags.
+//
+// Types could not be decoded from YAML like func and chan are allowed to be any value.
+func NewJSONSchema(config interface{}) *JSONSchema {
+	if config == nil {
+		return &JSONSchema{}
+	}
+
+	return newJSONSchema(reflect.TypeOf(config), map[reflect.Type]bool{})
+}
+
+// newJSONSchema generate JSON Schema from type recursively, visited is used to break recursive types.
+func newJSONSchema(t reflect.Type, visited map[reflect.Type]bool) *JSONSchema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return &JSONSchema{Type: jsonSchemaString}
+	case reflect.Bool:
+		return &JSONSchema{Type: jsonSchemaBoolean}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &JSONSchema{Type: jsonSchemaInteger}
+	case reflect.Float32, reflect.Float64:
+		return &JSONSchema{Type: jsonSchemaNumber}
+	case reflect.Slice, reflect.Array:
+		return &JSONSchema{Type: jsonSchemaArray, Items: newJSONSchema(t.Elem(), visited)}
+	case reflect.Map:
+		return &JSONSchema{Type: jsonSchemaObject, AdditionalProperties: newJSONSchema(t.Elem(), visited)}
+	case reflect.Struct:
+		if visited[t] {
+			return &JSONSchema{}
+		}
+		visited[t] = true
+		defer delete(visited, t)
+
+		res := &JSONSchema{
+			Type:                 jsonSchemaObject,
+			Properties:           map[string]*JSONSchema{},
+			AdditionalProperties: false,
+		}
+		addStructProperties(res, t, visited)
+		return res
+	default:
+		return &JSONSchema{}
+	}
+}
+
+// addStructProperties add fields of struct into properties, squashed fields are flattened as mapstructure does.
+func addStructProperties(schema *JSONSchema, t reflect.Type, visited map[reflect.Type]bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if len(field.PkgPath) > 0 {
+			continue
+		}
+
+		tokens := strings.Split(field.Tag.Get("yaml"), ",")
+		name := tokens[0]
+		if name == "-" {
+			continue
+		}
+
+		if field.Anonymous && (hasTagOption(tokens, "squash") || hasTagOption(tokens, "inline")) {
+			elem := field.Type
+			for elem.Kind() == reflect.Ptr {
+				elem = elem.Elem()
+			}
+			if elem.Kind() == reflect.Struct {
+				addStructProperties(schema, elem, visited)
+				continue
+			}
+		}
+
+		if len(name) < 1 {
+			name = field.Name
+		}
+
+		schema.Properties[name] = newJSONSchema(field.Type, visited)
+	}
+}
+
+// hasTagOption returns true if option exists in tag tokens.
+func hasTagOption(tokens []string, option string) bool {
+	for i := 1; i < len(tokens); i++ {
+		if tokens[i] == option {
+			return true
+		}
+	}
+
+	return false
+}
+
+// WebFrameBootSchemas returns schemas of entries embedded in each element of web frame sections like gin and grpc,
+// keyed by YAML key, which are commonService, docs, sw, static, pprof and prom.
+//
+// Web frame sections are parsed by RegFunc of web frame modules, they are not declared here since middleware
+// BootConfig could not be imported by rkentry. Web frame modules should declare them with RegisterBootSchema and
+// the type they are decoded into, like RegisterBootSchema("gin", []*BootGin{}), or with *JSONSchema composed of
+// these properties and NewJSONSchema(&rkmidauth.BootConfig{}). Sections declared by RegisterBootSection are not validated
+// in strict mode, which could be used until schema is available.
+func WebFrameBootSchemas() map[string]*JSONSchema {
+	return map[string]*JSONSchema{
+		"commonService": NewJSONSchema(&BootCommonService{}),
+		"docs":          NewJSONSchema(&BootDocs{}),
+		"sw":            NewJSONSchema(&BootSW{}),
+		"static":        NewJSONSchema(&BootStaticFileHandler{}),
+		"pprof":         NewJSONSchema(&BootPProf{}),
+		"prom":          NewJSONSchema(&BootProm{}),
+	}
+}
+
+// RegisterBootSchema declare top level section of boot config with schema generated from config.
+//
+// config should be the same type which section would be decoded into, like []*BootGin{}, or composed *JSONSchema.
+// Sections of builtin entries and entries registered by RegisterEntryFactory are declared by default.
+func RegisterBootSchema(section string, config interface{}) {
+	if schema, ok := config.(*JSONSchema); ok {
+		registerBootSection(section, schema)
+		return
+	}
+
+	registerBootSection(section, NewJSONSchema(config))
+}
+
+// RegisterBootSection declare top level sections of boot config which are parsed by RegFunc.
+//
+// Value of sections are not validated, use RegisterBootSchema to declare section with schema.
+func RegisterBootSection(sections ...string) {
+	for i := range sections {
+		registerBootSection(sections[i], &JSONSchema{})
+	}
+}
+
+// registerBootSection declare section with schema.
+func registerBootSection(section string, schema *JSONSchema) {
+	if len(section) < 1 || schema == nil {
+		return
+	}
+
+	bootSections[strings.ToLower(section)] = &bootSection{
+		name:   section,
+		schema: schema,
+	}
+}
+
+// BootSchema returns JSON Schema of boot config which contains every declared section.
+func BootSchema() *JSONSchema {
+	res := &JSONSchema{
+		Schema:               JSONSchemaDraft,
+		Title:                "rk boot config",
+		Type:                 jsonSchemaObject,
+		Properties:           map[string]*JSONSchema{},
+		AdditionalProperties: false,
+	}
+
+	for _, v := range bootSections {
+		res.Properties[v.name] = v.schema
+	}
+
+	return res
+}
+
+// ValidateBootYAML validate boot config strictly against BootSchema.
+//
+// Unknown keys and values with wrong type are reported in *BootConfigError with line and column.
+// ENV, envMapping, --rkset-file and --rkset overrides are validated as well.
+func ValidateBootYAML(raw []byte) error {
+	bootErr := &BootConfigError{}
+
+	node := &yaml3.Node{}
+	if err := yaml3.Unmarshal(raw, node); err != nil {
+		bootErr.Add("", err)
+		return bootErr
+	}
+
+	schema := BootSchema()
+	schema.validate("", node, bootErr)
+
+	// validate overrides
+	originalBootM := map[interface{}]interface{}{}
+	yaml.Unmarshal(raw, &originalBootM)
+
+	envOverrides, _ := parseEnvOverrides("RK")
+	envMappingOverrides, _ := parseEnvMappingOverrides(bootEnvMapping(originalBootM))
+	fileOverrides, err := parseFlagFileOverrides(newBootFlagSet())
+	if err != nil {
+		bootErr.Add("--"+bootFlagFile, err)
+	}
+	flagOverrides, _ := parseFlagOverrides(newBootFlagSet())
+
+	for _, v := range []struct {
+		source    string
+		overrides map[interface{}]interface{}
+	}{
+		{source: "ENV", overrides: envOverrides},
+		{source: bootEnvMappingSection, overrides: envMappingOverrides},
+		{source: "--" + bootFlagFile, overrides: fileOverrides},
+		{source: "--" + bootFlag, overrides: flagOverrides},
+	} {
+		source, overrides := v.source, v.overrides
+		if len(overrides) < 1 {
+			continue
+		}
+
+		overrideErr := &BootConfigError{}
+		overrideNode := &yaml3.Node{}
+		if err := overrideNode.Encode(overrides); err != nil {
+			overrideErr.Add("", err)
+		} else {
+			schema.validate("", overrideNode, overrideErr)
+		}
+
+		for _, issue := range overrideErr.Issues {
+			issue.Line, issue.Column = 0, 0
+			issue.Message = fmt.Sprintf("%s (overridden by %s)", issue.Message, source)
+			bootErr.Issues = append(bootErr.Issues, issue)
+		}
+	}
+
+	return bootErr.ErrorOrNil()
+}
+
+// validate yaml node against schema and add issues into BootConfigError.
+func (schema *JSONSchema) validate(path string, node *yaml3.Node, bootErr *BootConfigError) {
+	if schema == nil || node == nil {
+		return
+	}
+
+	switch node.Kind {
+	case yaml3.DocumentNode:
+		for i := range node.Content {
+			schema.validate(path, node.Content[i], bootErr)
+		}
+		return
+	case yaml3.AliasNode:
+		schema.validate(path, node.Alias, bootErr)
+		return
+	}
+
+	// scalar of ENV and flag is coerced into type of schema as decoding does
+	if override, ok := bootOverrideFromNode(node); ok {
+		schema.validateOverride(path, node, override, bootErr)
+		return
+	}
+
+	kind := yamlNodeType(node)
+	if len(kind) < 1 {
+		// null value is always allowed
+		return
+	}
+
+	if len(schema.OneOf) > 0 {
+		for _, candidate := range schema.OneOf {
+			if candidate.Type == kind || (candidate.Type == jsonSchemaNumber && kind == jsonSchemaInteger) {
+				candidate.validate(path, node, bootErr)
+				return
+			}
+		}
+
+		types := make([]string, 0)
+		for _, candidate := range schema.OneOf {
+			types = append(types, candidate.Type)
+		}
+		bootErr.addNodeIssue(path, node, fmt.Sprintf("expected %s, got %s", strings.Join(types, " or "), kind))
+		return
+	}
+
+	switch {
+	case len(schema.Type) < 1:
+		return
+	case schema.Type == jsonSchemaNumber && kind == jsonSchemaInteger:
+		return
+	case schema.Type != kind:
+		bootErr.addNodeIssue(path, node, fmt.Sprintf("expected %s, got %s", schema.Type, kind))
+		return
+	}
+
+	switch kind {
+	case jsonSchemaObject:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			keyNode, valueNode := node.Content[i], node.Content[i+1]
+			childPath := joinBootPath(path, keyNode.Value)
+
+			if child := schema.property(keyNode.Value); child != nil {
+				child.validate(childPath, valueNode, bootErr)
+				continue
+			}
+
+			switch v := schema.AdditionalProperties.(type) {
+			case *JSONSchema:
+				v.validate(childPath, valueNode, bootErr)
+			case bool:
+				if !v {
+					bootErr.addNodeIssue(childPath, keyNode, "unknown key, "+schema.suggestion())
+				}
+			}
+		}
+	case jsonSchemaArray:
+		for i := range node.Content {
+			schema.Items.validate(fmt.Sprintf("%s[%d]", path, i), node.Content[i], bootErr)
+		}
+	}
+}
+
+// property returns schema of property with case-insensitive key as mapstructure does.
+func (schema *JSONSchema) property(key string) *JSONSchema {
+	if v, ok := schema.Properties[key]; ok {
+		return v
+	}
+
+	for k, v := range schema.Properties {
+		if strings.EqualFold(k, key) {
+			return v
+		}
+	}
+
+	return nil
+}
+
+// suggestion returns allowed keys of object
+func (schema *JSONSchema) suggestion() string {
+	keys := make([]string, 0)
+	for k := range schema.Properties {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return fmt.Sprintf("allowed keys: [%s]", strings.Join(keys, ", "))
+}
+
+// validateOverride check whether override could be coerced into one of types of schema.
+func (schema *JSONSchema) validateOverride(path string, node *yaml3.Node, override bootOverrideValue, bootErr *BootConfigError) {
+	candidates := schema.OneOf
+	if len(candidates) < 1 {
+		candidates = []*JSONSchema{schema}
+	}
+
+	var err error
+	for _, candidate := range candidates {
+		var to reflect.Type
+		switch candidate.Type {
+		case jsonSchemaString:
+			to = reflect.TypeOf("")
+		case jsonSchemaInteger:
+			to = reflect.TypeOf(int64(0))
+		case jsonSchemaNumber:
+			to = reflect.TypeOf(float64(0))
+		case jsonSchemaBoolean:
+			to = reflect.TypeOf(false)
+		}
+
+		if _, err = override.coerce(to); err == nil {
+			return
+		}
+	}
+
+	bootErr.addNodeIssue(path, node, err.Error())
+}
+
+// yamlNodeType returns JSON Schema type of yaml node, empty string returned for null.
+//
+// Scalars are resolved with yaml.v2 which is used while unmarshalling boot config.
+func yamlNodeType(node *yaml3.Node) string {
+	switch node.Kind {
+	case yaml3.MappingNode:
+		return jsonSchemaObject
+	case yaml3.SequenceNode:
+		return jsonSchemaArray
+	}
+
+	if node.Style&(yaml3.DoubleQuotedStyle|yaml3.SingleQuotedStyle|yaml3.LiteralStyle|yaml3.FoldedStyle) != 0 {
+		return jsonSchemaString
+	}
+
+	var value interface{}
+	if err := yaml.Unmarshal([]byte(node.Value), &value); err != nil {
+		return jsonSchemaString
+	}
+
+	switch value.(type) {
+	case nil:
+		return ""
+	case bool:
+		return jsonSchemaBoolean
+	case int, int64, uint64:
+		return jsonSchemaInteger
+	case float64:
+		return jsonSchemaNumber
+	default:
+		return jsonSchemaString
+	}
+}
@@ -0,0 +1,241 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkentry
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"testing"
+)
+
+type schemaRecursiveMock struct {
+	Name     string                 `yaml:"name"`
+	Children []*schemaRecursiveMock `yaml:"children"`
+}
+
+type schemaSquashMock struct {
+	BootPProf  `yaml:",squash"`
+	Labels     map[string]string `yaml:"labels"`
+	Any        interface{}       `yaml:"any"`
+	Ignored    string            `yaml:"-"`
+	NoTag      float64
+	unexported string
+}
+
+func TestNewJSONSchema(t *testing.T) {
+	// with nil
+	assert.Empty(t, NewJSONSchema(nil).Type)
+
+	// with struct
+	schema := NewJSONSchema(&BootProm{})
+	assert.Equal(t, "object", schema.Type)
+	assert.Equal(t, false, schema.AdditionalProperties)
+	assert.Equal(t, "boolean", schema.Properties["enabled"].Type)
+	assert.Equal(t, "string", schema.Properties["path"].Type)
+	assert.Equal(t, "integer", schema.Properties["pusher"].Properties["IntervalMs"].Type)
+
+	// with slice
+	schema = NewJSONSchema([]*BootSW{})
+	assert.Equal(t, "array", schema.Type)
+	assert.Equal(t, "array", schema.Items.Properties["jsonPaths"].Type)
+	assert.Equal(t, "string", schema.Items.Properties["jsonPaths"].Items.Type)
+
+	// with squash, map and interface
+	schema = NewJSONSchema(schemaSquashMock{})
+	assert.Len(t, schema.Properties, 5)
+	assert.Equal(t, "boolean", schema.Properties["enabled"].Type)
+	assert.Equal(t, "string", schema.Properties["labels"].AdditionalProperties.(*JSONSchema).Type)
+	assert.Empty(t, schema.Properties["any"].Type)
+	assert.Equal(t, "number", schema.Properties["NoTag"].Type)
+
+	// with recursive type
+	schema = NewJSONSchema(&schemaRecursiveMock{})
+	assert.Empty(t, schema.Properties["children"].Items.Type)
+
+	// builtin and web framework related configs
+	for _, config := range []interface{}{
+		&BootLogger{}, &BootEvent{}, &BootConfig{}, &BootCert{}, &BootProm{}, &BootDocs{},
+		&BootSW{}, &BootStaticFileHandler{}, &BootPProf{}, &BootCommonService{},
+	} {
+		bytes, err := json.Marshal(NewJSONSchema(config))
+		assert.Nil(t, err)
+		assert.NotEmpty(t, bytes)
+	}
+}
+
+func TestBootSchema(t *testing.T) {
+	schema := BootSchema()
+	assert.Equal(t, JSONSchemaDraft, schema.Schema)
+	for _, section := range []string{"app", "logger", "event", "config", "cert"} {
+		assert.Contains(t, schema.Properties, section)
+	}
+
+	// with custom schema
+	RegisterBootSchema("utSchema", []*BootSW{})
+	defer delete(bootSections, "utschema")
+	assert.Equal(t, "array", BootSchema().Properties["utSchema"].Type)
+
+	// with factory
+	registerFactoryMock(t, "utFactory")
+	assert.Len(t, BootSchema().Properties["utFactory"].OneOf, 2)
+}
+
+func TestValidateBootYAML(t *testing.T) {
+	// happy case
+	raw := `
+app:
+  name: ut-app
+logger:
+  - name: ut-logger
+    zap:
+      level: debug
+      outputPaths: ["stdout"]
+config:
+  - name: ut-config
+    content:
+      key: value
+`
+	assert.Nil(t, ValidateBootYAML([]byte(raw)))
+
+	// with invalid YAML
+	assert.NotNil(t, ValidateBootYAML([]byte("logger: [invalid")))
+
+	// with unknown keys and wrong types
+	raw = `
+loger:
+  - name: ut-logger
+logger:
+  - name: [ut-logger]
+    zap:
+      levle: debug
+cert: ut-cert
+`
+	err := ValidateBootYAML([]byte(raw))
+	assert.NotNil(t, err)
+
+	issues := err.(*BootConfigError).Issues
+	assert.Len(t, issues, 4)
+
+	assert.Equal(t, "loger", issues[0].Path)
+	assert.Equal(t, 2, issues[0].Line)
+	assert.Equal(t, 1, issues[0].Column)
+	assert.Contains(t, issues[0].Message, "unknown key")
+
+	assert.Equal(t, "logger[0].name", issues[1].Path)
+	assert.Equal(t, "expected string, got array", issues[1].Message)
+	assert.Equal(t, 5, issues[1].Line)
+	assert.Equal(t, 11, issues[1].Column)
+
+	assert.Equal(t, "logger[0].zap.levle", issues[2].Path)
+	assert.Equal(t, 7, issues[2].Line)
+
+	assert.Equal(t, "cert", issues[3].Path)
+	assert.Equal(t, "expected array, got string", issues[3].Message)
+
+	assert.Contains(t, err.Error(), "loger (line 2, column 1): unknown key")
+}
+
+func TestValidateBootYAML_WithOverrides(t *testing.T) {
+	assert.Nil(t, os.Setenv("RK_LOGER_0_NAME", "ut-logger"))
+	defer os.Unsetenv("RK_LOGER_0_NAME")
+
+	err := ValidateBootYAML([]byte(`app: {}`))
+	assert.NotNil(t, err)
+
+	issues := err.(*BootConfigError).Issues
+	assert.Len(t, issues, 1)
+	assert.Equal(t, "loger", issues[0].Path)
+	assert.Zero(t, issues[0].Line)
+	assert.Contains(t, issues[0].Message, "overridden by ENV")
+}
+
+func TestValidateBootYAML_WithFactory(t *testing.T) {
+	registerFactoryMock(t, "utFactory")
+
+	raw := `
+utFactory:
+  - name: ut-0
+    port: 8080
+  - name: ut-1
+    port: invalid
+`
+	err := ValidateBootYAML([]byte(raw))
+	assert.NotNil(t, err)
+	assert.Equal(t, "utFactory[1].port", err.(*BootConfigError).Issues[0].Path)
+
+	assert.Nil(t, ValidateBootYAML([]byte("utFactory: {name: ut, port: 10}\n")))
+	assert.NotNil(t, ValidateBootYAML([]byte("utFactory: {name: ut, port: 1.5}\n")))
+	assert.NotNil(t, ValidateBootYAML([]byte("utFactory: ut\n")))
+}
+
+func TestWithStrictBootConfig(t *testing.T) {
+	raw := []byte(`
+logger:
+  - name: ut-logger
+    unknown: value
+`)
+
+	// without strict
+	assert.Nil(t, BootstrapBuiltInEntryFromYAMLE(raw, WithAppCtx(NewAppContext())))
+
+	// with strict
+	ctx := NewAppContext()
+	err := BootstrapBuiltInEntryFromYAMLE(raw, WithAppCtx(ctx), WithStrictBootConfig())
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "logger[0].unknown (line 4, column 5): unknown key")
+	assert.Nil(t, ctx.GetEntry(LoggerEntryType, "ut-logger"))
+}
+
+type schemaWebFrameMock struct {
+	Name          string                `yaml:"name"`
+	Port          uint64                `yaml:"port"`
+	CommonService BootCommonService     `yaml:"commonService"`
+	Docs          BootDocs              `yaml:"docs"`
+	SW            BootSW                `yaml:"sw"`
+	Static        BootStaticFileHandler `yaml:"static"`
+	PProf         BootPProf             `yaml:"pprof"`
+	Prom          BootProm              `yaml:"prom"`
+}
+
+func TestWebFrameBootSchemas(t *testing.T) {
+	schemas := WebFrameBootSchemas()
+	assert.Equal(t, NewJSONSchema(&schemaWebFrameMock{}).Properties["prom"], schemas["prom"])
+
+	raw := `
+utGin:
+  - name: ut-gin
+    port: 8080
+    commonService:
+      enabled: true
+    prom:
+      enabled: true
+      pusher:
+        jobName: ut-job
+`
+	// declared with type which section is decoded into
+	RegisterBootSchema("utGin", []*schemaWebFrameMock{})
+	defer delete(bootSections, "utgin")
+	assert.Nil(t, ValidateBootYAML([]byte(raw)))
+
+	err := ValidateBootYAML([]byte(raw + "    pprof: {enabled: true, unknown: value}\n"))
+	assert.NotNil(t, err)
+	assert.Equal(t, "utGin[0].pprof.unknown", err.(*BootConfigError).Issues[0].Path)
+
+	// composed with properties
+	properties := WebFrameBootSchemas()
+	properties["name"] = NewJSONSchema("")
+	properties["port"] = NewJSONSchema(0)
+	RegisterBootSchema("utGin", &JSONSchema{
+		Type:  jsonSchemaArray,
+		Items: &JSONSchema{Type: jsonSchemaObject, Properties: properties, AdditionalProperties: false},
+	})
+	assert.Nil(t, ValidateBootYAML([]byte(raw)))
+
+	// declared without schema is not validated
+	RegisterBootSection("utGin")
+	assert.Nil(t, ValidateBootYAML([]byte(raw+"    unknown: value\n")))
+}
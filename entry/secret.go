@@ -0,0 +1,239 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkentry
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+const (
+	// SecretSchemeEnv resolves ${env:NAME} with environment variable
+	SecretSchemeEnv = "env"
+	// SecretSchemeFile resolves ${file:/run/secrets/jwt} with content of file, trailing newline is trimmed
+	SecretSchemeFile = "file"
+	// SecretSchemeCrypto resolves ${crypto:entryName:BASE64CIPHERTEXT} with Crypto entry
+	SecretSchemeCrypto = "crypto"
+)
+
+var (
+	// secretRefRegex matches reference like ${env:NAME}
+	secretRefRegex = regexp.MustCompile(`\$\{([a-zA-Z0-9_-]+):([^}]*)\}`)
+
+	secretResolvers = map[string]SecretResolver{
+		SecretSchemeEnv:    SecretResolverFunc(resolveEnvSecret),
+		SecretSchemeFile:   SecretResolverFunc(resolveFileSecret),
+		SecretSchemeCrypto: NewCryptoSecretResolver(nil),
+	}
+	secretResolversLock = sync.RWMutex{}
+)
+
+// SecretResolver resolves secret reference in boot config and ConfigEntry values.
+//
+// Reference is of the form ${scheme:ref}, ref is passed to resolver registered with scheme.
+type SecretResolver interface {
+	Resolve(ref string) (string, error)
+}
+
+// SecretResolverFunc is an adapter to use function as SecretResolver.
+type SecretResolverFunc func(ref string) (string, error)
+
+// Resolve calls f(ref).
+func (f SecretResolverFunc) Resolve(ref string) (string, error) {
+	return f(ref)
+}
+
+// RegisterSecretResolver register resolver of scheme, builtin resolver of env, file and crypto could be replaced.
+func RegisterSecretResolver(scheme string, resolver SecretResolver) {
+	if len(scheme) < 1 || resolver == nil {
+		return
+	}
+
+	secretResolversLock.Lock()
+	defer secretResolversLock.Unlock()
+
+	secretResolvers[scheme] = resolver
+}
+
+// ResolveSecretRefs replace every reference in s with resolved secret.
+//
+// References with unknown scheme are kept as it is.
+//
+// Example:
+//
+//	ResolveSecretRefs("user:${env:PASS}")
+func ResolveSecretRefs(s string) (string, error) {
+	return resolveSecretRefs(nil, s)
+}
+
+// resolveSecretRefs is the same as ResolveSecretRefs, builtin crypto resolver looks up Crypto entry in appCtx.
+//
+// GlobalAppCtx is used if appCtx is nil.
+func resolveSecretRefs(appCtx *AppContext, s string) (string, error) {
+	if !strings.Contains(s, "${") {
+		return s, nil
+	}
+
+	secretResolversLock.RLock()
+	defer secretResolversLock.RUnlock()
+
+	var resolveErr error
+	res := secretRefRegex.ReplaceAllStringFunc(s, func(match string) string {
+		tokens := secretRefRegex.FindStringSubmatch(match)
+		resolver, ok := secretResolvers[tokens[1]]
+		if !ok || resolveErr != nil {
+			return match
+		}
+
+		// resolver created without AppContext follows AppContext of caller
+		if v, ok := resolver.(*cryptoSecretResolver); ok && v.appCtx == nil {
+			resolver = &cryptoSecretResolver{appCtx: appCtx}
+		}
+
+		secret, err := resolver.Resolve(tokens[2])
+		if err != nil {
+			// secret is not included in error
+			resolveErr = fmt.Errorf("failed to resolve secret reference of %s, %v", tokens[1], err)
+			return match
+		}
+
+		return secret
+	})
+
+	if resolveErr != nil {
+		return "", resolveErr
+	}
+
+	return res, nil
+}
+
+// NewCryptoSecretResolver returns resolver which decrypts base64 ciphertext with Crypto entry in AppContext.
+//
+// Reference is of the form entryName:BASE64CIPHERTEXT. If appCtx is nil, AppContext which entries are registered into
+// is used while registering and reloading entries, GlobalAppCtx is used by UnmarshalBootYAML and ResolveSecretRefs.
+// Crypto entry should be added into AppContext before boot config is unmarshalled.
+func NewCryptoSecretResolver(appCtx *AppContext) SecretResolver {
+	return &cryptoSecretResolver{appCtx: appCtx}
+}
+
+// cryptoSecretResolver decrypts base64 ciphertext with Crypto entry in AppContext.
+type cryptoSecretResolver struct {
+	appCtx *AppContext
+}
+
+// Resolve decrypts reference of the form entryName:BASE64CIPHERTEXT.
+func (resolver *cryptoSecretResolver) Resolve(ref string) (string, error) {
+	tokens := strings.SplitN(ref, ":", 2)
+	if len(tokens) != 2 {
+		return "", fmt.Errorf("invalid reference, expect entryName:BASE64CIPHERTEXT")
+	}
+
+	crypto := appCtxOrGlobal(resolver.appCtx).GetCryptoEntry(tokens[0])
+	if crypto == nil {
+		return "", fmt.Errorf("crypto entry %s not found", tokens[0])
+	}
+
+	ciphertext, err := base64.StdEncoding.DecodeString(tokens[1])
+	if err != nil {
+		return "", fmt.Errorf("invalid base64 ciphertext, %v", err)
+	}
+
+	plaintext, err := crypto.Decrypt(ciphertext)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt with crypto entry %s, %v", tokens[0], err)
+	}
+
+	return string(plaintext), nil
+}
+
+// resolveEnvSecret resolves environment variable.
+func resolveEnvSecret(ref string) (string, error) {
+	v, ok := os.LookupEnv(ref)
+	if !ok {
+		return "", fmt.Errorf("environment variable %s not set", ref)
+	}
+
+	return v, nil
+}
+
+// resolveFileSecret read file with trailing newline trimmed.
+func resolveFileSecret(ref string) (string, error) {
+	bytes, err := os.ReadFile(ref)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimRight(string(bytes), "\r\n"), nil
+}
+
+// secretRefDecodeHook returns hook which resolves references while decoding boot config,
+// so that only decoded fields are resolved. Crypto entries are looked up in appCtx.
+func secretRefDecodeHook(appCtx *AppContext) func(reflect.Type, reflect.Type, interface{}) (interface{}, error) {
+	return func(from reflect.Type, _ reflect.Type, data interface{}) (interface{}, error) {
+		if from.Kind() != reflect.String {
+			return data, nil
+		}
+
+		s := reflect.ValueOf(data).String()
+		if !strings.Contains(s, "${") {
+			return data, nil
+		}
+
+		return resolveSecretRefs(appCtx, s)
+	}
+}
+
+// resolveSecretRefsInValue resolves references in nested maps and slices, path is used in error.
+//
+// Crypto entries are looked up in appCtx.
+func resolveSecretRefsInValue(appCtx *AppContext, path string, input interface{}) (interface{}, error) {
+	return mapStringsInValue(path, input, func(s string) (string, error) {
+		return resolveSecretRefs(appCtx, s)
+	})
+}
+
+// mapStringsInValue replace strings in nested maps and slices with result of fn, path is used in error.
+func mapStringsInValue(path string, input interface{}, fn func(string) (string, error)) (interface{}, error) {
+	switch v := input.(type) {
+	case string:
+		res, err := fn(v)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", path, err)
+		}
+		return res, nil
+	case map[string]interface{}:
+		for key, value := range v {
+			res, err := mapStringsInValue(joinBootPath(path, key), value, fn)
+			if err != nil {
+				return nil, err
+			}
+			v[key] = res
+		}
+	case map[interface{}]interface{}:
+		for key, value := range v {
+			res, err := mapStringsInValue(joinBootPath(path, fmt.Sprintf("%v", key)), value, fn)
+			if err != nil {
+				return nil, err
+			}
+			v[key] = res
+		}
+	case []interface{}:
+		for i := range v {
+			res, err := mapStringsInValue(fmt.Sprintf("%s[%d]", path, i), v[i], fn)
+			if err != nil {
+				return nil, err
+			}
+			v[i] = res
+		}
+	}
+
+	return input, nil
+}
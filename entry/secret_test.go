@@ -0,0 +1,198 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkentry
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestResolveSecretRefs(t *testing.T) {
+	assert.Nil(t, os.Setenv("UT_SECRET", "ut-pass"))
+	defer os.Unsetenv("UT_SECRET")
+
+	secretPath := filepath.Join(t.TempDir(), "secret")
+	assert.Nil(t, os.WriteFile(secretPath, []byte("ut-token\n"), os.ModePerm))
+
+	// without reference
+	res, err := ResolveSecretRefs("plain")
+	assert.Nil(t, err)
+	assert.Equal(t, "plain", res)
+
+	// with env
+	res, err = ResolveSecretRefs("user:${env:UT_SECRET}")
+	assert.Nil(t, err)
+	assert.Equal(t, "user:ut-pass", res)
+
+	// with file
+	res, err = ResolveSecretRefs("${file:" + secretPath + "}")
+	assert.Nil(t, err)
+	assert.Equal(t, "ut-token", res)
+
+	// with unknown scheme
+	res, err = ResolveSecretRefs("${unknown:value}")
+	assert.Nil(t, err)
+	assert.Equal(t, "${unknown:value}", res)
+
+	// with missing env
+	res, err = ResolveSecretRefs("${env:UT_SECRET_NOT_EXIST}")
+	assert.Empty(t, res)
+	assert.NotNil(t, err)
+
+	// with custom resolver
+	RegisterSecretResolver("ut", SecretResolverFunc(func(ref string) (string, error) {
+		return "", errors.New("ut-error")
+	}))
+	defer delete(secretResolvers, "ut")
+	_, err = ResolveSecretRefs("${ut:value}")
+	assert.Contains(t, err.Error(), "ut-error")
+}
+
+func TestNewCryptoSecretResolver(t *testing.T) {
+	ctx := NewAppContext()
+	resolver := NewCryptoSecretResolver(ctx)
+
+	// without entry
+	_, err := resolver.Resolve("ut-crypto:AAAA")
+	assert.NotNil(t, err)
+
+	crypto, err := NewCryptoAES("ut-crypto", []byte("0123456789abcdef"))
+	assert.Nil(t, err)
+	ctx.AddEntry(crypto)
+
+	ciphertext, err := crypto.Encrypt([]byte("ut-pass"))
+	assert.Nil(t, err)
+
+	res, err := resolver.Resolve("ut-crypto:" + base64.StdEncoding.EncodeToString(ciphertext))
+	assert.Nil(t, err)
+	assert.Equal(t, "ut-pass", res)
+
+	// with invalid reference
+	_, err = resolver.Resolve("ut-crypto")
+	assert.NotNil(t, err)
+	_, err = resolver.Resolve("ut-crypto:invalid-base64")
+	assert.NotNil(t, err)
+}
+
+func TestUnmarshalBootYAMLE_WithSecretRefs(t *testing.T) {
+	assert.Nil(t, os.Setenv("UT_SECRET", "ut-pass"))
+	defer os.Unsetenv("UT_SECRET")
+
+	boot := &struct {
+		Prom BootProm `yaml:"prom"`
+	}{}
+	raw := `
+prom:
+  pusher:
+    basicAuth: "user:${env:UT_SECRET}"
+unused: ${env:UT_SECRET_NOT_EXIST}
+`
+	assert.Nil(t, UnmarshalBootYAMLE([]byte(raw), boot))
+	assert.Equal(t, "user:ut-pass", boot.Prom.Pusher.BasicAuth)
+
+	// effective config keeps reference
+	effective, err := NewEffectiveConfig([]byte(raw))
+	assert.Nil(t, err)
+	assert.NotContains(t, effective.Get("prom.pusher.basicauth").Value, "ut-pass")
+
+	// with missing env
+	err = UnmarshalBootYAMLE([]byte(`
+prom:
+  pusher:
+    basicAuth: ${env:UT_SECRET_NOT_EXIST}
+`), boot)
+	assert.NotNil(t, err)
+	assert.Equal(t, "prom.pusher.basicAuth", err.(*BootConfigError).Issues[0].Path)
+}
+
+func TestRegisterConfigEntry_WithSecretRefs(t *testing.T) {
+	assert.Nil(t, os.Setenv("UT_SECRET", "ut-pass"))
+	defer os.Unsetenv("UT_SECRET")
+
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	assert.Nil(t, os.WriteFile(configPath, []byte(`
+db:
+  password: ${env:UT_SECRET}
+  hosts: ["${env:UT_SECRET}"]
+`), os.ModePerm))
+
+	entries := RegisterConfigEntry(&BootConfig{
+		Config: []*BootConfigE{
+			{
+				Name: "ut-config",
+				Path: configPath,
+				Content: map[string]interface{}{
+					"token": "${env:UT_SECRET}",
+				},
+			},
+		},
+	}, WithAppCtx(NewAppContext()))
+	entry := entries[0]
+	assert.Equal(t, "ut-pass", entry.GetString("db.password"))
+	assert.Equal(t, []interface{}{"ut-pass"}, entry.Get("db.hosts"))
+	assert.Equal(t, "ut-pass", entry.GetString("token"))
+	assert.NotContains(t, entry.String(), "ut-pass")
+
+	// with missing env
+	entries, err := RegisterConfigEntryE(&BootConfig{
+		Config: []*BootConfigE{
+			{
+				Name: "ut-config",
+				Content: map[string]interface{}{
+					"token": "${env:UT_SECRET_NOT_EXIST}",
+				},
+			},
+		},
+	}, WithAppCtx(NewAppContext()))
+	assert.Empty(t, entries)
+	assert.Contains(t, err.Error(), "token")
+	assert.NotContains(t, err.Error(), "ut-pass")
+}
+
+func TestRegisterEntries_WithCryptoRefsInAppCtx(t *testing.T) {
+	ctx := NewAppContext()
+	crypto, err := NewCryptoAES("ut-crypto", []byte("0123456789abcdef"))
+	assert.Nil(t, err)
+	ctx.AddEntry(crypto)
+
+	ciphertext, err := crypto.Encrypt([]byte("ut-pass"))
+	assert.Nil(t, err)
+	ref := "${crypto:ut-crypto:" + base64.StdEncoding.EncodeToString(ciphertext) + "}"
+
+	raw := []byte(`
+config:
+  - name: ut-config
+    content:
+      token: ` + ref + `
+event:
+  - name: ut-event
+    description: ` + ref + `
+`)
+
+	// crypto entry is looked up in AppContext of registration
+	configs, err := RegisterConfigEntryYAMLE(raw, WithAppCtx(ctx))
+	assert.Nil(t, err)
+	config := configs["ut-config"].(*ConfigEntry)
+	assert.Equal(t, "ut-pass", config.GetString("token"))
+
+	events, err := RegisterEventEntryYAMLE(raw, WithAppCtx(ctx))
+	assert.Nil(t, err)
+	assert.Equal(t, "ut-pass", events["ut-event"].GetDescription())
+
+	// and while reloading
+	assert.Nil(t, config.Reload(context.TODO(), raw))
+	assert.Equal(t, "ut-pass", config.GetString("token"))
+
+	// not in GlobalAppCtx
+	_, err = RegisterEventEntryYAMLE(raw, WithAppCtx(NewAppContext()))
+	assert.Contains(t, err.Error(), "crypto entry ut-crypto not found")
+	assert.Nil(t, GlobalAppCtx.GetCryptoEntry("ut-crypto"))
+}
@@ -0,0 +1,425 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkentry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go.uber.org/zap"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultShutdownBudget is default time budget of AppContext.Shutdown
+	DefaultShutdownBudget = 30 * time.Second
+	// DefaultShutdownHookTimeout is default deadline of each shutdown hook
+	DefaultShutdownHookTimeout = 10 * time.Second
+)
+
+// ShutdownPhase is phase of graceful shutdown, phases are executed in order bellow.
+//
+// 1: StopTraffic, stop accepting new traffic, like closing listeners.
+// 2: Drain, wait for in-flight requests or jobs.
+// 3: FlushTelemetry, flush logs, metrics and traces, like Loki syncers, pushGateway pusher and span exporters.
+// 4: CloseResources, close remaining resources, like database connections.
+type ShutdownPhase int
+
+const (
+	ShutdownPhaseStopTraffic ShutdownPhase = iota
+	ShutdownPhaseDrain
+	ShutdownPhaseFlushTelemetry
+	ShutdownPhaseCloseResources
+)
+
+// ShutdownPhases list all ShutdownPhase in execution order.
+var ShutdownPhases = []ShutdownPhase{
+	ShutdownPhaseStopTraffic,
+	ShutdownPhaseDrain,
+	ShutdownPhaseFlushTelemetry,
+	ShutdownPhaseCloseResources,
+}
+
+// String returns name of phase.
+func (p ShutdownPhase) String() string {
+	switch p {
+	case ShutdownPhaseStopTraffic:
+		return "StopTraffic"
+	case ShutdownPhaseDrain:
+		return "Drain"
+	case ShutdownPhaseFlushTelemetry:
+		return "FlushTelemetry"
+	case ShutdownPhaseCloseResources:
+		return "CloseResources"
+	}
+
+	return fmt.Sprintf("ShutdownPhase(%d)", int(p))
+}
+
+// ShutdownHookE is shutdown hook which respects deadline of context and returns error.
+type ShutdownHookE func(ctx context.Context) error
+
+// ShutdownHookOption option for AddShutdownHookE
+type ShutdownHookOption func(hook *shutdownHook)
+
+// WithPhaseShutdownHook provide phase of shutdown hook, ShutdownPhaseCloseResources by default.
+func WithPhaseShutdownHook(phase ShutdownPhase) ShutdownHookOption {
+	return func(hook *shutdownHook) {
+		hook.phase = phase
+	}
+}
+
+// WithPriorityShutdownHook provide priority of shutdown hook.
+//
+// Hooks with higher priority run earlier in the same phase, hooks with same priority run in registration order.
+func WithPriorityShutdownHook(priority int) ShutdownHookOption {
+	return func(hook *shutdownHook) {
+		hook.priority = priority
+	}
+}
+
+// WithTimeoutShutdownHook provide deadline of shutdown hook, AppContext.SetShutdownHookTimeout() by default.
+func WithTimeoutShutdownHook(timeout time.Duration) ShutdownHookOption {
+	return func(hook *shutdownHook) {
+		if timeout > 0 {
+			hook.timeout = timeout
+		}
+	}
+}
+
+// EntryShutdown could be implemented by entries to control how they are interrupted by AppContext.Shutdown.
+//
+// Entries without EntryShutdown are interrupted in ShutdownPhaseCloseResources with default hook timeout.
+type EntryShutdown interface {
+	// ShutdownPhase returns phase which entry should be interrupted in
+	ShutdownPhase() ShutdownPhase
+
+	// ShutdownTimeout returns deadline of Interrupt, default hook timeout will be used if zero returned
+	ShutdownTimeout() time.Duration
+}
+
+// shutdownHook is shutdown hook registered in AppContext.
+type shutdownHook struct {
+	name     string
+	phase    ShutdownPhase
+	priority int
+	timeout  time.Duration
+	seq      int
+	f        ShutdownHookE
+	legacy   ShutdownHook
+}
+
+// toShutdownHook convert hook into ShutdownHook.
+func (hook *shutdownHook) toShutdownHook() ShutdownHook {
+	if hook.legacy != nil {
+		return hook.legacy
+	}
+
+	return func() {
+		hook.f(context.Background())
+	}
+}
+
+// ShutdownHookReport is result of a shutdown hook.
+type ShutdownHookReport struct {
+	Name     string        `json:"name" yaml:"name"`
+	Priority int           `json:"priority" yaml:"priority"`
+	Timeout  time.Duration `json:"timeout" yaml:"timeout"`
+	Elapsed  time.Duration `json:"elapsed" yaml:"elapsed"`
+	Status   string        `json:"status" yaml:"status"`
+	Error    string        `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// ShutdownPhaseReport is result of a shutdown phase.
+type ShutdownPhaseReport struct {
+	Phase   string                `json:"phase" yaml:"phase"`
+	Elapsed time.Duration         `json:"elapsed" yaml:"elapsed"`
+	Hooks   []*ShutdownHookReport `json:"hooks" yaml:"hooks"`
+}
+
+// ShutdownReport is result of AppContext.Shutdown.
+type ShutdownReport struct {
+	StartTime time.Time              `json:"startTime" yaml:"startTime"`
+	Budget    time.Duration          `json:"budget" yaml:"budget"`
+	Elapsed   time.Duration          `json:"elapsed" yaml:"elapsed"`
+	Phases    []*ShutdownPhaseReport `json:"phases" yaml:"phases"`
+}
+
+const (
+	ShutdownHookStatusOk      = "ok"
+	ShutdownHookStatusError   = "error"
+	ShutdownHookStatusTimeout = "timeout"
+	ShutdownHookStatusSkipped = "skipped"
+)
+
+// Err returns error if any of shutdown hooks failed, timed out or skipped.
+func (r *ShutdownReport) Err() error {
+	problems := make([]string, 0)
+	for _, phase := range r.Phases {
+		for _, hook := range phase.Hooks {
+			if hook.Status != ShutdownHookStatusOk {
+				problems = append(problems, fmt.Sprintf("%s/%s: %s, %s", phase.Phase, hook.Name, hook.Status, hook.Error))
+			}
+		}
+	}
+
+	if len(problems) < 1 {
+		return nil
+	}
+
+	return fmt.Errorf("shutdown not completed gracefully, %d problem(s) found:\n  * %s",
+		len(problems), strings.Join(problems, "\n  * "))
+}
+
+// AddShutdownHookE add shutdown hook with phase, priority and deadline.
+//
+// Hook with the same name will be overridden.
+func (ctx *AppContext) AddShutdownHookE(name string, f ShutdownHookE, opts ...ShutdownHookOption) {
+	if f == nil {
+		return
+	}
+
+	hook := &shutdownHook{
+		name:  name,
+		phase: ShutdownPhaseCloseResources,
+		f:     f,
+	}
+
+	for i := range opts {
+		opts[i](hook)
+	}
+
+	ctx.addShutdownHook(hook)
+}
+
+// SetShutdownBudget set total time budget of Shutdown, DefaultShutdownBudget by default.
+func (ctx *AppContext) SetShutdownBudget(budget time.Duration) {
+	if budget > 0 {
+		ctx.shutdownBudget = budget
+	}
+}
+
+// SetShutdownHookTimeout set default deadline of shutdown hooks, DefaultShutdownHookTimeout by default.
+func (ctx *AppContext) SetShutdownHookTimeout(timeout time.Duration) {
+	if timeout > 0 {
+		ctx.shutdownHookTimeout = timeout
+	}
+}
+
+// Shutdown run shutdown hooks and interrupt entries phase by phase.
+//
+// Entries are interrupted in reverse dependency order after shutdown hooks of the same phase.
+// Each hook runs with its own deadline which would never exceed remaining budget,
+// hooks will be skipped once budget runs out. Hooks exceeding deadline keep running in background.
+//
+// Report of each phase is logged via default LoggerEntry.
+//
+// Shutdown is not called by WaitForShutdownSig(), bootstrapper should call it after shutdown signal received,
+// or use WaitForShutdownSigAndShutdown(), instead of calling ListShutdownHooks() and Interrupt() of entries by itself.
+func (ctx *AppContext) Shutdown(c context.Context, entries ...Entry) *ShutdownReport {
+	if c == nil {
+		c = context.Background()
+	}
+
+	report := &ShutdownReport{
+		StartTime: time.Now(),
+		Budget:    ctx.shutdownBudget,
+		Phases:    make([]*ShutdownPhaseReport, 0),
+	}
+
+	c, cancel := context.WithTimeout(c, ctx.shutdownBudget)
+	defer cancel()
+
+	hooks := ctx.sortedShutdownHooks(entries)
+	logger := ctx.GetLoggerEntryDefault().Logger
+
+	for _, phase := range ShutdownPhases {
+		phaseReport := &ShutdownPhaseReport{
+			Phase: phase.String(),
+			Hooks: make([]*ShutdownHookReport, 0),
+		}
+		phaseStart := time.Now()
+
+		for _, hook := range hooks {
+			if hook.phase == phase {
+				phaseReport.Hooks = append(phaseReport.Hooks, ctx.runShutdownHook(c, hook))
+			}
+		}
+
+		phaseReport.Elapsed = time.Since(phaseStart)
+		report.Phases = append(report.Phases, phaseReport)
+
+		fields := []zap.Field{
+			zap.String("phase", phaseReport.Phase),
+			zap.Duration("elapsed", phaseReport.Elapsed),
+			zap.Any("hooks", phaseReport.Hooks),
+		}
+
+		if phaseFailed(phaseReport) {
+			logger.Warn("Shutdown phase finished with failures", fields...)
+		} else {
+			logger.Info("Shutdown phase finished", fields...)
+		}
+	}
+
+	report.Elapsed = time.Since(report.StartTime)
+	logger.Info("Shutdown finished",
+		zap.Duration("budget", report.Budget),
+		zap.Duration("elapsed", report.Elapsed))
+
+	return report
+}
+
+// WaitForShutdownSigAndShutdown waits for shutdown signal and calls Shutdown with every entry in AppContext.
+func (ctx *AppContext) WaitForShutdownSigAndShutdown(c context.Context) *ShutdownReport {
+	ctx.WaitForShutdownSig()
+
+	entries := make([]Entry, 0)
+	for _, v := range ctx.entries {
+		entries = append(entries, entryMapToList(v)...)
+	}
+
+	// make sure order is stable before sorting with dependencies
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].GetType() < entries[j].GetType()
+	})
+
+	return ctx.Shutdown(c, entries...)
+}
+
+// addShutdownHook add or override hook with name, registration order is preserved for overridden hook.
+func (ctx *AppContext) addShutdownHook(hook *shutdownHook) {
+	if v, ok := ctx.shutdownHooks[hook.name]; ok {
+		hook.seq = v.seq
+	} else {
+		ctx.shutdownHookSeq++
+		hook.seq = ctx.shutdownHookSeq
+	}
+
+	ctx.shutdownHooks[hook.name] = hook
+}
+
+// sortedShutdownHooks returns registered hooks sorted by priority and registration order followed by entries.
+func (ctx *AppContext) sortedShutdownHooks(entries []Entry) []*shutdownHook {
+	res := make([]*shutdownHook, 0, len(ctx.shutdownHooks)+len(entries))
+	for _, v := range ctx.shutdownHooks {
+		res = append(res, v)
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].priority != res[j].priority {
+			return res[i].priority > res[j].priority
+		}
+		return res[i].seq < res[j].seq
+	})
+
+	// interrupt entries in reverse dependency order
+	sorted, err := SortEntries(entries)
+	if err != nil {
+		// still interrupt entries with cycle in input order
+		sorted = make([]Entry, 0, len(entries))
+		for i := range entries {
+			if entries[i] != nil {
+				sorted = append(sorted, entries[i])
+			}
+		}
+	}
+
+	for i := len(sorted) - 1; i >= 0; i-- {
+		entry := sorted[i]
+		hook := &shutdownHook{
+			name:  refOf(entry).String(),
+			phase: ShutdownPhaseCloseResources,
+			f: func(c context.Context) error {
+				ctx.interruptEntry(c, entry)
+				return nil
+			},
+		}
+
+		if v, ok := entry.(EntryShutdown); ok {
+			hook.phase = v.ShutdownPhase()
+			hook.timeout = v.ShutdownTimeout()
+		}
+
+		res = append(res, hook)
+	}
+
+	return res
+}
+
+// runShutdownHook run hook with deadline, panic would be recovered as error.
+func (ctx *AppContext) runShutdownHook(c context.Context, hook *shutdownHook) *ShutdownHookReport {
+	timeout := hook.timeout
+	if timeout <= 0 {
+		timeout = ctx.shutdownHookTimeout
+	}
+
+	report := &ShutdownHookReport{
+		Name:     hook.name,
+		Priority: hook.priority,
+		Timeout:  timeout,
+		Status:   ShutdownHookStatusOk,
+	}
+
+	if c.Err() != nil {
+		report.Status = ShutdownHookStatusSkipped
+		report.Error = "shutdown budget exceeded"
+		return report
+	}
+
+	hookCtx, cancel := context.WithTimeout(c, timeout)
+	defer cancel()
+
+	start := time.Now()
+	done := make(chan error, 1)
+
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- fmt.Errorf("panic in shutdown hook, %v", r)
+			}
+		}()
+
+		if hook.legacy != nil {
+			hook.legacy()
+			done <- nil
+			return
+		}
+
+		done <- hook.f(hookCtx)
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			report.Status = ShutdownHookStatusError
+			report.Error = err.Error()
+		}
+	case <-hookCtx.Done():
+		report.Status = ShutdownHookStatusTimeout
+		report.Error = hookCtx.Err().Error()
+		if errors.Is(c.Err(), context.DeadlineExceeded) {
+			report.Error = "shutdown budget exceeded"
+		}
+	}
+
+	report.Elapsed = time.Since(start)
+
+	return report
+}
+
+// phaseFailed returns true if any of hooks in phase not finished successfully.
+func phaseFailed(report *ShutdownPhaseReport) bool {
+	for _, hook := range report.Hooks {
+		if hook.Status != ShutdownHookStatusOk {
+			return true
+		}
+	}
+
+	return false
+}
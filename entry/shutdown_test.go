@@ -0,0 +1,172 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkentry
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestShutdownPhase_String(t *testing.T) {
+	assert.Equal(t, "StopTraffic", ShutdownPhaseStopTraffic.String())
+	assert.Equal(t, "Drain", ShutdownPhaseDrain.String())
+	assert.Equal(t, "FlushTelemetry", ShutdownPhaseFlushTelemetry.String())
+	assert.Equal(t, "CloseResources", ShutdownPhaseCloseResources.String())
+	assert.Equal(t, "ShutdownPhase(10)", ShutdownPhase(10).String())
+}
+
+func TestAppContext_AddShutdownHookE(t *testing.T) {
+	ctx := NewAppContext()
+
+	// with nil func
+	ctx.AddShutdownHookE("ut-hook", nil)
+	assert.Empty(t, ctx.ListShutdownHooks())
+
+	called := false
+	ctx.AddShutdownHookE("ut-hook", func(context.Context) error {
+		called = true
+		return nil
+	})
+	assert.Len(t, ctx.ListShutdownHooks(), 1)
+
+	// call via legacy ShutdownHook
+	ctx.GetShutdownHook("ut-hook")()
+	assert.True(t, called)
+}
+
+func TestAppContext_Shutdown_Order(t *testing.T) {
+	ctx := NewAppContext()
+	order := make([]string, 0)
+
+	hook := func(name string) ShutdownHookE {
+		return func(context.Context) error {
+			order = append(order, name)
+			return nil
+		}
+	}
+
+	ctx.AddShutdownHook("legacy", func() {
+		order = append(order, "legacy")
+	})
+	ctx.AddShutdownHookE("close-low", hook("close-low"), WithPriorityShutdownHook(-1))
+	ctx.AddShutdownHookE("close-high", hook("close-high"), WithPriorityShutdownHook(10))
+	ctx.AddShutdownHookE("flush", hook("flush"), WithPhaseShutdownHook(ShutdownPhaseFlushTelemetry))
+	ctx.AddShutdownHookE("drain", hook("drain"), WithPhaseShutdownHook(ShutdownPhaseDrain))
+	ctx.AddShutdownHookE("stop", hook("stop"), WithPhaseShutdownHook(ShutdownPhaseStopTraffic))
+
+	a := &dependentEntryMock{EntryMock: EntryMock{Name: "a"}, deps: []string{"b"}, order: &order}
+	b := &dependentEntryMock{EntryMock: EntryMock{Name: "b"}, order: &order}
+
+	report := ctx.Shutdown(context.Background(), b, a)
+	assert.Nil(t, report.Err())
+	assert.Equal(t, []string{
+		"stop", "drain", "flush", "close-high", "legacy", "close-low", "interrupt-a", "interrupt-b",
+	}, order)
+
+	assert.Len(t, report.Phases, len(ShutdownPhases))
+	assert.Equal(t, "CloseResources", report.Phases[3].Phase)
+	assert.Len(t, report.Phases[3].Hooks, 5)
+	assert.Equal(t, "mock/a", report.Phases[3].Hooks[3].Name)
+	assert.Equal(t, DefaultShutdownBudget, report.Budget)
+
+	// interrupted entries are stopped
+	assert.Equal(t, EntryStateStopped, ctx.GetEntryStatus("mock", "a").State)
+}
+
+func TestAppContext_Shutdown_WithFailures(t *testing.T) {
+	ctx := NewAppContext()
+	ctx.SetShutdownBudget(200 * time.Millisecond)
+	ctx.SetShutdownHookTimeout(50 * time.Millisecond)
+
+	ctx.AddShutdownHookE("error", func(context.Context) error {
+		return errors.New("ut-error")
+	}, WithPriorityShutdownHook(3))
+	ctx.AddShutdownHookE("panic", func(context.Context) error {
+		panic("ut-panic")
+	}, WithPriorityShutdownHook(2))
+	ctx.AddShutdownHookE("timeout", func(c context.Context) error {
+		<-c.Done()
+		return c.Err()
+	}, WithPriorityShutdownHook(1))
+	ctx.AddShutdownHookE("budget", func(c context.Context) error {
+		time.Sleep(time.Second)
+		return nil
+	}, WithTimeoutShutdownHook(time.Second))
+	ctx.AddShutdownHookE("skipped", func(c context.Context) error {
+		return nil
+	}, WithPriorityShutdownHook(-1))
+
+	report := ctx.Shutdown(context.Background())
+	hooks := report.Phases[3].Hooks
+	assert.Len(t, hooks, 5)
+
+	assert.Equal(t, ShutdownHookStatusError, hooks[0].Status)
+	assert.Equal(t, "ut-error", hooks[0].Error)
+
+	assert.Equal(t, ShutdownHookStatusError, hooks[1].Status)
+	assert.Contains(t, hooks[1].Error, "ut-panic")
+
+	assert.Equal(t, ShutdownHookStatusTimeout, hooks[2].Status)
+	assert.Equal(t, 50*time.Millisecond, hooks[2].Timeout)
+
+	assert.Equal(t, ShutdownHookStatusTimeout, hooks[3].Status)
+	assert.Equal(t, "shutdown budget exceeded", hooks[3].Error)
+
+	assert.Equal(t, ShutdownHookStatusSkipped, hooks[4].Status)
+
+	assert.Less(t, report.Elapsed, time.Second)
+	assert.NotNil(t, report.Err())
+	assert.Contains(t, report.Err().Error(), "5 problem(s) found")
+}
+
+func TestAppContext_Shutdown_WithEntryShutdown(t *testing.T) {
+	ctx := NewAppContext()
+	order := make([]string, 0)
+
+	ctx.AddShutdownHookE("flush", func(context.Context) error {
+		order = append(order, "flush")
+		return nil
+	}, WithPhaseShutdownHook(ShutdownPhaseFlushTelemetry))
+
+	logger := RegisterLoggerEntry(&BootLogger{
+		Logger: []*BootLoggerE{
+			{
+				Name: "ut-logger",
+			},
+		},
+	}, WithAppCtx(ctx))[0]
+	mock := &dependentEntryMock{EntryMock: EntryMock{Name: "mock"}, order: &order}
+
+	report := ctx.Shutdown(context.Background(), mock, logger)
+	assert.Nil(t, report.Err())
+	assert.Equal(t, "LoggerEntry/ut-logger", report.Phases[2].Hooks[1].Name)
+	assert.Equal(t, DefaultShutdownHookTimeout, report.Phases[2].Hooks[1].Timeout)
+	assert.Equal(t, []string{"flush", "interrupt-mock"}, order)
+}
+
+func TestAppContext_WaitForShutdownSigAndShutdown(t *testing.T) {
+	ctx := NewAppContext()
+	order := make([]string, 0)
+
+	ctx.AddShutdownHookE("flush", func(context.Context) error {
+		order = append(order, "flush")
+		return nil
+	}, WithPhaseShutdownHook(ShutdownPhaseFlushTelemetry))
+	ctx.AddEntry(&dependentEntryMock{EntryMock: EntryMock{Name: "a"}, order: &order})
+
+	go func() {
+		ctx.GetShutdownSig() <- syscall.SIGTERM
+	}()
+
+	report := ctx.WaitForShutdownSigAndShutdown(context.Background())
+	assert.Nil(t, report.Err())
+	assert.Equal(t, []string{"flush", "interrupt-a"}, order)
+	assert.Equal(t, EntryStateStopped, ctx.GetEntryStatus("mock", "a").State)
+}
@@ -80,6 +80,7 @@ type StaticFileHandlerEntry struct {
 	Path             string             `yaml:"-" json:"-"`
 	Template         *template.Template `json:"-" yaml:"-"`
 	httpFS           http.FileSystem    `yaml:"-" json:"-"`
+	appCtx           *AppContext        `json:"-" yaml:"-"`
 }
 
 // StaticFileHandlerEntryOption options for StaticFileHandlerEntry
@@ -92,6 +93,15 @@ func WithNameStaticFileHandlerEntry(name string) StaticFileHandlerEntryOption {
 	}
 }
 
+// WithAppCtxStaticFileHandlerEntry provide AppContext, GlobalAppCtx by default
+func WithAppCtxStaticFileHandlerEntry(ctx *AppContext) StaticFileHandlerEntryOption {
+	return func(entry *StaticFileHandlerEntry) {
+		if ctx != nil {
+			entry.appCtx = ctx
+		}
+	}
+}
+
 // RegisterStaticFileHandlerEntry Create new static file handler entry with config
 func RegisterStaticFileHandlerEntry(boot *BootStaticFileHandler, opts ...StaticFileHandlerEntryOption) *StaticFileHandlerEntry {
 	if !boot.Enabled {
@@ -105,13 +115,14 @@ func RegisterStaticFileHandlerEntry(boot *BootStaticFileHandler, opts ...StaticF
 		Template:         template.New("rk-static"),
 		Path:             boot.Path,
 		httpFS:           http.Dir(""),
+		appCtx:           GlobalAppCtx,
 	}
 
 	for i := range opts {
 		opts[i](entry)
 	}
 
-	if fs := GlobalAppCtx.GetEmbedFS(entry.GetType(), entry.GetName()); fs != nil {
+	if fs := entry.appCtx.GetEmbedFS(entry.GetType(), entry.GetName()); fs != nil {
 		entry.httpFS = http.FS(fs)
 	}
 
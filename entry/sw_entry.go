@@ -58,6 +58,7 @@ type SWEntry struct {
 	Path             string            `json:"-" yaml:"-"`
 	Headers          map[string]string `json:"-" yaml:"-"`
 	embedFS          *embed.FS         `json:"-" yaml:"-"`
+	appCtx           *AppContext       `json:"-" yaml:"-"`
 }
 
 type SWEntryOption func(entry *SWEntry)
@@ -68,6 +69,15 @@ func WithNameSWEntry(name string) SWEntryOption {
 	}
 }
 
+// WithAppCtxSWEntry provide AppContext, GlobalAppCtx by default
+func WithAppCtxSWEntry(ctx *AppContext) SWEntryOption {
+	return func(entry *SWEntry) {
+		if ctx != nil {
+			entry.appCtx = ctx
+		}
+	}
+}
+
 func RegisterSWEntry(boot *BootSW, opts ...SWEntryOption) *SWEntry {
 	var swEntry *SWEntry
 	if boot.Enabled {
@@ -88,13 +98,14 @@ func RegisterSWEntry(boot *BootSW, opts ...SWEntryOption) *SWEntry {
 			Path:             boot.Path,
 			JsonPaths:        boot.JsonPaths,
 			Headers:          headers,
+			appCtx:           GlobalAppCtx,
 		}
 
 		for i := range opts {
 			opts[i](swEntry)
 		}
 
-		swEntry.embedFS = GlobalAppCtx.GetEmbedFS(swEntry.GetType(), swEntry.GetName())
+		swEntry.embedFS = swEntry.appCtx.GetEmbedFS(swEntry.GetType(), swEntry.GetName())
 
 		if len(swEntry.Path) < 1 {
 			swEntry.Path = "/sw"
@@ -13,6 +13,7 @@ import (
 	"os"
 	"path/filepath"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -76,38 +77,152 @@ var (
 //
 // ./your_compiled_binary
 //
-// Important! Please make sure the type of value keeps the same, otherwise, it won't override.
-// For example, os.Setenv("RK_GIN_0_PORT", "invalid-port") won't success, but keep original value.
+// Use double underscore(__) for underscore in key, like RK_LOGGER_0_LOKI_LABELS_APP__TIER for logger[0].loki.labels.app_tier.
+//
+// ENV could also be mapped to key explicitly with envMapping section, ENV name is case-sensitive:
+//
+//	envMapping:
+//	  LOKI_TIER: logger[0].loki.labels.app_tier
+//
+// [File]: Override boot config value with YAML fragment by flag of rkset-file:
+//
+// ./your_compiled_binary --rkset-file override.yaml
+//
+// Overrides are applied in order of ENV, envMapping, --rkset-file and --rkset, later one wins.
+//
+// Values of ENV and --rkset are coerced into type of destination field, so RK_GIN_0_NAME=0012 stays as string.
+// Type could be declared explicitly with suffix of key, like --rkset "gin[0].name:string=0012", one of string, int, float and bool.
+//
+// Important! Value which could not be coerced fails with name of ENV or flag.
+// For example, os.Setenv("RK_GIN_0_PORT", "invalid-port") returns error of gin[0].port.
 func UnmarshalBootYAML(raw []byte, config interface{}) {
+	if err := UnmarshalBootYAMLE(raw, config); err != nil {
+		ShutdownWithError(err)
+	}
+}
+
+// UnmarshalBootYAMLE is the same as UnmarshalBootYAML but returns error instead of panic.
+//
+// Returned error would be *BootConfigError which contains every problem found in boot config with YAML path.
+func UnmarshalBootYAMLE(raw []byte, config interface{}) error {
+	return unmarshalBootYAMLE(nil, raw, config)
+}
+
+// unmarshalBootYAMLE is the same as UnmarshalBootYAMLE, references of crypto entries are resolved with appCtx.
+func unmarshalBootYAMLE(appCtx *AppContext, raw []byte, config interface{}) error {
+	bootM, err := parseBootYAML(raw)
+	if err != nil {
+		return err
+	}
+
+	return decodeBootYAML(appCtx, "", bootM, config)
+}
+
+// parseBootYAML unmarshal raw boot config into map with keys of sections lowered, ENV and flag overrides are applied.
+//
+// Nested keys keep original case since they could be decoded into map, like labels of Loki. Struct fields are
+// matched case-insensitively while decoding, and overrides are merged into existing keys case-insensitively.
+func parseBootYAML(raw []byte) (map[interface{}]interface{}, error) {
+	bootErr := &BootConfigError{}
+
 	// 1: unmarshal original
 	originalBootM := map[interface{}]interface{}{}
 	// unmarshal with yaml
 	if err := yaml.Unmarshal(raw, &originalBootM); err != nil {
-		ShutdownWithError(err)
+		bootErr.Add("", err)
+		return nil, bootErr
 	}
 
-	// lower key
-	originalBootM = lowerKeyMap(originalBootM)
+	// ENV names in envMapping are case-sensitive, read it before keys lowered
+	envMapping := bootEnvMapping(originalBootM)
+
+	// lower keys of sections
+	originalBootM = lowerSectionKeys(originalBootM)
 
 	// 2: get ENV overrides
-	// ignoring error, output to stdout already
-	envOverridesBootM, _ := parseEnvOverrides("RK")
+	envOverridesBootM, err := parseEnvOverrides("RK")
+	bootErr.Add("", err)
+	envMappingOverridesBootM, err := parseEnvMappingOverrides(envMapping)
+	bootErr.Add("", err)
 
 	// 3: get flag overrides
-	pFlag := pflag.NewFlagSet("rk", pflag.ContinueOnError)
-	pFlag.String("rkset", "", "")
-	// ignoring error, output to stdout already
-	flagOverridesBootM, _ := parseFlagOverrides(pFlag)
+	fileOverridesBootM, err := parseFlagFileOverrides(newBootFlagSet())
+	bootErr.Add("--"+bootFlagFile, err)
+	flagOverridesBootM, err := parseFlagOverrides(newBootFlagSet())
+	bootErr.Add("", err)
+
+	if err := bootErr.ErrorOrNil(); err != nil {
+		return nil, err
+	}
 
 	// 4: override environment first, and then flags
 	overrideMap(originalBootM, envOverridesBootM)
+	overrideMap(originalBootM, envMappingOverridesBootM)
+	overrideMap(originalBootM, fileOverridesBootM)
 	overrideMap(originalBootM, flagOverridesBootM)
 
-	// 5: unmarshal to struct
-	if err := mapstructure.Decode(originalBootM, config); err != nil {
-		ShutdownWithError(err)
+	return originalBootM, nil
+}
+
+const (
+	// bootFlag overrides boot config with key value pairs
+	bootFlag = "rkset"
+	// bootFlagFile overrides boot config with YAML fragment
+	bootFlagFile = "rkset-file"
+	// bootEnvMappingSection maps ENV to key explicitly
+	bootEnvMappingSection = "envMapping"
+)
+
+// newBootFlagSet returns flag set which contains --rkset and --rkset-file flags.
+func newBootFlagSet() *pflag.FlagSet {
+	pFlag := pflag.NewFlagSet("rk", pflag.ContinueOnError)
+	pFlag.String(bootFlag, "", "")
+	pFlag.String(bootFlagFile, "", "")
+	return pFlag
+}
+
+// bootEnvMapping returns envMapping section of boot config which is not lowered yet, case of paths is kept as well.
+func bootEnvMapping(bootM map[interface{}]interface{}) map[string]string {
+	res := map[string]string{}
+
+	for k, v := range bootM {
+		if !strings.EqualFold(fmt.Sprintf("%v", k), bootEnvMappingSection) {
+			continue
+		}
+
+		mapping, _ := v.(map[interface{}]interface{})
+		for name, key := range mapping {
+			if s, ok := key.(string); ok {
+				res[fmt.Sprintf("%v", name)] = s
+			}
+		}
+	}
+
+	return res
+}
+
+// decodeBootYAML decode parsed boot config into struct, path is YAML path of input used in error.
+//
+// Secret references like ${env:NAME} in decoded string fields are resolved, crypto entries are looked up in appCtx.
+func decodeBootYAML(appCtx *AppContext, path string, input interface{}, config interface{}) error {
+	bootErr := &BootConfigError{}
+
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		Result:  config,
+		TagName: "yaml",
+		DecodeHook: mapstructure.ComposeDecodeHookFunc(
+			bootOverrideDecodeHook,
+			secretRefDecodeHook(appCtx),
+		),
+	})
+	if err != nil {
+		bootErr.Add(path, err)
+		return bootErr
 	}
 
+	bootErr.Add(path, decoder.Decode(input))
+
+	return bootErr.ErrorOrNil()
 }
 
 // ShutdownWithError shuts down and panic.
@@ -133,17 +248,57 @@ func IsValidDomain(domain string) bool {
 	return true
 }
 
+// filterByDomain filter out elements of boot config list based on domain, elements without name are ignored.
+//
+// Elements with valid domain are kept, and the one with matching domain wins over "*" or empty domain
+// if multiple elements share the same name.
+func filterByDomain[T any](list []T, nameAndDomain func(T) (string, string)) map[string]T {
+	res := make(map[string]T)
+	for _, elem := range list {
+		name, domain := nameAndDomain(elem)
+		if len(name) < 1 {
+			continue
+		}
+
+		if !IsValidDomain(domain) {
+			continue
+		}
+
+		// * or matching domain
+		// 1: add it to map if missing
+		if _, ok := res[name]; !ok {
+			res[name] = elem
+			continue
+		}
+
+		// 2: already has an entry, then compare domain,
+		//    only one case would occur, previous one is already the correct one, continue
+		if domain == "" || domain == "*" {
+			continue
+		}
+
+		res[name] = elem
+	}
+
+	return res
+}
+
 // readFile wil read try to read file with bellow sequence.
 //
 // 1: Read from embed.FS if not nil
 // 2: Read from local FS
 func readFile(filePath string, fs *embed.FS, shouldPanic bool) []byte {
+	data, err := readFileE(filePath, fs)
+	if err != nil && shouldPanic {
+		ShutdownWithError(err)
+	}
+	return data
+}
+
+// readFileE is the same as readFile but returns error instead of panic.
+func readFileE(filePath string, fs *embed.FS) ([]byte, error) {
 	if fs != nil {
-		data, err := fs.ReadFile(filePath)
-		if err != nil && shouldPanic {
-			ShutdownWithError(err)
-		}
-		return data
+		return fs.ReadFile(filePath)
 	}
 
 	wd, _ := os.Getwd()
@@ -152,11 +307,21 @@ func readFile(filePath string, fs *embed.FS, shouldPanic bool) []byte {
 		filePath = filepath.ToSlash(filepath.Join(wd, filePath))
 	}
 
-	data, err := os.ReadFile(filePath)
-	if err != nil && shouldPanic {
-		ShutdownWithError(err)
+	return os.ReadFile(filePath)
+}
+
+// lowerSectionKeys convert top level keys of boot config to lower case, nested keys are kept.
+func lowerSectionKeys(src map[interface{}]interface{}) map[interface{}]interface{} {
+	res := map[interface{}]interface{}{}
+
+	for k, v := range src {
+		if s, ok := k.(string); ok {
+			k = strings.ToLower(s)
+		}
+		res[k] = v
 	}
-	return data
+
+	return res
 }
 
 // iterate map structure and convert string type key to lower case
@@ -173,6 +338,12 @@ func lowerKeyMap(src map[interface{}]interface{}) map[interface{}]interface{} {
 			k = strings.ToLower(k.(string))
 		}
 
+		// key without value in YAML
+		if v == nil {
+			res[k] = v
+			continue
+		}
+
 		valueKind := reflect.TypeOf(v).Kind()
 		switch valueKind {
 		case reflect.Slice, reflect.Array:
@@ -196,6 +367,11 @@ func lowerKeySlice(src []interface{}) []interface{} {
 	res := make([]interface{}, 0)
 
 	for i := range src {
+		if src[i] == nil {
+			res = append(res, src[i])
+			continue
+		}
+
 		switch reflect.TypeOf(src[i]).Kind() {
 		case reflect.Slice, reflect.Array:
 			res = append(res, lowerKeySlice(src[i].([]interface{})))
@@ -230,6 +406,7 @@ func overrideMap(src map[interface{}]interface{}, override map[interface{}]inter
 	}
 
 	for k, overrideItem := range override {
+		k = existingMapKey(src, k)
 		originalItem, ok := src[k]
 		if ok && reflect.TypeOf(originalItem) == reflect.TypeOf(overrideItem) {
 			switch overrideItem.(type) {
@@ -246,6 +423,28 @@ func overrideMap(src map[interface{}]interface{}, override map[interface{}]inter
 	}
 }
 
+// existingMapKey returns key of src which equals to k case-insensitively, k is returned if missing.
+//
+// Keys of ENV overrides are lowered, they should override keys in original case.
+func existingMapKey(src map[interface{}]interface{}, k interface{}) interface{} {
+	s, ok := k.(string)
+	if !ok {
+		return k
+	}
+
+	if _, ok := src[k]; ok {
+		return k
+	}
+
+	for key := range src {
+		if v, ok := key.(string); ok && strings.EqualFold(v, s) {
+			return key
+		}
+	}
+
+	return k
+}
+
 // overrideSlice override source slice with new slice items.
 // It will iterate through all items in slice and check map and slice types of item to recursively override values
 //
@@ -256,7 +455,21 @@ func overrideSlice(src []interface{}, override []interface{}) {
 	}
 
 	for i := range override {
-		if override[i] != nil && len(src)-1 >= i && reflect.TypeOf(override[i]) == reflect.TypeOf(src[i]) {
+		if override[i] == nil || len(src)-1 < i {
+			continue
+		}
+
+		// scalar of ENV and flag is coerced while decoding, but could not replace map or slice
+		if _, ok := override[i].(bootOverrideValue); ok {
+			switch src[i].(type) {
+			case []interface{}, map[interface{}]interface{}:
+			default:
+				src[i] = override[i]
+			}
+			continue
+		}
+
+		if reflect.TypeOf(override[i]) == reflect.TypeOf(src[i]) {
 			overrideItem := override[i]
 			originalItem := src[i]
 			switch overrideItem.(type) {
@@ -302,12 +515,52 @@ func reformatEnvKey(input string) string {
 	return strings.Join(list, ".")
 }
 
-// parseEnvOverrides read environment variables and convert to map
-func parseEnvOverrides(prefix string) (map[interface{}]interface{}, error) {
-	overrideValueList := make([]string, 0)
-	forLogList := make([]string, 0)
+// bootOverride is a single override of boot config from ENV or flag.
+type bootOverride struct {
+	// source of override, like env:RK_GIN_0_PORT or flag:--rkset
+	source string
+	// origin is raw ENV or flag value
+	origin string
+	// expr is flattened key value pairs, like gin[0].port=8080
+	expr string
+	// values is map of YAML fragment with keys of sections lowered, expr is empty if values exist
+	values map[interface{}]interface{}
+}
 
-	// 1: iterate ENV values and filter with prefix
+// parse returns override as map.
+func (o *bootOverride) parse() (map[interface{}]interface{}, error) {
+	if o.values != nil {
+		return o.values, nil
+	}
+
+	return parseBootOverridesWithSource(o.expr, o.source)
+}
+
+// mergeBootOverrides parse overrides and merge them into map in order, invalid overrides are skipped.
+//
+// Errors of invalid overrides are aggregated into *BootConfigError with source of override as path, like env:RK_GIN_0_PORT.
+func mergeBootOverrides(overrides []*bootOverride) (map[interface{}]interface{}, error) {
+	res := map[interface{}]interface{}{}
+
+	bootErr := &BootConfigError{}
+	for _, override := range overrides {
+		m, err := override.parse()
+		if err != nil {
+			bootErr.Add(override.source, fmt.Errorf("invalid override %s, %v", override.origin, err))
+			continue
+		}
+
+		overrideMap(res, m)
+	}
+
+	return res, bootErr.ErrorOrNil()
+}
+
+// listEnvOverrides read environment variables with prefix and convert them into overrides.
+func listEnvOverrides(prefix string) []*bootOverride {
+	res := make([]*bootOverride, 0)
+
+	// iterate ENV values and filter with prefix
 	for _, val := range os.Environ() {
 		if !strings.HasPrefix(val, strings.ToUpper(prefix)+"_") {
 			continue
@@ -318,8 +571,11 @@ func parseEnvOverrides(prefix string) (map[interface{}]interface{}, error) {
 			continue
 		}
 
-		// convert key
-		newKey := strings.ToLower(strings.ReplaceAll(strings.TrimPrefix(tokens[0], strings.ToUpper(prefix)+"_"), "_", "."))
+		// convert key, double underscore stands for underscore in key
+		newKey := strings.TrimPrefix(tokens[0], strings.ToUpper(prefix)+"_")
+		newKey = strings.ReplaceAll(newKey, "__", "\x00")
+		newKey = strings.ReplaceAll(newKey, "_", ".")
+		newKey = strings.ToLower(strings.ReplaceAll(newKey, "\x00", "_"))
 		// Notice: in order to distinguish arrays in key, we defined a special case as bellow.
 		// 1: Environment variables allowed is [a-zA-Z_], so we will use [_number_] to represent arrays. The downside is do not start name with number
 		//
@@ -331,16 +587,139 @@ func parseEnvOverrides(prefix string) (map[interface{}]interface{}, error) {
 		newKey = reformatEnvKey(newKey)
 		newValue := tokens[1]
 
-		forLogList = append(forLogList, fmt.Sprintf("%s => %s=%s", val, newKey, newValue))
+		res = append(res, &bootOverride{
+			source: EffectiveSourceEnv + ":" + tokens[0],
+			origin: val,
+			expr:   fmt.Sprintf("%s=%s", newKey, newValue),
+		})
+	}
+
+	return res
+}
+
+// listFlagOverrides read --rkset flag values and convert them into overrides.
+func listFlagOverrides(set *pflag.FlagSet) []*bootOverride {
+	res := make([]*bootOverride, 0)
+
+	set.ParseAll(os.Args[1:], func(flag *pflag.Flag, value string) error {
+		if flag.Name != bootFlag {
+			return nil
+		}
+
+		res = append(res, &bootOverride{
+			source: EffectiveSourceFlag + ":--" + flag.Name,
+			origin: value,
+			expr:   value,
+		})
+		return nil
+	})
+
+	return res
+}
+
+// listEnvMappingOverrides read environment variables in envMapping and convert them into overrides.
+func listEnvMappingOverrides(mapping map[string]string) []*bootOverride {
+	res := make([]*bootOverride, 0)
 
-		overrideValueList = append(overrideValueList, fmt.Sprintf("%s=%s", newKey, newValue))
+	names := make([]string, 0)
+	for name := range mapping {
+		names = append(names, name)
 	}
+	sort.Strings(names)
 
-	// 2: flatten values
-	overrideValueFlatten := strings.Join(overrideValueList, ",")
+	for _, name := range names {
+		value, ok := os.LookupEnv(name)
+		if !ok || len(mapping[name]) < 1 {
+			continue
+		}
+
+		res = append(res, &bootOverride{
+			source: EffectiveSourceEnv + ":" + name,
+			origin: name + "=" + value,
+			expr:   fmt.Sprintf("%s=%s", mapping[name], value),
+		})
+	}
+
+	return res
+}
 
-	// 3: parse to map
-	res, err := parseBootOverrides(overrideValueFlatten)
+// listFlagFileOverrides read YAML fragments of --rkset-file flag values and convert them into overrides.
+func listFlagFileOverrides(set *pflag.FlagSet) ([]*bootOverride, error) {
+	res := make([]*bootOverride, 0)
+
+	var err error
+	set.ParseAll(os.Args[1:], func(flag *pflag.Flag, value string) error {
+		if flag.Name != bootFlagFile || err != nil {
+			return nil
+		}
+
+		var raw []byte
+		if raw, err = readFileE(value, nil); err != nil {
+			return nil
+		}
+
+		values := map[interface{}]interface{}{}
+		if err = yaml.Unmarshal(raw, &values); err != nil {
+			err = fmt.Errorf("failed to unmarshal %s, %v", value, err)
+			return nil
+		}
+
+		res = append(res, &bootOverride{
+			source: EffectiveSourceFlag + ":--" + flag.Name,
+			origin: value,
+			values: lowerSectionKeys(values),
+		})
+		return nil
+	})
+
+	return res, err
+}
+
+// parseEnvMappingOverrides read environment variables in envMapping and convert to map
+func parseEnvMappingOverrides(mapping map[string]string) (map[interface{}]interface{}, error) {
+	overrides := listEnvMappingOverrides(mapping)
+	overrideValueList := make([]string, 0)
+
+	for _, override := range overrides {
+		overrideValueList = append(overrideValueList, override.expr)
+	}
+
+	res, err := mergeBootOverrides(overrides)
+	if err != nil {
+		LoggerEntryStdout.Debug("Found ENV in envMapping to override, but failed to parse",
+			zap.Strings("env", overrideValueList))
+	}
+
+	return res, err
+}
+
+// parseFlagFileOverrides read YAML fragments of --rkset-file flag values and merge them into map
+func parseFlagFileOverrides(set *pflag.FlagSet) (map[interface{}]interface{}, error) {
+	overrides, err := listFlagFileOverrides(set)
+	if err != nil {
+		return nil, err
+	}
+
+	res := map[interface{}]interface{}{}
+	for _, override := range overrides {
+		overrideMap(res, override.values)
+	}
+
+	return res, nil
+}
+
+// parseEnvOverrides read environment variables and convert to map
+func parseEnvOverrides(prefix string) (map[interface{}]interface{}, error) {
+	forLogList := make([]string, 0)
+
+	// 1: iterate ENV values and filter with prefix
+	overrides := listEnvOverrides(prefix)
+	for _, override := range overrides {
+		forLogList = append(forLogList, fmt.Sprintf("%s => %s", override.origin, override.expr))
+	}
+
+	// 2: parse to map, values are coerced into type of destination while decoding
+	res, err := mergeBootOverrides(overrides)
 
 	envLogOnce.Do(func() {
 		if len(forLogList) > 0 {
@@ -349,7 +728,7 @@ func parseEnvOverrides(prefix string) (map[interface{}]interface{}, error) {
 			}
 
 			if err != nil {
-				LoggerEntryStdout.Debug("Found ENV to override, but failed to parse, ignoring...", zapFields...)
+				LoggerEntryStdout.Debug("Found ENV to override, but failed to parse", zapFields...)
 			} else {
 				LoggerEntryStdout.Debug("Found ENV to override, applying...", zapFields...)
 			}
@@ -359,30 +738,27 @@ func parseEnvOverrides(prefix string) (map[interface{}]interface{}, error) {
 	return res, err
 }
 
-// parseEnvOverrides read flag values and convert to map
+// parseFlagOverrides read --rkset flag values and convert to map
 func parseFlagOverrides(set *pflag.FlagSet) (map[interface{}]interface{}, error) {
 	overrideValueList := make([]string, 0)
 
 	// 1: iterate pFlag values and filter with prefix
-	set.ParseAll(os.Args[1:], func(flag *pflag.Flag, value string) error {
-		overrideValueList = append(overrideValueList, value)
-		return nil
-	})
-
-	// 2: flatten values
-	overrideValueFlatten := strings.Join(overrideValueList, ",")
+	overrides := listFlagOverrides(set)
+	for _, override := range overrides {
+		overrideValueList = append(overrideValueList, override.expr)
+	}
 
-	// 3: parse to map
-	res, err := parseBootOverrides(overrideValueFlatten)
+	// 2: parse to map, values are coerced into type of destination while decoding
+	res, err := mergeBootOverrides(overrides)
 
 	flagLogOnce.Do(func() {
-		if len(overrideValueFlatten) > 0 {
+		if len(overrideValueList) > 0 {
 			zapFields := []zap.Field{
 				zap.Strings("flags", overrideValueList),
 			}
 
 			if err != nil {
-				LoggerEntryStdout.Debug("Found flag to override, but failed to parse, ignoring...", zapFields...)
+				LoggerEntryStdout.Debug("Found flag to override, but failed to parse", zapFields...)
 			} else {
 				LoggerEntryStdout.Debug("Found flag to override, applying...", zapFields...)
 			}
@@ -505,10 +505,11 @@ func TestShutdownWithError_HappyCase(t *testing.T) {
 func TestParseEnvOverrides(t *testing.T) {
 	assert.Nil(t, os.Setenv("RK_GIN_NAME", "rookie"))
 
-	m, err := parseEnvOverrides("rk")
+	bootM, err := parseEnvOverrides("rk")
 	assert.Nil(t, err)
-	assert.Len(t, m, 1)
-	for k, v := range m {
+	assert.Len(t, bootM, 1)
+	m, _ := naturalBootValue(bootM)
+	for k, v := range m.(map[interface{}]interface{}) {
 		assert.Equal(t, "gin", k)
 		for k1, v1 := range v.(map[interface{}]interface{}) {
 			assert.Equal(t, "name", k1)
@@ -516,7 +517,136 @@ func TestParseEnvOverrides(t *testing.T) {
 		}
 	}
 
-	assert.Nil(t, os.Setenv("RK_GIN_NAME", ""))
+	// source of value
+	effective, err := NewEffectiveConfig([]byte("gin:\n  name: origin\n"))
+	assert.Nil(t, err)
+	assert.Equal(t, "rookie", effective.Get("gin.name").Value)
+	assert.Equal(t, "env:RK_GIN_NAME", effective.Get("gin.name").Source)
+
+	assert.Nil(t, os.Unsetenv("RK_GIN_NAME"))
+}
+
+func TestParseBootYAML_WithInvalidOverrides(t *testing.T) {
+	raw := `
+envMapping:
+  UT_GIN_NAME: gin[0.name
+gin:
+  - name: origin
+`
+	assert.Nil(t, os.Setenv("RK_GIN_0_NAME", "a,b"))
+	defer os.Unsetenv("RK_GIN_0_NAME")
+	assert.Nil(t, os.Setenv("UT_GIN_NAME", "mapping"))
+	defer os.Unsetenv("UT_GIN_NAME")
+
+	args := os.Args
+	defer func() { os.Args = args }()
+	os.Args = []string{args[0], "--rkset", "gin[abc].name=flag"}
+
+	bootM, err := parseBootYAML([]byte(raw))
+	assert.Nil(t, bootM)
+
+	var bootErr *BootConfigError
+	assert.True(t, errors.As(err, &bootErr))
+	assert.Len(t, bootErr.Issues, 3)
+	assert.Contains(t, err.Error(), "env:RK_GIN_0_NAME")
+	assert.Contains(t, err.Error(), "env:UT_GIN_NAME")
+	assert.Contains(t, err.Error(), "flag:--rkset")
+}
+
+func TestParseBootYAML_WithEnvMappingAndFlagFile(t *testing.T) {
+	raw := `
+envMapping:
+  LOKI_TIER: logger[0].loki.labels.app_tier
+logger:
+  - name: ut-logger
+    loki:
+      labels:
+        app_tier: file
+        app: file
+        team: file
+`
+	// double underscore stands for underscore
+	assert.Nil(t, os.Setenv("RK_LOGGER_0_LOKI_LABELS_APP__TIER", "env"))
+	defer os.Unsetenv("RK_LOGGER_0_LOKI_LABELS_APP__TIER")
+
+	bootM, err := parseBootYAML([]byte(raw))
+	assert.Nil(t, err)
+	m, _ := naturalBootValue(bootM)
+	labels := m.(map[interface{}]interface{})["logger"].([]interface{})[0].(map[interface{}]interface{})["loki"].(map[interface{}]interface{})["labels"]
+	assert.Equal(t, "env", labels.(map[interface{}]interface{})["app_tier"])
+	assert.Equal(t, "file", labels.(map[interface{}]interface{})["app"])
+
+	// envMapping wins over ENV with prefix
+	assert.Nil(t, os.Setenv("LOKI_TIER", "mapping"))
+	defer os.Unsetenv("LOKI_TIER")
+
+	// --rkset-file and --rkset win over ENV
+	overridePath := filepath.Join(t.TempDir(), "override.yaml")
+	assert.Nil(t, os.WriteFile(overridePath, []byte(`
+logger:
+  - loki:
+      labels:
+        app: flag-file
+        team: flag-file
+`), os.ModePerm))
+
+	args := os.Args
+	defer func() { os.Args = args }()
+	os.Args = []string{args[0], "--rkset-file", overridePath, "--rkset", "logger[0].loki.labels.team=flag"}
+
+	bootM, err = parseBootYAML([]byte(raw))
+	assert.Nil(t, err)
+	m, _ = naturalBootValue(bootM)
+	labels = m.(map[interface{}]interface{})["logger"].([]interface{})[0].(map[interface{}]interface{})["loki"].(map[interface{}]interface{})["labels"]
+	assert.Equal(t, "mapping", labels.(map[interface{}]interface{})["app_tier"])
+	assert.Equal(t, "flag-file", labels.(map[interface{}]interface{})["app"])
+	assert.Equal(t, "flag", labels.(map[interface{}]interface{})["team"])
+
+	effective, err := NewEffectiveConfig([]byte(raw))
+	assert.Nil(t, err)
+	assert.Equal(t, "env:LOKI_TIER", effective.Get("logger[0].loki.labels.app_tier").Source)
+	assert.Equal(t, "flag:--rkset-file", effective.Get("logger[0].loki.labels.app").Source)
+	assert.Equal(t, "flag:--rkset", effective.Get("logger[0].loki.labels.team").Source)
+	assert.Nil(t, ValidateBootYAML([]byte(raw)))
+
+	// with missing file
+	os.Args = []string{args[0], "--rkset-file", filepath.Join(t.TempDir(), "not-exist.yaml")}
+	_, err = parseBootYAML([]byte(raw))
+	assert.Equal(t, "--rkset-file", err.(*BootConfigError).Issues[0].Path)
+}
+
+func TestUnmarshalBootYAMLE_KeepKeyCaseOfMap(t *testing.T) {
+	raw := `
+envMapping:
+  LOKI_OWNER: event[0].loki.labels.Owner
+Event:
+  - Name: ut-event
+    loki:
+      labels:
+        App_Tier: file
+        Team: file
+`
+	assert.Nil(t, os.Setenv("RK_EVENT_0_LOKI_LABELS_APP__TIER", "env"))
+	defer os.Unsetenv("RK_EVENT_0_LOKI_LABELS_APP__TIER")
+	assert.Nil(t, os.Setenv("LOKI_OWNER", "mapping"))
+	defer os.Unsetenv("LOKI_OWNER")
+
+	boot := &BootEvent{}
+	assert.Nil(t, UnmarshalBootYAMLE([]byte(raw), boot))
+	assert.Equal(t, "ut-event", boot.Event[0].Name)
+
+	// ENV overrides existing key case-insensitively and envMapping adds key in case of path
+	assert.Equal(t, map[string]string{
+		"App_Tier": "env",
+		"Team":     "file",
+		"Owner":    "mapping",
+	}, boot.Event[0].Loki.Labels)
+
+	// keys of effective config are lowered
+	effective, err := NewEffectiveConfig([]byte(raw))
+	assert.Nil(t, err)
+	assert.Equal(t, "env:RK_EVENT_0_LOKI_LABELS_APP__TIER", effective.Get("event[0].loki.labels.app_tier").Source)
+	assert.Equal(t, "env:LOKI_OWNER", effective.Get("event[0].loki.labels.owner").Source)
 }
 
 func TestLowerKeyMap(t *testing.T) {
@@ -21,55 +21,45 @@ func main() {
 	os.Setenv("DOMAIN", "prod")
 
 	// 1: register my entry into global rk context
-	RegisterMyEntryFromConfig(boot)
+	rkentry.RegisterFactoryEntryYAML(boot)
 
 	// 2: retrieve entry from global context and convert it into MyEntry
-	raw := rkentry.GlobalAppCtx.GetEntry("MyEntry", "MyEntry")
-
-	entry, _ := raw.(*MyEntry)
+	entry, _ := rkentry.GetEntryAs[*MyEntry](rkentry.GlobalAppCtx, "my-entry")
 
 	// 3: bootstrap entry
 	entry.Bootstrap(context.Background())
 }
 
-// Register entry, must be in init() function since we need to register entry at beginning
+// Register entry factory, must be in init() function since we need to register entry at beginning
 func init() {
-	rkentry.RegisterUserEntryRegFunc(RegisterMyEntryFromConfig)
+	rkentry.RegisterEntryFactory("myEntry", func() *BootMyEntry {
+		return &BootMyEntry{}
+	}, NewMyEntryFromConfig)
 }
 
-// BootConfig A struct which is for unmarshalled YAML
-type BootConfig struct {
-	MyEntry struct {
-		Enabled     bool   `yaml:"enabled" json:"enabled"`
-		Name        string `yaml:"name" json:"name"`
-		Description string `yaml:"description" json:"description"`
-		Key         string `yaml:"key" json:"key"`
-	} `yaml:"myEntry" json:"myEntry"`
+// BootMyEntry A struct which is for unmarshalled YAML of myEntry section
+type BootMyEntry struct {
+	Enabled     bool   `yaml:"enabled" json:"enabled"`
+	Name        string `yaml:"name" json:"name"`
+	Description string `yaml:"description" json:"description"`
+	Key         string `yaml:"key" json:"key"`
 }
 
-// RegisterMyEntryFromConfig an implementation of:
-// type EntryRegFunc func([]byte) map[string]rke.Entry
-func RegisterMyEntryFromConfig(raw []byte) map[string]rkentry.Entry {
-	res := make(map[string]rkentry.Entry)
-
-	// 1: decode config map into boot config struct
-	config := &BootConfig{}
-	rkentry.UnmarshalBootYAML(raw, config)
-
-	// 3: construct entry
-	if config.MyEntry.Enabled {
-		entry := RegisterMyEntry(
-			WithName(config.MyEntry.Name),
-			WithDescription(config.MyEntry.Description),
-			WithKey(config.MyEntry.Key))
-		res[entry.GetName()] = entry
+// NewMyEntryFromConfig an implementation of factory which creates entry from myEntry section,
+// entry will be registered into rkentry.GlobalAppCtx by rkentry.RegisterFactoryEntryYAML.
+func NewMyEntryFromConfig(config *BootMyEntry) (rkentry.Entry, error) {
+	if !config.Enabled {
+		return nil, nil
 	}
 
-	return res
+	return NewMyEntry(
+		WithName(config.Name),
+		WithDescription(config.Description),
+		WithKey(config.Key)), nil
 }
 
-// RegisterMyEntry register entry based on code
-func RegisterMyEntry(opts ...MyEntryOption) *MyEntry {
+// NewMyEntry create entry based on code
+func NewMyEntry(opts ...MyEntryOption) *MyEntry {
 	entry := &MyEntry{
 		EntryName:        "MyEntry",
 		EntryType:        "MyEntry",
@@ -88,8 +78,6 @@ func RegisterMyEntry(opts ...MyEntryOption) *MyEntry {
 		entry.EntryDescription = "Please contact maintainers to add description of this entry."
 	}
 
-	rkentry.GlobalAppCtx.AddEntry(entry)
-
 	return entry
 }
 
@@ -9,6 +9,7 @@ package rkmidauth
 import (
 	"encoding/base64"
 	"fmt"
+	"github.com/rookie-ninja/rk-entry/v2/entry"
 	"github.com/rookie-ninja/rk-entry/v2/error"
 	"github.com/rookie-ninja/rk-entry/v2/middleware"
 	"net/http"
@@ -44,6 +45,7 @@ type optionSet struct {
 	basicAccounts map[string]bool
 	apiKey        map[string]bool
 	pathToIgnore  []string
+	appCtx        *rkentry.AppContext
 	mock          OptionSetInterface
 }
 
@@ -56,6 +58,7 @@ func NewOptionSet(opts ...Option) OptionSetInterface {
 		basicAccounts: make(map[string]bool),
 		apiKey:        make(map[string]bool),
 		pathToIgnore:  []string{},
+		appCtx:        rkentry.GlobalAppCtx,
 	}
 
 	for i := range opts {
@@ -205,6 +208,70 @@ func (set *optionSet) isApiKeyAuthorized(ctx *BeforeCtx) rkerror.ErrorInterface
 	return rkmid.GetErrorBuilder().New(http.StatusUnauthorized, "Missing authorization header")
 }
 
+// ***************** OptionSet Reloadable *****************
+
+// NewReloadableOptionSet create ReloadableOptionSet with options.
+//
+// Use it instead of NewOptionSet if middleware should be reloaded with BootConfig at runtime.
+func NewReloadableOptionSet(opts ...Option) *ReloadableOptionSet {
+	return &ReloadableOptionSet{
+		set: rkmid.NewReloadableSet(NewOptionSet(opts...)),
+	}
+}
+
+// ReloadableOptionSet implements OptionSetInterface, underlying optionSet could be replaced by Reload() concurrently.
+type ReloadableOptionSet struct {
+	set *rkmid.ReloadableSet[OptionSetInterface]
+}
+
+// Reload rebuild underlying optionSet with BootConfig, entry name and entry type are kept.
+//
+// Middleware could not be enabled or disabled at runtime since middleware chain is already built.
+func (r *ReloadableOptionSet) Reload(config *BootConfig) error {
+	return r.set.Reload("auth", config != nil && config.Enabled, func() OptionSetInterface {
+		return NewOptionSet(ToOptions(config, r.GetEntryName(), r.GetEntryType(), rkentry.WithAppCtx(r.appCtx()))...)
+	})
+}
+
+// appCtx returns rkentry.AppContext of underlying optionSet, nil returned if mocked
+func (r *ReloadableOptionSet) appCtx() *rkentry.AppContext {
+	if set, ok := r.current().(*optionSet); ok {
+		return set.appCtx
+	}
+
+	return nil
+}
+
+// current returns underlying optionSet
+func (r *ReloadableOptionSet) current() OptionSetInterface {
+	return r.set.Load()
+}
+
+// GetEntryName returns entry name
+func (r *ReloadableOptionSet) GetEntryName() string {
+	return r.current().GetEntryName()
+}
+
+// GetEntryType returns entry type
+func (r *ReloadableOptionSet) GetEntryType() string {
+	return r.current().GetEntryType()
+}
+
+// BeforeCtx should be created before Before()
+func (r *ReloadableOptionSet) BeforeCtx(req *http.Request) *BeforeCtx {
+	return r.current().BeforeCtx(req)
+}
+
+// Before should run before user handler
+func (r *ReloadableOptionSet) Before(ctx *BeforeCtx) {
+	r.current().Before(ctx)
+}
+
+// ShouldIgnore determine whether auth should be ignored based on path
+func (r *ReloadableOptionSet) ShouldIgnore(path string) bool {
+	return r.current().ShouldIgnore(path)
+}
+
 // ***************** OptionSet Mock *****************
 
 // NewOptionSetMock for testing purpose
@@ -276,12 +343,13 @@ type BootConfig struct {
 }
 
 // ToOptions convert BootConfig into Option list
-func ToOptions(config *BootConfig, entryName, entryType string) []Option {
+func ToOptions(config *BootConfig, entryName, entryType string, appOpts ...rkentry.RegOption) []Option {
 	opts := make([]Option, 0)
 
 	if config.Enabled {
 		opts = append(opts,
 			WithEntryNameAndType(entryName, entryType),
+			WithAppCtx(rkentry.NewRegOptions(appOpts...).AppCtx),
 			WithBasicAuth(entryName, config.Basic...),
 			WithApiKeyAuth(config.ApiKey...),
 			WithPathToIgnore(config.Ignore...))
@@ -303,6 +371,15 @@ func WithEntryNameAndType(entryName, entryType string) Option {
 	}
 }
 
+// WithAppCtx provide rkentry.AppContext, rkentry.GlobalAppCtx by default.
+func WithAppCtx(ctx *rkentry.AppContext) Option {
+	return func(opt *optionSet) {
+		if ctx != nil {
+			opt.appCtx = ctx
+		}
+	}
+}
+
 // WithBasicAuth provide basic auth credentials formed as user:pass.
 // We will encode credential with base64 since incoming credential from client would be encoded.
 func WithBasicAuth(realm string, cred ...string) Option {
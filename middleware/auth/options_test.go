@@ -8,6 +8,7 @@ package rkmidauth
 import (
 	"encoding/base64"
 	"fmt"
+	"github.com/rookie-ninja/rk-entry/v2/entry"
 	"github.com/rookie-ninja/rk-entry/v2/middleware"
 	"github.com/stretchr/testify/assert"
 	"net/http"
@@ -217,3 +218,53 @@ func TestNewOptionSetMock(t *testing.T) {
 	assert.NotNil(t, mock.BeforeCtx(nil))
 	mock.Before(nil)
 }
+
+func TestReloadableOptionSet(t *testing.T) {
+	set := NewReloadableOptionSet(
+		WithEntryNameAndType("ut-name", "ut-type"),
+		WithApiKeyAuth("ut-key"))
+
+	// with disabled
+	err := set.Reload(&BootConfig{Enabled: false})
+	assert.ErrorIs(t, err, rkentry.ErrRestartRequired)
+	assert.ErrorIs(t, err, rkmid.ErrRestartRequired)
+	assert.Contains(t, err.Error(), "auth middleware disabled")
+
+	req := httptest.NewRequest(http.MethodGet, "/ut-path", nil)
+	req.Header.Set(rkmid.HeaderApiKey, "ut-new-key")
+	ctx := set.BeforeCtx(req)
+	set.Before(ctx)
+	assert.NotNil(t, ctx.Output.ErrResp)
+
+	// with new api key
+	assert.Nil(t, set.Reload(&BootConfig{
+		Enabled: true,
+		ApiKey:  []string{"ut-new-key"},
+		Ignore:  []string{"/ut-ignore"},
+	}))
+	ctx = set.BeforeCtx(req)
+	set.Before(ctx)
+	assert.Nil(t, ctx.Output.ErrResp)
+
+	assert.Equal(t, "ut-name", set.GetEntryName())
+	assert.Equal(t, "ut-type", set.GetEntryType())
+	assert.True(t, set.ShouldIgnore("/ut-ignore"))
+}
+
+func TestToOptions_WithAppCtx(t *testing.T) {
+	appCtx := rkentry.NewAppContext()
+	config := &BootConfig{Enabled: true}
+
+	// GlobalAppCtx by default
+	set := NewOptionSet(ToOptions(config, "", "")...).(*optionSet)
+	assert.Same(t, rkentry.GlobalAppCtx, set.appCtx)
+
+	// with AppContext
+	set = NewOptionSet(ToOptions(config, "", "", rkentry.WithAppCtx(appCtx))...).(*optionSet)
+	assert.Same(t, appCtx, set.appCtx)
+
+	// kept after reloaded
+	reloadable := NewReloadableOptionSet(ToOptions(config, "ut-name", "ut-type", rkentry.WithAppCtx(appCtx))...)
+	assert.Nil(t, reloadable.Reload(config))
+	assert.Same(t, appCtx, reloadable.appCtx())
+}
@@ -1,11 +1,14 @@
 package rkmid
 
 import (
+	"errors"
+	"fmt"
 	"net"
 	"net/http"
 	"os"
 	"regexp"
 	"strings"
+	"sync"
 
 	"github.com/google/uuid"
 	rkerror "github.com/rookie-ninja/rk-entry/v2/error"
@@ -54,6 +57,7 @@ var (
 	PropagatorKey     = &propagatorKey{}
 	JwtTokenKey       = &jwtTokenKey{}
 	CsrfTokenKey      = &csrfTokenKey{}
+	FeatureFlagKey    = &featureFlagKey{}
 
 	// Domain environment variable
 	Domain = zap.String("domain", getEnvValueOrDefault("DOMAIN", "*"))
@@ -145,6 +149,58 @@ func (key *csrfTokenKey) String() string {
 	return "csrfTokenKeyRk"
 }
 
+type featureFlagKey struct{}
+
+func (key *featureFlagKey) String() string {
+	return "featureFlagKeyRk"
+}
+
+// ErrRestartRequired is returned (or wrapped) if new config could only be applied after restart.
+var ErrRestartRequired = errors.New("restart required")
+
+// ReloadableSet keeps option set of middleware which could be replaced by Store() concurrently.
+//
+// It is shared by ReloadableOptionSet of auth, cors and ratelimit middleware.
+type ReloadableSet[T any] struct {
+	lock sync.RWMutex
+	set  T
+}
+
+// NewReloadableSet create ReloadableSet with option set.
+func NewReloadableSet[T any](set T) *ReloadableSet[T] {
+	return &ReloadableSet[T]{
+		set: set,
+	}
+}
+
+// Load returns current option set.
+func (r *ReloadableSet[T]) Load() T {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	return r.set
+}
+
+// Store replace current option set.
+func (r *ReloadableSet[T]) Store(set T) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.set = set
+}
+
+// Reload replace current option set with the one created by newSet.
+//
+// Middleware could not be enabled or disabled at runtime since middleware chain is already built,
+// ErrRestartRequired is returned with name of middleware if new config is disabled.
+func (r *ReloadableSet[T]) Reload(name string, enabled bool, newSet func() T) error {
+	if !enabled {
+		return fmt.Errorf("%s middleware disabled, %w", name, ErrRestartRequired)
+	}
+
+	r.Store(newSet())
+
+	return nil
+}
+
 // GetRemoteAddressSet returns remote endpoint information set including IP, Port.
 // We will do as best as we can to determine it.
 // If fails, then just return default ones.
@@ -7,6 +7,7 @@
 package rkmidcors
 
 import (
+	"github.com/rookie-ninja/rk-entry/v2/entry"
 	"github.com/rookie-ninja/rk-entry/v2/middleware"
 	"net/http"
 	"regexp"
@@ -36,6 +37,7 @@ type optionSet struct {
 	entryName    string
 	entryType    string
 	pathToIgnore []string
+	appCtx       *rkentry.AppContext
 	mock         OptionSetInterface
 	// AllowOrigins defines a list of origins that may access the resource.
 	// Optional. Default value []string{"*"}.
@@ -79,6 +81,7 @@ func NewOptionSet(opts ...Option) OptionSetInterface {
 		allowCredentials: false,
 		exposeHeaders:    []string{},
 		maxAge:           0,
+		appCtx:           rkentry.GlobalAppCtx,
 	}
 
 	for i := range opts {
@@ -255,6 +258,70 @@ func (set *optionSet) ShouldIgnore(path string) bool {
 	return rkmid.ShouldIgnoreGlobal(path)
 }
 
+// ***************** OptionSet Reloadable *****************
+
+// NewReloadableOptionSet create ReloadableOptionSet with options.
+//
+// Use it instead of NewOptionSet if middleware should be reloaded with BootConfig at runtime.
+func NewReloadableOptionSet(opts ...Option) *ReloadableOptionSet {
+	return &ReloadableOptionSet{
+		set: rkmid.NewReloadableSet(NewOptionSet(opts...)),
+	}
+}
+
+// ReloadableOptionSet implements OptionSetInterface, underlying optionSet could be replaced by Reload() concurrently.
+type ReloadableOptionSet struct {
+	set *rkmid.ReloadableSet[OptionSetInterface]
+}
+
+// Reload rebuild underlying optionSet with BootConfig, entry name and entry type are kept.
+//
+// Middleware could not be enabled or disabled at runtime since middleware chain is already built.
+func (r *ReloadableOptionSet) Reload(config *BootConfig) error {
+	return r.set.Reload("cors", config != nil && config.Enabled, func() OptionSetInterface {
+		return NewOptionSet(ToOptions(config, r.GetEntryName(), r.GetEntryType(), rkentry.WithAppCtx(r.appCtx()))...)
+	})
+}
+
+// appCtx returns rkentry.AppContext of underlying optionSet, nil returned if mocked
+func (r *ReloadableOptionSet) appCtx() *rkentry.AppContext {
+	if set, ok := r.current().(*optionSet); ok {
+		return set.appCtx
+	}
+
+	return nil
+}
+
+// current returns underlying optionSet
+func (r *ReloadableOptionSet) current() OptionSetInterface {
+	return r.set.Load()
+}
+
+// GetEntryName returns entry name
+func (r *ReloadableOptionSet) GetEntryName() string {
+	return r.current().GetEntryName()
+}
+
+// GetEntryType returns entry type
+func (r *ReloadableOptionSet) GetEntryType() string {
+	return r.current().GetEntryType()
+}
+
+// BeforeCtx should be created before Before()
+func (r *ReloadableOptionSet) BeforeCtx(req *http.Request) *BeforeCtx {
+	return r.current().BeforeCtx(req)
+}
+
+// Before should run before user handler
+func (r *ReloadableOptionSet) Before(ctx *BeforeCtx) {
+	r.current().Before(ctx)
+}
+
+// ShouldIgnore determine whether cors should be ignored based on path
+func (r *ReloadableOptionSet) ShouldIgnore(path string) bool {
+	return r.current().ShouldIgnore(path)
+}
+
 // ***************** OptionSet Mock *****************
 
 // NewOptionSetMock for testing purpose
@@ -333,12 +400,13 @@ type BootConfig struct {
 }
 
 // ToOptions convert BootConfig into Option list
-func ToOptions(config *BootConfig, entryName, entryType string) []Option {
+func ToOptions(config *BootConfig, entryName, entryType string, appOpts ...rkentry.RegOption) []Option {
 	opts := make([]Option, 0)
 
 	if config.Enabled {
 		opts = append(opts,
 			WithEntryNameAndType(entryName, entryType),
+			WithAppCtx(rkentry.NewRegOptions(appOpts...).AppCtx),
 			WithAllowOrigins(config.AllowOrigins...),
 			WithAllowCredentials(config.AllowCredentials),
 			WithExposeHeaders(config.ExposeHeaders...),
@@ -364,6 +432,15 @@ func WithEntryNameAndType(entryName, entryType string) Option {
 	}
 }
 
+// WithAppCtx provide rkentry.AppContext, rkentry.GlobalAppCtx by default.
+func WithAppCtx(ctx *rkentry.AppContext) Option {
+	return func(opt *optionSet) {
+		if ctx != nil {
+			opt.appCtx = ctx
+		}
+	}
+}
+
 // WithAllowOrigins provide allowed origins.
 func WithAllowOrigins(origins ...string) Option {
 	return func(opt *optionSet) {
@@ -1,6 +1,7 @@
 package rkmidcors
 
 import (
+	"github.com/rookie-ninja/rk-entry/v2/entry"
 	"github.com/rookie-ninja/rk-entry/v2/middleware"
 	"github.com/stretchr/testify/assert"
 	"net/http"
@@ -230,3 +231,52 @@ func TestNewOptionSetMock(t *testing.T) {
 	assert.NotNil(t, mock.BeforeCtx(nil))
 	mock.Before(nil)
 }
+
+func TestReloadableOptionSet(t *testing.T) {
+	originHeaderValue := "http://ut-origin"
+	set := NewReloadableOptionSet(
+		WithEntryNameAndType("ut-name", "ut-type"),
+		WithAllowOrigins("http://do-not-pass-through"))
+
+	// with disabled
+	err := set.Reload(&BootConfig{Enabled: false})
+	assert.ErrorIs(t, err, rkentry.ErrRestartRequired)
+	assert.ErrorIs(t, err, rkmid.ErrRestartRequired)
+	assert.Contains(t, err.Error(), "cors middleware disabled")
+
+	req := newReq(http.MethodGet, header{rkmid.HeaderOrigin, originHeaderValue})
+	ctx := set.BeforeCtx(req)
+	set.Before(ctx)
+	assert.True(t, ctx.Output.Abort)
+
+	// with new origins
+	assert.Nil(t, set.Reload(&BootConfig{
+		Enabled:      true,
+		AllowOrigins: []string{originHeaderValue},
+	}))
+	ctx = set.BeforeCtx(req)
+	set.Before(ctx)
+	assert.False(t, ctx.Output.Abort)
+
+	assert.Equal(t, "ut-name", set.GetEntryName())
+	assert.Equal(t, "ut-type", set.GetEntryType())
+	assert.False(t, set.ShouldIgnore("/ut-path"))
+}
+
+func TestToOptions_WithAppCtx(t *testing.T) {
+	appCtx := rkentry.NewAppContext()
+	config := &BootConfig{Enabled: true}
+
+	// GlobalAppCtx by default
+	set := NewOptionSet(ToOptions(config, "", "")...).(*optionSet)
+	assert.Same(t, rkentry.GlobalAppCtx, set.appCtx)
+
+	// with AppContext
+	set = NewOptionSet(ToOptions(config, "", "", rkentry.WithAppCtx(appCtx))...).(*optionSet)
+	assert.Same(t, appCtx, set.appCtx)
+
+	// kept after reloaded
+	reloadable := NewReloadableOptionSet(ToOptions(config, "ut-name", "ut-type", rkentry.WithAppCtx(appCtx))...)
+	assert.Nil(t, reloadable.Reload(config))
+	assert.Same(t, appCtx, reloadable.appCtx())
+}
@@ -10,6 +10,7 @@ import (
 	"context"
 	"crypto/subtle"
 	"errors"
+	"github.com/rookie-ninja/rk-entry/v2/entry"
 	"github.com/rookie-ninja/rk-entry/v2/error"
 	"github.com/rookie-ninja/rk-entry/v2/middleware"
 	"math/rand"
@@ -99,7 +100,8 @@ type optionSet struct {
 
 	userExtractor CsrfExtractor
 
-	mock OptionSetInterface
+	appCtx *rkentry.AppContext
+	mock   OptionSetInterface
 }
 
 // NewOptionSet Create new optionSet with options.
@@ -113,6 +115,7 @@ func NewOptionSet(opts ...Option) OptionSetInterface {
 		cookieMaxAge:   86400,
 		cookieSameSite: http.SameSiteDefaultMode,
 		pathToIgnore:   make([]string, 0),
+		appCtx:         rkentry.GlobalAppCtx,
 	}
 
 	for i := range opts {
@@ -317,12 +320,13 @@ type BootConfig struct {
 }
 
 // ToOptions convert BootConfig into Option list
-func ToOptions(config *BootConfig, entryName, entryType string) []Option {
+func ToOptions(config *BootConfig, entryName, entryType string, appOpts ...rkentry.RegOption) []Option {
 	opts := make([]Option, 0)
 
 	if config.Enabled {
 		opts = append(opts,
 			WithEntryNameAndType(entryName, entryType),
+			WithAppCtx(rkentry.NewRegOptions(appOpts...).AppCtx),
 			WithTokenLength(config.TokenLength),
 			WithTokenLookup(config.TokenLookup),
 			WithCookieName(config.CookieName),
@@ -365,6 +369,15 @@ func WithEntryNameAndType(entryName, entryType string) Option {
 	}
 }
 
+// WithAppCtx provide rkentry.AppContext, rkentry.GlobalAppCtx by default.
+func WithAppCtx(ctx *rkentry.AppContext) Option {
+	return func(opt *optionSet) {
+		if ctx != nil {
+			opt.appCtx = ctx
+		}
+	}
+}
+
 // WithTokenLength the length of the generated token.
 // Optional. Default value 32.
 func WithTokenLength(val int) Option {
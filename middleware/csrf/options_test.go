@@ -7,6 +7,7 @@ package rkmidcsrf
 
 import (
 	"context"
+	"github.com/rookie-ninja/rk-entry/v2/entry"
 	"github.com/rookie-ninja/rk-entry/v2/middleware"
 	"github.com/stretchr/testify/assert"
 	"net/http"
@@ -218,3 +219,16 @@ func TestNewOptionSetMock(t *testing.T) {
 	assert.NotNil(t, mock.BeforeCtx(nil))
 	mock.Before(nil)
 }
+
+func TestToOptions_WithAppCtx(t *testing.T) {
+	appCtx := rkentry.NewAppContext()
+	config := &BootConfig{Enabled: true}
+
+	// GlobalAppCtx by default
+	set := NewOptionSet(ToOptions(config, "", "")...).(*optionSet)
+	assert.Same(t, rkentry.GlobalAppCtx, set.appCtx)
+
+	// with AppContext
+	set = NewOptionSet(ToOptions(config, "", "", rkentry.WithAppCtx(appCtx))...).(*optionSet)
+	assert.Same(t, appCtx, set.appCtx)
+}
@@ -0,0 +1,353 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+// Package rkmidfeatureflag is a middleware for feature flags
+package rkmidfeatureflag
+
+import (
+	"context"
+	"fmt"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/rookie-ninja/rk-entry/v2/entry"
+	"github.com/rookie-ninja/rk-entry/v2/middleware"
+	"net"
+	"net/http"
+	"strings"
+)
+
+const (
+	// DefaultSubjectClaim is JWT claim of subject key
+	DefaultSubjectClaim = "sub"
+)
+
+// ***************** OptionSet Interface *****************
+
+// OptionSetInterface mainly for testing purpose
+type OptionSetInterface interface {
+	GetEntryName() string
+
+	GetEntryType() string
+
+	Before(*BeforeCtx)
+
+	BeforeCtx(*http.Request, *jwt.Token) *BeforeCtx
+
+	ShouldIgnore(string) bool
+}
+
+// ***************** OptionSet Implementation *****************
+
+// optionSet which is used for middleware implementation
+type optionSet struct {
+	entryName     string
+	entryType     string
+	flagEntry     string
+	subjectHeader string
+	subjectClaim  string
+	pathToIgnore  []string
+	appCtx        *rkentry.AppContext
+	mock          OptionSetInterface
+}
+
+// NewOptionSet Create new optionSet with options.
+func NewOptionSet(opts ...Option) OptionSetInterface {
+	set := &optionSet{
+		entryName:    "fake-entry",
+		entryType:    "",
+		subjectClaim: DefaultSubjectClaim,
+		pathToIgnore: []string{},
+		appCtx:       rkentry.GlobalAppCtx,
+	}
+
+	for i := range opts {
+		opts[i](set)
+	}
+
+	if set.mock != nil {
+		return set.mock
+	}
+
+	return set
+}
+
+// GetEntryName returns entry name
+func (set *optionSet) GetEntryName() string {
+	return set.entryName
+}
+
+// GetEntryType returns entry type
+func (set *optionSet) GetEntryType() string {
+	return set.entryType
+}
+
+// BeforeCtx should be created before Before(), token is parsed by JWT middleware and could be nil
+func (set *optionSet) BeforeCtx(req *http.Request, token *jwt.Token) *BeforeCtx {
+	ctx := NewBeforeCtx()
+
+	ctx.Input.Request = req
+	if req != nil && req.URL != nil {
+		ctx.Input.UrlPath = req.URL.Path
+	}
+
+	ctx.Input.JwtToken = token
+	return ctx
+}
+
+// Before should run before user handler
+func (set *optionSet) Before(ctx *BeforeCtx) {
+	if ctx == nil {
+		return
+	}
+
+	// case 0: ignore path
+	if set.ShouldIgnore(ctx.Input.UrlPath) {
+		return
+	}
+
+	// case 1: FeatureFlagEntry not found, flags are off with nil evaluator
+	entry := set.getFlagEntry()
+	if entry == nil {
+		return
+	}
+
+	// case 2: evaluate flags with attributes of request
+	flagCtx := &rkentry.FeatureFlagContext{
+		Claims: map[string]interface{}{},
+	}
+
+	if ctx.Input.JwtToken != nil {
+		if claims, ok := ctx.Input.JwtToken.Claims.(jwt.MapClaims); ok {
+			flagCtx.Claims = claims
+		}
+	}
+
+	if v, ok := flagCtx.Claims[set.subjectClaim]; ok && v != nil {
+		flagCtx.SubjectKey = fmt.Sprint(v)
+	}
+
+	if req := ctx.Input.Request; req != nil {
+		flagCtx.Headers = req.Header
+		flagCtx.Domain = hostWithoutPort(req.Host)
+
+		// header is trusted only if configured explicitly, since it could be set by any client
+		if len(flagCtx.SubjectKey) < 1 && len(set.subjectHeader) > 0 {
+			flagCtx.SubjectKey = req.Header.Get(set.subjectHeader)
+		}
+	}
+
+	ctx.Output.Evaluator = entry.Evaluator(flagCtx)
+
+	if ctx.Input.Request != nil {
+		ctx.Output.Request = ctx.Input.Request.WithContext(
+			context.WithValue(ctx.Input.Request.Context(), rkmid.FeatureFlagKey, ctx.Output.Evaluator))
+	}
+}
+
+// ShouldIgnore determine whether feature flags should be ignored based on path
+func (set *optionSet) ShouldIgnore(path string) bool {
+	for i := range set.pathToIgnore {
+		if strings.HasPrefix(path, set.pathToIgnore[i]) {
+			return true
+		}
+	}
+
+	return rkmid.ShouldIgnoreGlobal(path)
+}
+
+// getFlagEntry returns FeatureFlagEntry with name, the only one in AppContext is used if name is empty.
+func (set *optionSet) getFlagEntry() *rkentry.FeatureFlagEntry {
+	if len(set.flagEntry) > 0 {
+		return set.appCtx.GetFeatureFlagEntry(set.flagEntry)
+	}
+
+	if entries := rkentry.ListEntriesAs[*rkentry.FeatureFlagEntry](set.appCtx); len(entries) == 1 {
+		return entries[0]
+	}
+
+	return nil
+}
+
+// hostWithoutPort returns lowered host without port.
+func hostWithoutPort(host string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+
+	return strings.ToLower(host)
+}
+
+// GetEvaluator returns evaluator which was put into context by middleware.
+//
+// Nil evaluator is returned if missing, flags of nil evaluator are off.
+func GetEvaluator(ctx context.Context) *rkentry.FeatureFlagEvaluator {
+	if ctx == nil {
+		return nil
+	}
+
+	if v, ok := ctx.Value(rkmid.FeatureFlagKey).(*rkentry.FeatureFlagEvaluator); ok {
+		return v
+	}
+
+	return nil
+}
+
+// ***************** OptionSet Mock *****************
+
+// NewOptionSetMock for testing purpose
+func NewOptionSetMock(before *BeforeCtx) OptionSetInterface {
+	return &optionSetMock{
+		before: before,
+	}
+}
+
+type optionSetMock struct {
+	before *BeforeCtx
+}
+
+// GetEntryName returns entry name
+func (mock *optionSetMock) GetEntryName() string {
+	return "mock"
+}
+
+// GetEntryType returns entry type
+func (mock *optionSetMock) GetEntryType() string {
+	return "mock"
+}
+
+// BeforeCtx should be created before Before()
+func (mock *optionSetMock) BeforeCtx(*http.Request, *jwt.Token) *BeforeCtx {
+	return mock.before
+}
+
+// Before should run before user handler
+func (mock *optionSetMock) Before(ctx *BeforeCtx) {
+	return
+}
+
+// ShouldIgnore should run before user handler
+func (mock *optionSetMock) ShouldIgnore(string) bool {
+	return false
+}
+
+// ***************** Context *****************
+
+// NewBeforeCtx create new BeforeCtx with fields initialized
+func NewBeforeCtx() *BeforeCtx {
+	ctx := &BeforeCtx{}
+	return ctx
+}
+
+// BeforeCtx context for Before() function
+//
+// Output.Evaluator should be stored into context of framework with rkmid.FeatureFlagKey,
+// Output.Request carries it in request context already.
+type BeforeCtx struct {
+	Input struct {
+		UrlPath  string
+		Request  *http.Request
+		JwtToken *jwt.Token
+	}
+	Output struct {
+		Evaluator *rkentry.FeatureFlagEvaluator
+		Request   *http.Request
+	}
+}
+
+// ***************** BootConfig *****************
+
+// BootConfig for YAML
+type BootConfig struct {
+	Enabled bool `yaml:"enabled" json:"enabled"`
+	// EntryName is name of FeatureFlagEntry, could be empty if only one FeatureFlagEntry exists
+	EntryName string `yaml:"entryName" json:"entryName"`
+	// SubjectHeader is request header of subject key if JWT claim is missing, header is not read if empty
+	SubjectHeader string   `yaml:"subjectHeader" json:"subjectHeader"`
+	SubjectClaim  string   `yaml:"subjectClaim" json:"subjectClaim"`
+	Ignore        []string `yaml:"ignore" json:"ignore"`
+}
+
+// ToOptions convert BootConfig into Option list
+func ToOptions(config *BootConfig, entryName, entryType string, appOpts ...rkentry.RegOption) []Option {
+	opts := make([]Option, 0)
+
+	if config.Enabled {
+		opts = append(opts,
+			WithEntryNameAndType(entryName, entryType),
+			WithFlagEntry(config.EntryName),
+			WithSubjectHeader(config.SubjectHeader),
+			WithSubjectClaim(config.SubjectClaim),
+			WithPathToIgnore(config.Ignore...),
+			WithAppCtx(rkentry.NewRegOptions(appOpts...).AppCtx))
+	}
+
+	return opts
+}
+
+// ***************** Option *****************
+
+// Option if for middleware options while creating middleware
+type Option func(*optionSet)
+
+// WithEntryNameAndType provide entry name and entry type.
+func WithEntryNameAndType(entryName, entryType string) Option {
+	return func(opt *optionSet) {
+		opt.entryName = entryName
+		opt.entryType = entryType
+	}
+}
+
+// WithAppCtx provide rkentry.AppContext which FeatureFlagEntry would be looked up from, rkentry.GlobalAppCtx by default.
+func WithAppCtx(ctx *rkentry.AppContext) Option {
+	return func(opt *optionSet) {
+		if ctx != nil {
+			opt.appCtx = ctx
+		}
+	}
+}
+
+// WithFlagEntry provide name of FeatureFlagEntry.
+func WithFlagEntry(name string) Option {
+	return func(opt *optionSet) {
+		opt.flagEntry = name
+	}
+}
+
+// WithSubjectHeader provide request header of subject key which is read if JWT claim is missing.
+//
+// Header is not read by default, since any client could set it, enable it only behind a trusted proxy.
+func WithSubjectHeader(header string) Option {
+	return func(opt *optionSet) {
+		if len(header) > 0 {
+			opt.subjectHeader = header
+		}
+	}
+}
+
+// WithSubjectClaim provide JWT claim of subject key which wins over header, sub by default.
+func WithSubjectClaim(claim string) Option {
+	return func(opt *optionSet) {
+		if len(claim) > 0 {
+			opt.subjectClaim = claim
+		}
+	}
+}
+
+// WithPathToIgnore provide paths prefix that will ignore.
+func WithPathToIgnore(paths ...string) Option {
+	return func(set *optionSet) {
+		for i := range paths {
+			if len(paths[i]) > 0 {
+				set.pathToIgnore = append(set.pathToIgnore, paths[i])
+			}
+		}
+	}
+}
+
+// WithMockOptionSet provide mock OptionSetInterface
+func WithMockOptionSet(mock OptionSetInterface) Option {
+	return func(set *optionSet) {
+		set.mock = mock
+	}
+}
@@ -0,0 +1,113 @@
+// Copyright (c) 2021 rookie-ninja
+//
+// Use of this source code is governed by an Apache-style
+// license that can be found in the LICENSE file.
+
+package rkmidfeatureflag
+
+import (
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/rookie-ninja/rk-entry/v2/entry"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestToOptions(t *testing.T) {
+	// with disabled
+	config := &BootConfig{
+		Enabled: false,
+	}
+	assert.Empty(t, ToOptions(config, "", ""))
+
+	// with enabled
+	config.Enabled = true
+	assert.NotEmpty(t, ToOptions(config, "", ""))
+}
+
+func TestNewOptionSet(t *testing.T) {
+	// without options
+	set := NewOptionSet().(*optionSet)
+	assert.Empty(t, set.subjectHeader)
+	assert.Equal(t, DefaultSubjectClaim, set.subjectClaim)
+
+	// with options
+	set = NewOptionSet(
+		WithEntryNameAndType("ut-entry", "ut-type"),
+		WithFlagEntry("ut-flags"),
+		WithSubjectHeader("X-Ut-User"),
+		WithSubjectClaim("uid"),
+		WithPathToIgnore("/ut-ignore")).(*optionSet)
+	assert.Equal(t, "ut-entry", set.GetEntryName())
+	assert.Equal(t, "ut-type", set.GetEntryType())
+	assert.Equal(t, "ut-flags", set.flagEntry)
+	assert.Equal(t, "X-Ut-User", set.subjectHeader)
+	assert.Equal(t, "uid", set.subjectClaim)
+	assert.True(t, set.ShouldIgnore("/ut-ignore"))
+
+	// with mock
+	mock := NewOptionSetMock(NewBeforeCtx())
+	assert.Equal(t, mock, NewOptionSet(WithMockOptionSet(mock)))
+}
+
+func TestOptionSet_Before(t *testing.T) {
+	appCtx := rkentry.NewAppContext()
+	set := NewOptionSet(WithAppCtx(appCtx), WithSubjectHeader("X-User-Id"))
+
+	// with nil ctx
+	set.Before(nil)
+
+	// without FeatureFlagEntry
+	ctx := set.BeforeCtx(httptest.NewRequest(http.MethodGet, "/ut", nil), nil)
+	set.Before(ctx)
+	assert.Nil(t, ctx.Output.Evaluator)
+	assert.False(t, ctx.Output.Evaluator.IsEnabled("ut"))
+
+	_, err := rkentry.RegisterFeatureFlagEntryYAMLE([]byte(`
+featureFlag:
+  - name: ut-flags
+    flags:
+      - name: ut
+        enabled: true
+        rollout: 0
+        rules:
+          - claim: tier
+            values: [gold]
+          - domains: ["*.beta.ut"]
+`), rkentry.WithAppCtx(appCtx))
+	assert.Nil(t, err)
+
+	// with claim and subject from claim
+	req := httptest.NewRequest(http.MethodGet, "/ut", nil)
+	req.Header.Set("X-User-Id", "ut-header-user")
+	token := &jwt.Token{Claims: jwt.MapClaims{"sub": "ut-user", "tier": "gold"}}
+
+	ctx = set.BeforeCtx(req, token)
+	set.Before(ctx)
+	assert.True(t, ctx.Output.Evaluator.IsEnabled("ut"))
+	assert.Equal(t, "ut-user", ctx.Output.Evaluator.GetContext().SubjectKey)
+	assert.Equal(t, ctx.Output.Evaluator, GetEvaluator(ctx.Output.Request.Context()))
+
+	// with domain and subject from header
+	req = httptest.NewRequest(http.MethodGet, "http://api.beta.ut:8080/ut", nil)
+	req.Header.Set("X-User-Id", "ut-header-user")
+	ctx = set.BeforeCtx(req, nil)
+	set.Before(ctx)
+	assert.True(t, ctx.Output.Evaluator.IsEnabled("ut"))
+	assert.Equal(t, "api.beta.ut", ctx.Output.Evaluator.GetContext().Domain)
+	assert.Equal(t, "ut-header-user", ctx.Output.Evaluator.GetContext().SubjectKey)
+
+	// header is not read without subject header configured
+	set = NewOptionSet(WithAppCtx(appCtx))
+	ctx = set.BeforeCtx(req, nil)
+	set.Before(ctx)
+	assert.Empty(t, ctx.Output.Evaluator.GetContext().SubjectKey)
+
+	// with ignored path
+	set = NewOptionSet(WithAppCtx(appCtx), WithPathToIgnore("/ut"))
+	ctx = set.BeforeCtx(req, nil)
+	set.Before(ctx)
+	assert.Nil(t, ctx.Output.Evaluator)
+	assert.Nil(t, GetEvaluator(nil))
+}
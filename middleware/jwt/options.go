@@ -9,6 +9,7 @@ package rkmidjwt
 import (
 	"context"
 	"errors"
+	"fmt"
 	"github.com/golang-jwt/jwt/v4"
 	"github.com/rookie-ninja/rk-entry/v2/entry"
 	"github.com/rookie-ninja/rk-entry/v2/error"
@@ -79,6 +80,10 @@ type optionSet struct {
 	// Optional. Default value "false".
 	skipVerify bool
 
+	// AppContext which signer would be registered into.
+	// Optional. Default value rkentry.GlobalAppCtx.
+	appCtx *rkentry.AppContext
+
 	mock OptionSetInterface
 }
 
@@ -90,6 +95,7 @@ func NewOptionSet(opts ...Option) OptionSetInterface {
 		tokenLookup:  "header:" + rkmid.HeaderAuthorization,
 		authScheme:   "Bearer",
 		pathToIgnore: []string{},
+		appCtx:       rkentry.GlobalAppCtx,
 	}
 
 	for i := range opts {
@@ -97,7 +103,7 @@ func NewOptionSet(opts ...Option) OptionSetInterface {
 	}
 
 	if set.signer == nil && !set.skipVerify {
-		set.signer = rkentry.RegisterSymmetricJwtSigner(set.entryName, jwt.SigningMethodHS256.Name, []byte("rk jwt key"))
+		set.signer = rkentry.RegisterSymmetricJwtSigner(set.entryName, jwt.SigningMethodHS256.Name, []byte("rk jwt key"), rkentry.WithAppCtx(set.appCtx))
 	}
 
 	if set.mock != nil {
@@ -292,53 +298,67 @@ type AsymmetricConfig struct {
 }
 
 // ToOptions convert BootConfig into Option list
-func ToOptions(config *BootConfig, entryName, entryType string) []Option {
+func ToOptions(config *BootConfig, entryName, entryType string, appOpts ...rkentry.RegOption) []Option {
+	opts, err := ToOptionsE(config, entryName, entryType, appOpts...)
+	if err != nil {
+		rkentry.ShutdownWithError(err)
+	}
+
+	return opts
+}
+
+// ToOptionsE is the same as ToOptions but returns error instead of panic.
+func ToOptionsE(config *BootConfig, entryName, entryType string, appOpts ...rkentry.RegOption) ([]Option, error) {
 	opts := make([]Option, 0)
+	appCtx := rkentry.NewRegOptions(appOpts...).AppCtx
 
 	if config.Enabled {
 		var signerJwt rkentry.SignerJwt
 
 		// check signer entry first
-		if v := rkentry.GlobalAppCtx.GetEntry(rkentry.SignerJwtEntryType, config.SignerEntry); v != nil {
+		if v := appCtx.GetEntry(rkentry.SignerJwtEntryType, config.SignerEntry); v != nil {
 			signer, ok := v.(rkentry.SignerJwt)
 			if !ok {
-				rkentry.ShutdownWithError(errors.New("invalid signer jwt entry"))
+				return nil, errors.New("invalid signer jwt entry")
 			}
 
 			signerJwt = signer
-			if signerJwt == nil {
-				rkentry.ShutdownWithError(errors.New("cannot find signer entry"))
-			}
 		} else if config.Asymmetric != nil {
 			var pubKey, privKey []byte
+			var err error
 
 			if len(config.Asymmetric.PublicKey) > 0 {
 				pubKey = []byte(config.Asymmetric.PublicKey)
-			} else {
-				pubKey = mustRead(config.Asymmetric.PublicKeyPath)
+			} else if pubKey, err = read(config.Asymmetric.PublicKeyPath); err != nil {
+				return nil, err
 			}
 
 			if len(config.Asymmetric.PrivateKey) > 0 {
 				privKey = []byte(config.Asymmetric.PrivateKey)
-			} else {
-				privKey = mustRead(config.Asymmetric.PrivateKeyPath)
+			} else if privKey, err = read(config.Asymmetric.PrivateKeyPath); err != nil {
+				return nil, err
 			}
 
-			signerJwt = rkentry.RegisterAsymmetricJwtSigner(entryName, config.Asymmetric.Algorithm, privKey, pubKey)
-			if signerJwt == nil {
-				rkentry.ShutdownWithError(errors.New("invalid asymmetric configuration"))
+			signer, err := rkentry.RegisterAsymmetricJwtSignerE(entryName, config.Asymmetric.Algorithm, privKey, pubKey, appOpts...)
+			if err != nil {
+				return nil, fmt.Errorf("invalid asymmetric configuration, %w", err)
 			}
+			signerJwt = signer
 		} else if config.Symmetric != nil {
 			var token []byte
+			var err error
+
 			if len(config.Symmetric.Token) > 0 {
 				token = []byte(config.Symmetric.Token)
-			} else {
-				token = mustRead(config.Symmetric.TokenPath)
+			} else if token, err = read(config.Symmetric.TokenPath); err != nil {
+				return nil, err
 			}
 
-			signerJwt = rkentry.RegisterSymmetricJwtSigner(entryName, config.Symmetric.Algorithm, token)
-			if signerJwt == nil {
-				rkentry.ShutdownWithError(errors.New("invalid symmetric configuration"))
+			// avoid typed nil in interface
+			if signer := rkentry.RegisterSymmetricJwtSigner(entryName, config.Symmetric.Algorithm, token, appOpts...); signer != nil {
+				signerJwt = signer
+			} else {
+				return nil, errors.New("invalid symmetric configuration")
 			}
 		}
 
@@ -349,25 +369,21 @@ func ToOptions(config *BootConfig, entryName, entryType string) []Option {
 			WithAuthScheme(config.AuthScheme),
 			WithPathToIgnore(config.Ignore...),
 			WithSkipVerify(config.SkipVerify),
+			WithAppCtx(appCtx),
 		}
 
 	}
 
-	return opts
+	return opts, nil
 }
 
-func mustRead(p string) []byte {
+func read(p string) ([]byte, error) {
 	if !filepath.IsAbs(p) {
 		wd, _ := os.Getwd()
 		p = filepath.ToSlash(filepath.Join(wd, p))
 	}
 
-	res, err := os.ReadFile(p)
-	if err != nil {
-		rkentry.ShutdownWithError(err)
-	}
-
-	return res
+	return os.ReadFile(p)
 }
 
 // ***************** Option *****************
@@ -383,6 +399,15 @@ func WithEntryNameAndType(entryName, entryType string) Option {
 	}
 }
 
+// WithAppCtx provide rkentry.AppContext, rkentry.GlobalAppCtx by default.
+func WithAppCtx(ctx *rkentry.AppContext) Option {
+	return func(opt *optionSet) {
+		if ctx != nil {
+			opt.appCtx = ctx
+		}
+	}
+}
+
 // WithSigner provide rkentry.SignerJwt.
 func WithSigner(signer rkentry.SignerJwt) Option {
 	return func(opt *optionSet) {
@@ -111,6 +111,83 @@ func TestToOptions_Five(t *testing.T) {
 	rkentry.GlobalAppCtx.RemoveEntryByType(rkentry.SignerJwtEntryType)
 }
 
+func TestToOptionsE(t *testing.T) {
+	defer rkentry.GlobalAppCtx.RemoveEntryByType(rkentry.SignerJwtEntryType)
+
+	// with non-exist token path
+	config := &BootConfig{
+		Enabled: true,
+		Symmetric: &SymmetricConfig{
+			Algorithm: jwt.SigningMethodHS256.Name,
+			TokenPath: "/non-exist-ut-path",
+		},
+	}
+	opts, err := ToOptionsE(config, "", "")
+	assert.Nil(t, opts)
+	assert.NotNil(t, err)
+
+	// with invalid algorithm
+	config.Symmetric = &SymmetricConfig{
+		Algorithm: "invalid",
+		Token:     "ut-key",
+	}
+	opts, err = ToOptionsE(config, "", "")
+	assert.Nil(t, opts)
+	assert.NotNil(t, err)
+
+	// with signer entry which is not SignerJwt
+	appCtx := rkentry.NewAppContext()
+	appCtx.AddEntry(&invalidSignerEntry{})
+	opts, err = ToOptionsE(&BootConfig{
+		Enabled:     true,
+		SignerEntry: "ut-invalid-signer",
+	}, "", "", rkentry.WithAppCtx(appCtx))
+	assert.Nil(t, opts)
+	assert.Contains(t, err.Error(), "invalid signer jwt entry")
+
+	// with invalid asymmetric key
+	opts, err = ToOptionsE(&BootConfig{
+		Enabled: true,
+		Asymmetric: &AsymmetricConfig{
+			Algorithm:  jwt.SigningMethodRS256.Name,
+			PublicKey:  "invalid",
+			PrivateKey: "invalid",
+		},
+	}, "", "")
+	assert.Nil(t, opts)
+	assert.NotNil(t, err)
+
+	// happy case
+	config.Symmetric.Algorithm = jwt.SigningMethodHS256.Name
+	opts, err = ToOptionsE(config, "", "")
+	assert.NotEmpty(t, opts)
+	assert.Nil(t, err)
+}
+
+func TestToOptionsE_WithAppCtx(t *testing.T) {
+	appCtx := rkentry.NewAppContext()
+
+	config := &BootConfig{
+		Enabled: true,
+		Symmetric: &SymmetricConfig{
+			Algorithm: jwt.SigningMethodHS256.Name,
+			Token:     "ut-key",
+		},
+	}
+	opts, err := ToOptionsE(config, "ut-entry", "", rkentry.WithAppCtx(appCtx))
+	assert.Nil(t, err)
+	assert.NotNil(t, appCtx.GetEntry(rkentry.SignerJwtEntryType, "ut-entry"))
+	assert.Nil(t, rkentry.GlobalAppCtx.GetEntry(rkentry.SignerJwtEntryType, "ut-entry"))
+
+	set := NewOptionSet(opts...).(*optionSet)
+	assert.Equal(t, appCtx, set.appCtx)
+
+	// default signer registered into provided AppContext
+	NewOptionSet(WithEntryNameAndType("ut-default", ""), WithAppCtx(appCtx))
+	assert.NotNil(t, appCtx.GetEntry(rkentry.SignerJwtEntryType, "ut-default"))
+	assert.Nil(t, rkentry.GlobalAppCtx.GetEntry(rkentry.SignerJwtEntryType, "ut-default"))
+}
+
 func TestNewOptionSet(t *testing.T) {
 	// without option
 	set := NewOptionSet().(*optionSet)
@@ -222,3 +299,25 @@ func assertPanic(t *testing.T) {
 		assert.True(t, false)
 	}
 }
+
+type invalidSignerEntry struct{}
+
+func (e *invalidSignerEntry) Bootstrap(context.Context) {}
+
+func (e *invalidSignerEntry) Interrupt(context.Context) {}
+
+func (e *invalidSignerEntry) GetName() string {
+	return "ut-invalid-signer"
+}
+
+func (e *invalidSignerEntry) GetType() string {
+	return rkentry.SignerJwtEntryType
+}
+
+func (e *invalidSignerEntry) GetDescription() string {
+	return ""
+}
+
+func (e *invalidSignerEntry) String() string {
+	return ""
+}
@@ -60,6 +60,7 @@ type optionSet struct {
 	eventLoggerOutputPath []string
 	eventLoggerOverride   *zap.Logger
 	pathToIgnore          []string
+	appCtx                *rkentry.AppContext
 	mock                  OptionSetInterface
 }
 
@@ -74,6 +75,7 @@ func NewOptionSet(opts ...Option) OptionSetInterface {
 		zapLoggerOutputPath:   make([]string, 0),
 		eventLoggerOutputPath: make([]string, 0),
 		pathToIgnore:          []string{},
+		appCtx:                rkentry.GlobalAppCtx,
 	}
 
 	for i := range opts {
@@ -230,16 +232,16 @@ func (set *optionSet) createEvent(urlPath string, threadSafe bool) rkquery.Event
 		event = set.eventEntry.EventFactory.CreateEventThreadSafe(
 			rkquery.WithZapLogger(set.eventLoggerOverride),
 			rkquery.WithEncoding(set.eventLoggerEncoding),
-			rkquery.WithAppName(rkentry.GlobalAppCtx.GetAppInfoEntry().AppName),
-			rkquery.WithAppVersion(rkentry.GlobalAppCtx.GetAppInfoEntry().Version),
+			rkquery.WithAppName(set.appCtx.GetAppInfoEntry().AppName),
+			rkquery.WithAppVersion(set.appCtx.GetAppInfoEntry().Version),
 			rkquery.WithEntryName(set.GetEntryName()),
 			rkquery.WithEntryType(set.GetEntryType()))
 	} else {
 		event = set.eventEntry.EventFactory.CreateEvent(
 			rkquery.WithZapLogger(set.eventLoggerOverride),
 			rkquery.WithEncoding(set.eventLoggerEncoding),
-			rkquery.WithAppName(rkentry.GlobalAppCtx.GetAppInfoEntry().AppName),
-			rkquery.WithAppVersion(rkentry.GlobalAppCtx.GetAppInfoEntry().Version),
+			rkquery.WithAppName(set.appCtx.GetAppInfoEntry().AppName),
+			rkquery.WithAppVersion(set.appCtx.GetAppInfoEntry().Version),
 			rkquery.WithEntryName(set.GetEntryName()),
 			rkquery.WithEntryType(set.GetEntryType()))
 	}
@@ -368,7 +370,8 @@ type BootConfig struct {
 func ToOptions(config *BootConfig,
 	entryName, entryType string,
 	loggerEntry *rkentry.LoggerEntry,
-	eventEntry *rkentry.EventEntry) []Option {
+	eventEntry *rkentry.EventEntry,
+	appOpts ...rkentry.RegOption) []Option {
 	opts := make([]Option, 0)
 
 	if config.Enabled {
@@ -380,7 +383,8 @@ func ToOptions(config *BootConfig,
 			WithEventEncoding(config.EventEncoding),
 			WithLoggerOutputPaths(config.LoggerOutputPaths...),
 			WithEventOutputPaths(config.EventOutputPaths...),
-			WithPathToIgnore(config.Ignore...))
+			WithPathToIgnore(config.Ignore...),
+			WithAppCtx(rkentry.NewRegOptions(appOpts...).AppCtx))
 	}
 
 	return opts
@@ -399,6 +403,15 @@ func WithEntryNameAndType(entryName, entryType string) Option {
 	}
 }
 
+// WithAppCtx provide rkentry.AppContext, rkentry.GlobalAppCtx by default.
+func WithAppCtx(ctx *rkentry.AppContext) Option {
+	return func(opt *optionSet) {
+		if ctx != nil {
+			opt.appCtx = ctx
+		}
+	}
+}
+
 // WithLoggerEntry provide rkentry.LoggerEntry.
 func WithLoggerEntry(loggerEntry *rkentry.LoggerEntry) Option {
 	return func(set *optionSet) {
@@ -44,6 +44,7 @@ type optionSet struct {
 	appUnixTimeKey  string
 	receivedTimeKey string
 	pathToIgnore    []string
+	appCtx          *rkentry.AppContext
 	mock            OptionSetInterface
 }
 
@@ -54,6 +55,7 @@ func NewOptionSet(opts ...Option) OptionSetInterface {
 		entryType:    "",
 		prefix:       "RK",
 		pathToIgnore: []string{},
+		appCtx:       rkentry.GlobalAppCtx,
 	}
 
 	for i := range opts {
@@ -122,8 +124,8 @@ func (set *optionSet) Before(ctx *BeforeCtx) {
 	ctx.Output.RequestId = reqId
 
 	ctx.Output.HeadersToReturn[rkmid.HeaderRequestId] = reqId
-	ctx.Output.HeadersToReturn[fmt.Sprintf("X-%s-App-Name", set.prefix)] = rkentry.GlobalAppCtx.GetAppInfoEntry().AppName
-	ctx.Output.HeadersToReturn[fmt.Sprintf("X-%s-App-Version", set.prefix)] = rkentry.GlobalAppCtx.GetAppInfoEntry().Version
+	ctx.Output.HeadersToReturn[fmt.Sprintf("X-%s-App-Name", set.prefix)] = set.appCtx.GetAppInfoEntry().AppName
+	ctx.Output.HeadersToReturn[fmt.Sprintf("X-%s-App-Version", set.prefix)] = set.appCtx.GetAppInfoEntry().Version
 	ctx.Output.HeadersToReturn[fmt.Sprintf("X-%s-App-Unix-Time", set.prefix)] = now
 	ctx.Output.HeadersToReturn[fmt.Sprintf("X-%s-Received-Time", set.prefix)] = now
 	ctx.Output.HeadersToReturn[fmt.Sprintf("X-%s-App-Domain", set.prefix)] = rkmid.Domain.String
@@ -210,14 +212,15 @@ type BootConfig struct {
 }
 
 // ToOptions convert BootConfig into Option list
-func ToOptions(config *BootConfig, entryName, entryType string) []Option {
+func ToOptions(config *BootConfig, entryName, entryType string, appOpts ...rkentry.RegOption) []Option {
 	opts := make([]Option, 0)
 
 	if config.Enabled {
 		opts = append(opts,
 			WithEntryNameAndType(entryName, entryType),
 			WithPrefix(config.Prefix),
-			WithPathToIgnore(config.Ignore...))
+			WithPathToIgnore(config.Ignore...),
+			WithAppCtx(rkentry.NewRegOptions(appOpts...).AppCtx))
 	}
 
 	return opts
@@ -236,6 +239,15 @@ func WithEntryNameAndType(entryName, entryType string) Option {
 	}
 }
 
+// WithAppCtx provide rkentry.AppContext, rkentry.GlobalAppCtx by default.
+func WithAppCtx(ctx *rkentry.AppContext) Option {
+	return func(opt *optionSet) {
+		if ctx != nil {
+			opt.appCtx = ctx
+		}
+	}
+}
+
 // WithPrefix provide prefix.
 func WithPrefix(prefix string) Option {
 	return func(opt *optionSet) {
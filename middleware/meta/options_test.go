@@ -61,6 +61,22 @@ func TestOptionSet_Before(t *testing.T) {
 	assert.NotEmpty(t, ctx.Output.HeadersToReturn)
 }
 
+func TestOptionSet_WithAppCtx(t *testing.T) {
+	appCtx := rkentry.NewAppContext()
+	appCtx.GetAppInfoEntry().AppName = "ut-app"
+
+	// with nil AppContext
+	set := NewOptionSet(WithAppCtx(nil)).(*optionSet)
+	assert.Equal(t, rkentry.GlobalAppCtx, set.appCtx)
+
+	set = NewOptionSet(ToOptions(&BootConfig{Enabled: true}, "", "", rkentry.WithAppCtx(appCtx))...).(*optionSet)
+	assert.Equal(t, appCtx, set.appCtx)
+
+	ctx := set.BeforeCtx(httptest.NewRequest(http.MethodGet, "/ut", nil), nil)
+	set.Before(ctx)
+	assert.Equal(t, "ut-app", ctx.Output.HeadersToReturn["X-RK-App-Name"])
+}
+
 func TestNewOptionSetMock(t *testing.T) {
 	mock := NewOptionSetMock(NewBeforeCtx())
 	assert.NotEmpty(t, mock.GetEntryName())
@@ -8,6 +8,7 @@ package rkmidprom
 
 import (
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rookie-ninja/rk-entry/v2/entry"
 	"github.com/rookie-ninja/rk-entry/v2/middleware"
 	"net/http"
 	"strings"
@@ -332,17 +333,27 @@ type BootConfig struct {
 }
 
 // ToOptions convert BootConfig into Option list
+//
+// If reg is nil, metrics are registered into registry of PromEntry in rkentry.AppContext,
+// prometheus.DefaultRegisterer is used if PromEntry is missing.
 func ToOptions(config *BootConfig,
 	entryName, entryType string,
-	reg *prometheus.Registry, labelerType string) []Option {
+	reg *prometheus.Registry, labelerType string,
+	appOpts ...rkentry.RegOption) []Option {
 	opts := make([]Option, 0)
 
 	if config.Enabled {
 		opts = append(opts,
 			WithEntryNameAndType(entryName, entryType),
-			WithRegisterer(reg),
 			WithLabelerType(labelerType),
 			WithPathToIgnore(config.Ignore...))
+
+		// nil registry should not be wrapped into non-nil prometheus.Registerer
+		if reg != nil {
+			opts = append(opts, WithRegisterer(reg))
+		} else if entries := rkentry.ListEntriesAs[*rkentry.PromEntry](rkentry.NewRegOptions(appOpts...).AppCtx); len(entries) > 0 {
+			opts = append(opts, WithRegisterer(entries[0].Registerer))
+		}
 	}
 
 	return opts
@@ -6,6 +6,7 @@ package rkmidprom
 
 import (
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rookie-ninja/rk-entry/v2/entry"
 	"github.com/stretchr/testify/assert"
 	"net/http"
 	"net/http/httptest"
@@ -279,3 +280,25 @@ func assertNotPanic(t *testing.T) {
 		assert.True(t, true)
 	}
 }
+
+func TestToOptions_WithAppCtx(t *testing.T) {
+	config := &BootConfig{Enabled: true}
+
+	// registered into registry of PromEntry in AppContext
+	appCtx := rkentry.NewAppContext()
+	promEntry := rkentry.RegisterPromEntry(&rkentry.BootProm{Enabled: true}, rkentry.WithAppCtxPromEntry(appCtx))
+	appCtx.AddEntry(promEntry)
+
+	set := NewOptionSet(ToOptions(config, "ut-prom-ctx", "", nil, "", rkentry.WithAppCtx(appCtx))...).(*optionSet)
+	assert.Equal(t, promEntry.Registerer, set.registerer)
+	assert.Equal(t, promEntry.Registerer, set.metricsSet.GetRegisterer())
+
+	// registry is preferred
+	registry := prometheus.NewRegistry()
+	set = NewOptionSet(ToOptions(config, "ut-prom-reg", "", registry, "", rkentry.WithAppCtx(appCtx))...).(*optionSet)
+	assert.Equal(t, registry, set.registerer)
+
+	// DefaultRegisterer is used without PromEntry
+	set = NewOptionSet(ToOptions(config, "ut-prom-default", "", nil, "", rkentry.WithAppCtx(rkentry.NewAppContext()))...).(*optionSet)
+	assert.Equal(t, prometheus.DefaultRegisterer, set.registerer)
+}
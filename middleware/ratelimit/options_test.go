@@ -7,6 +7,8 @@ package rkmidlimit
 
 import (
 	"errors"
+	"github.com/rookie-ninja/rk-entry/v2/entry"
+	"github.com/rookie-ninja/rk-entry/v2/middleware"
 	"github.com/stretchr/testify/assert"
 	"net/http"
 	"net/http/httptest"
@@ -112,3 +114,54 @@ func assertNotPanic(t *testing.T) {
 		assert.True(t, true)
 	}
 }
+
+func TestReloadableOptionSet(t *testing.T) {
+	set := NewReloadableOptionSet(
+		WithEntryNameAndType("ut-name", "ut-type"),
+		WithGlobalLimiter(func() error {
+			return errors.New("ut-error")
+		}))
+
+	// with disabled
+	err := set.Reload(&BootConfig{Enabled: false})
+	assert.ErrorIs(t, err, rkentry.ErrRestartRequired)
+	assert.ErrorIs(t, err, rkmid.ErrRestartRequired)
+	assert.Contains(t, err.Error(), "ratelimit middleware disabled")
+
+	ctx := set.BeforeCtx(httptest.NewRequest(http.MethodGet, "/ut", nil))
+	set.Before(ctx)
+	assert.NotNil(t, ctx.Output.ErrResp)
+
+	// with new limit
+	reqPerSec := 100
+	assert.Nil(t, set.Reload(&BootConfig{
+		Enabled:   true,
+		ReqPerSec: &reqPerSec,
+		Ignore:    []string{"/ut-ignore"},
+	}))
+	ctx = set.BeforeCtx(httptest.NewRequest(http.MethodGet, "/ut", nil))
+	set.Before(ctx)
+	assert.Nil(t, ctx.Output.ErrResp)
+
+	assert.Equal(t, "ut-name", set.GetEntryName())
+	assert.Equal(t, "ut-type", set.GetEntryType())
+	assert.True(t, set.ShouldIgnore("/ut-ignore"))
+}
+
+func TestToOptions_WithAppCtx(t *testing.T) {
+	appCtx := rkentry.NewAppContext()
+	config := &BootConfig{Enabled: true}
+
+	// GlobalAppCtx by default
+	set := NewOptionSet(ToOptions(config, "", "")...).(*optionSet)
+	assert.Same(t, rkentry.GlobalAppCtx, set.appCtx)
+
+	// with AppContext
+	set = NewOptionSet(ToOptions(config, "", "", rkentry.WithAppCtx(appCtx))...).(*optionSet)
+	assert.Same(t, appCtx, set.appCtx)
+
+	// kept after reloaded
+	reloadable := NewReloadableOptionSet(ToOptions(config, "ut-name", "ut-type", rkentry.WithAppCtx(appCtx))...)
+	assert.Nil(t, reloadable.Reload(config))
+	assert.Same(t, appCtx, reloadable.appCtx())
+}
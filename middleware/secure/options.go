@@ -8,6 +8,7 @@ package rkmidsec
 
 import (
 	"fmt"
+	"github.com/rookie-ninja/rk-entry/v2/entry"
 	"github.com/rookie-ninja/rk-entry/v2/middleware"
 	"net/http"
 	"strings"
@@ -101,7 +102,8 @@ type optionSet struct {
 	// Optional. Default value "".
 	referrerPolicy string
 
-	mock OptionSetInterface
+	appCtx *rkentry.AppContext
+	mock   OptionSetInterface
 }
 
 // NewOptionSet Create new optionSet with options.
@@ -114,6 +116,7 @@ func NewOptionSet(opts ...Option) OptionSetInterface {
 		xFrameOptions:      "SAMEORIGIN",
 		hstsPreloadEnabled: false,
 		pathToIgnore:       make([]string, 0),
+		appCtx:             rkentry.GlobalAppCtx,
 	}
 
 	for i := range opts {
@@ -288,12 +291,13 @@ type BootConfig struct {
 }
 
 // ToOptions convert BootConfig into Option list
-func ToOptions(config *BootConfig, entryName, entryType string) []Option {
+func ToOptions(config *BootConfig, entryName, entryType string, appOpts ...rkentry.RegOption) []Option {
 	opts := make([]Option, 0)
 
 	if config.Enabled {
 		opts = append(opts,
 			WithEntryNameAndType(entryName, entryType),
+			WithAppCtx(rkentry.NewRegOptions(appOpts...).AppCtx),
 			WithXSSProtection(config.XssProtection),
 			WithContentTypeNosniff(config.ContentTypeNosniff),
 			WithXFrameOptions(config.XFrameOptions),
@@ -322,6 +326,15 @@ func WithEntryNameAndType(entryName, entryType string) Option {
 	}
 }
 
+// WithAppCtx provide rkentry.AppContext, rkentry.GlobalAppCtx by default.
+func WithAppCtx(ctx *rkentry.AppContext) Option {
+	return func(opt *optionSet) {
+		if ctx != nil {
+			opt.appCtx = ctx
+		}
+	}
+}
+
 // WithXSSProtection provide X-XSS-Protection header value.
 // Optional. Default value "1; mode=block".
 func WithXSSProtection(val string) Option {
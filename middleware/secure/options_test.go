@@ -7,6 +7,7 @@ package rkmidsec
 
 import (
 	"crypto/tls"
+	"github.com/rookie-ninja/rk-entry/v2/entry"
 	"github.com/rookie-ninja/rk-entry/v2/middleware"
 	"github.com/stretchr/testify/assert"
 	"net/http"
@@ -132,3 +133,16 @@ func containsHeader(t *testing.T, in map[string]string, headers ...string) {
 		assert.Contains(t, in, v)
 	}
 }
+
+func TestToOptions_WithAppCtx(t *testing.T) {
+	appCtx := rkentry.NewAppContext()
+	config := &BootConfig{Enabled: true}
+
+	// GlobalAppCtx by default
+	set := NewOptionSet(ToOptions(config, "", "")...).(*optionSet)
+	assert.Same(t, rkentry.GlobalAppCtx, set.appCtx)
+
+	// with AppContext
+	set = NewOptionSet(ToOptions(config, "", "", rkentry.WithAppCtx(appCtx))...).(*optionSet)
+	assert.Same(t, appCtx, set.appCtx)
+}
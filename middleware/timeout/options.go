@@ -46,6 +46,7 @@ type optionSet struct {
 	entryType    string
 	pathToIgnore []string
 	timeouts     map[string]time.Duration
+	appCtx       *rkentry.AppContext
 	mock         OptionSetInterface
 }
 
@@ -56,6 +57,7 @@ func NewOptionSet(opts ...Option) OptionSetInterface {
 		entryType:    "",
 		pathToIgnore: []string{},
 		timeouts:     make(map[string]time.Duration),
+		appCtx:       rkentry.GlobalAppCtx,
 	}
 
 	for i := range opts {
@@ -259,11 +261,13 @@ type BootConfig struct {
 }
 
 // ToOptions convert BootConfig into Option list
-func ToOptions(config *BootConfig, entryName, entryType string) []Option {
+func ToOptions(config *BootConfig, entryName, entryType string, appOpts ...rkentry.RegOption) []Option {
 	opts := make([]Option, 0)
 
 	if config.Enabled {
-		opts = append(opts, WithEntryNameAndType(entryName, entryType))
+		opts = append(opts,
+			WithEntryNameAndType(entryName, entryType),
+			WithAppCtx(rkentry.NewRegOptions(appOpts...).AppCtx))
 
 		timeout := time.Duration(config.TimeoutMs) * time.Millisecond
 		opts = append(opts, WithTimeout(timeout))
@@ -293,6 +297,15 @@ func WithEntryNameAndType(entryName, entryType string) Option {
 	}
 }
 
+// WithAppCtx provide rkentry.AppContext, rkentry.GlobalAppCtx by default.
+func WithAppCtx(ctx *rkentry.AppContext) Option {
+	return func(opt *optionSet) {
+		if ctx != nil {
+			opt.appCtx = ctx
+		}
+	}
+}
+
 // WithTimeout Provide global timeout and response handler.
 // If response is nil, default globalResponse will be assigned
 func WithTimeout(timeout time.Duration) Option {
@@ -67,6 +67,7 @@ type optionSet struct {
 	propagator   propagation.TextMapPropagator
 	tracer       oteltrace.Tracer
 	pathToIgnore []string
+	appCtx       *rkentry.AppContext
 	mock         OptionSetInterface
 }
 
@@ -76,6 +77,7 @@ func NewOptionSet(opts ...Option) OptionSetInterface {
 		entryName:    "fake-entry",
 		entryType:    "",
 		pathToIgnore: []string{},
+		appCtx:       rkentry.GlobalAppCtx,
 	}
 
 	for i := range opts {
@@ -101,8 +103,8 @@ func NewOptionSet(opts ...Option) OptionSetInterface {
 			sdkresource.WithTelemetrySDK(),
 			sdkresource.WithHost(),
 			sdkresource.WithAttributes(
-				semconv.ServiceNameKey.String(rkentry.GlobalAppCtx.GetAppInfoEntry().AppName),
-				semconv.ServiceVersionKey.String(rkentry.GlobalAppCtx.GetAppInfoEntry().Version),
+				semconv.ServiceNameKey.String(set.appCtx.GetAppInfoEntry().AppName),
+				semconv.ServiceVersionKey.String(set.appCtx.GetAppInfoEntry().Version),
 				attribute.String("service.entryName", set.entryName),
 				attribute.String("service.entryType", set.entryType),
 				semconv.TelemetrySDKLanguageGo,
@@ -164,7 +166,7 @@ func (set *optionSet) BeforeCtx(req *http.Request, isClient bool, attrs ...attri
 		ctx.Input.Attributes = append(ctx.Input.Attributes, semconv.NetAttributesFromHTTPRequest("tcp", req)...)
 		ctx.Input.Attributes = append(ctx.Input.Attributes, semconv.EndUserAttributesFromHTTPRequest(req)...)
 		ctx.Input.Attributes = append(ctx.Input.Attributes, semconv.HTTPServerAttributesFromHTTPRequest(
-			rkentry.GlobalAppCtx.GetAppInfoEntry().AppName, req.URL.Path, req)...)
+			set.appCtx.GetAppInfoEntry().AppName, req.URL.Path, req)...)
 		ctx.Input.SpanName = req.URL.Path
 
 		ctx.Input.RequestCtx = req.Context()
@@ -390,7 +392,7 @@ type BootConfig struct {
 }
 
 // ToOptions convert BootConfig into Option list
-func ToOptions(config *BootConfig, entryName, entryType string) []Option {
+func ToOptions(config *BootConfig, entryName, entryType string, appOpts ...rkentry.RegOption) []Option {
 	opts := make([]Option, 0)
 
 	if config.Enabled {
@@ -424,7 +426,8 @@ func ToOptions(config *BootConfig, entryName, entryType string) []Option {
 		opts = append(opts,
 			WithEntryNameAndType(entryName, entryType),
 			WithExporter(exporter),
-			WithPathToIgnore(config.Ignore...))
+			WithPathToIgnore(config.Ignore...),
+			WithAppCtx(rkentry.NewRegOptions(appOpts...).AppCtx))
 	}
 
 	return opts
@@ -435,6 +438,15 @@ func ToOptions(config *BootConfig, entryName, entryType string) []Option {
 // Option is used while creating middleware as param
 type Option func(*optionSet)
 
+// WithAppCtx provide rkentry.AppContext, rkentry.GlobalAppCtx by default.
+func WithAppCtx(ctx *rkentry.AppContext) Option {
+	return func(opt *optionSet) {
+		if ctx != nil {
+			opt.appCtx = ctx
+		}
+	}
+}
+
 // WithExporter Provide sdktrace.SpanExporter.
 func WithExporter(exporter sdktrace.SpanExporter) Option {
 	return func(opt *optionSet) {